@@ -0,0 +1,78 @@
+// Package chat runs conversations in which the model answers questions
+// about the library by calling tools.
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dajoen/steam-pick/internal/llm"
+	"github.com/dajoen/steam-pick/internal/tools"
+)
+
+// SystemPrompt tells the model what it is and how to use the tools.
+const SystemPrompt = `You are steam-pick, an assistant for a player's Steam library.
+Answer questions about the games they own by calling the tools; never invent games, playtimes or statuses.
+Playtime is in minutes. Games "not touched" or "unplayed" are those still in the backlog.
+Keep answers short and name games with their appid.`
+
+// DefaultMaxSteps limits the model calls of one turn so a model that keeps
+// calling tools cannot loop forever.
+const DefaultMaxSteps = 8
+
+// Conversation is a chat with tool access. History holds every message
+// except the system prompt.
+type Conversation struct {
+	Client   llm.ChatClient
+	Tools    *tools.Registry
+	History  []llm.Message
+	MaxSteps int
+}
+
+// Send adds the user's input, lets the model call tools until it answers,
+// and returns the answer with every message the turn added to History.
+func (c *Conversation) Send(ctx context.Context, input string) (string, []llm.Message, error) {
+	start := len(c.History)
+	c.History = append(c.History, llm.Message{Role: "user", Content: input})
+
+	var defs []llm.Tool
+	for _, t := range c.Tools.Tools() {
+		defs = append(defs, llm.Tool{Name: t.Name, Description: t.Description, Parameters: t.Parameters})
+	}
+
+	maxSteps := c.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = DefaultMaxSteps
+	}
+	for step := 0; step < maxSteps; step++ {
+		messages := append([]llm.Message{{Role: "system", Content: SystemPrompt}}, c.History...)
+		reply, err := c.Client.Chat(ctx, messages, defs)
+		if err != nil {
+			c.History = c.History[:start]
+			return "", nil, err
+		}
+		c.History = append(c.History, reply)
+		if len(reply.ToolCalls) == 0 {
+			return reply.Content, c.History[start:], nil
+		}
+
+		for _, call := range reply.ToolCalls {
+			result, err := c.Tools.Call(ctx, call.Name, call.Arguments)
+			if err != nil {
+				// Errors go back to the model so it can correct itself.
+				b, _ := json.Marshal(map[string]string{"error": err.Error()})
+				result = string(b)
+			}
+			c.History = append(c.History, llm.Message{
+				Role:       "tool",
+				Content:    result,
+				ToolCallID: call.ID,
+				Name:       call.Name,
+			})
+		}
+	}
+
+	added := c.History[start:]
+	return "", added, fmt.Errorf("no answer after %d steps", maxSteps)
+}
@@ -0,0 +1,112 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dajoen/steam-pick/internal/llm"
+	"github.com/dajoen/steam-pick/internal/tools"
+)
+
+// scriptedClient replies with its messages in order and records what it was sent.
+type scriptedClient struct {
+	replies []llm.Message
+	sent    [][]llm.Message
+}
+
+func (s *scriptedClient) Check(ctx context.Context) error { return nil }
+
+func (s *scriptedClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return "", nil
+}
+
+func (s *scriptedClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	return nil, nil
+}
+
+func (s *scriptedClient) Chat(ctx context.Context, messages []llm.Message, defs []llm.Tool) (llm.Message, error) {
+	s.sent = append(s.sent, messages)
+	if len(s.replies) == 0 {
+		return llm.Message{}, errors.New("no more replies")
+	}
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return reply, nil
+}
+
+func echoTools() *tools.Registry {
+	r := tools.NewRegistry()
+	r.Register(tools.Tool{
+		Name: "echo",
+		Run: func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+			var in struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(args, &in); err != nil {
+				return nil, err
+			}
+			if in.Text == "" {
+				return nil, errors.New("text is required")
+			}
+			return map[string]string{"echo": in.Text}, nil
+		},
+	})
+	return r
+}
+
+func TestSendRunsTools(t *testing.T) {
+	client := &scriptedClient{replies: []llm.Message{
+		{Role: "assistant", ToolCalls: []llm.ToolCall{
+			{ID: "1", Name: "echo", Arguments: json.RawMessage(`{"text": "hi"}`)},
+			{ID: "2", Name: "echo", Arguments: json.RawMessage(`{}`)},
+		}},
+		{Role: "assistant", Content: "Done"},
+	}}
+	conv := &Conversation{Client: client, Tools: echoTools()}
+
+	answer, added, err := conv.Send(context.Background(), "Say hi")
+	if err != nil {
+		t.Fatalf("Send error: %v", err)
+	}
+	if answer != "Done" {
+		t.Errorf("got answer %q, want Done", answer)
+	}
+	// user, assistant tool calls, two tool results, final answer
+	if len(added) != 5 || len(conv.History) != 5 {
+		t.Fatalf("got %d added and %d history messages, want 5", len(added), len(conv.History))
+	}
+	if added[2].Content != `{"echo":"hi"}` || added[2].ToolCallID != "1" {
+		t.Errorf("unexpected tool result: %+v", added[2])
+	}
+	if !strings.Contains(added[3].Content, "text is required") {
+		t.Errorf("tool error not passed to the model: %+v", added[3])
+	}
+	if second := client.sent[1]; second[0].Role != "system" || len(second) != 5 {
+		t.Errorf("second call got %d messages, want system prompt and 4 history messages", len(second))
+	}
+}
+
+func TestSendLimitsSteps(t *testing.T) {
+	call := llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "1", Name: "echo", Arguments: json.RawMessage(`{"text": "again"}`)}}}
+	client := &scriptedClient{replies: []llm.Message{call, call, call}}
+	conv := &Conversation{Client: client, Tools: echoTools(), MaxSteps: 2}
+
+	if _, added, err := conv.Send(context.Background(), "Loop"); err == nil || len(added) != 5 {
+		t.Errorf("got %d added messages and error %v, want 5 and an error", len(added), err)
+	}
+}
+
+func TestSendRestoresHistoryOnError(t *testing.T) {
+	conv := &Conversation{Client: &scriptedClient{}, Tools: echoTools()}
+	conv.History = []llm.Message{{Role: "user", Content: "Earlier"}}
+
+	if _, _, err := conv.Send(context.Background(), "Hello"); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(conv.History) != 1 {
+		t.Errorf("history has %d messages after failure, want 1", len(conv.History))
+	}
+}
@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/steamapi"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/time/rate"
+)
+
+var (
+	achievementsOutput       string
+	achievementsRateLimit    int
+	achievementsIncludeNever bool
+)
+
+var achievementsCmd = &cobra.Command{
+	Use:   "achievements",
+	Short: "Show achievement completion per game",
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		progress, err := database.GetAchievementProgress(steamID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching achievements: %v\n", err)
+			os.Exit(1)
+		}
+
+		if achievementsOutput == "json" {
+			if progress == nil {
+				progress = []model.AchievementProgress{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(progress); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(progress) == 0 {
+			fmt.Println("No achievement data yet. Run 'steam-pick achievements sync' first.")
+			return
+		}
+
+		fmt.Printf("%-10s %-40s %-10s %s\n", "AppID", "Name", "Unlocked", "Completion")
+		fmt.Println("------------------------------------------------------------------------")
+		for _, p := range progress {
+			fmt.Printf("%-10d %-40s %-10s %.0f%%\n", p.AppID, p.Name, fmt.Sprintf("%d/%d", p.Unlocked, p.Total), p.Completion()*100)
+		}
+	},
+}
+
+var achievementsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch achievements for played games",
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKey, err := getAPIKey()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if achievementsRateLimit < 1 {
+			fmt.Fprintln(os.Stderr, "Error: --rate-limit-per-minute must be >= 1")
+			os.Exit(1)
+		}
+
+		vanityTTL := viper.GetDuration("auth_cache_ttl")
+		client, err := NewSteamClient(apiKey, 24*time.Hour, vanityTTL, 30*time.Second)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		ctx := context.Background()
+		steamID, err := getSteamID(ctx, client, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		games, err := database.GetOwnedGames(steamID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching games from DB: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Achievements can only be unlocked by playing, so unplayed games
+		// are skipped unless asked for.
+		var toSync []model.Game
+		for _, g := range games {
+			if g.PlaytimeForever > 0 || achievementsIncludeNever {
+				toSync = append(toSync, g)
+			}
+		}
+
+		if len(toSync) == 0 {
+			fmt.Println("No games to sync achievements for.")
+			return
+		}
+
+		fmt.Printf("Fetching achievements for %d games...\n", len(toSync))
+		limiter := rate.NewLimiter(rate.Limit(float64(achievementsRateLimit)/60.0), 1)
+
+		synced, skipped, err := syncAchievements(ctx, database, client, steamID, toSync, limiter)
+		if errors.Is(err, steamapi.ErrRateLimitExceeded) {
+			fmt.Fprintln(os.Stderr, "Rate limit exceeded! Stopping achievement sync.")
+			os.Exit(1)
+		}
+
+		fmt.Printf("Achievement sync complete: %d synced, %d without achievements or unavailable.\n", synced, skipped)
+	},
+}
+
+// syncAchievements fetches and stores the achievements of games. It stops
+// early on a cancelled context or when the API rate limit is exceeded.
+func syncAchievements(ctx context.Context, database *db.DB, client SteamClient, steamID string, games []model.Game, limiter *rate.Limiter) (synced, skipped int, err error) {
+	for i, g := range games {
+		if err := limiter.Wait(ctx); err != nil {
+			break
+		}
+
+		fmt.Printf("[%d/%d] %s (%d)\n", i+1, len(games), g.Name, g.AppID)
+		progress, err := client.GetPlayerAchievements(ctx, steamID, g.AppID)
+		if err != nil {
+			if errors.Is(err, steamapi.ErrRateLimitExceeded) {
+				return synced, skipped, err
+			}
+			if !errors.Is(err, steamapi.ErrNoAchievements) {
+				fmt.Fprintf(os.Stderr, "Failed to fetch achievements for %s (%d): %v\n", g.Name, g.AppID, err)
+			}
+			skipped++
+			continue
+		}
+
+		// The schema is a second request and counts against the rate limit.
+		if err := limiter.Wait(ctx); err != nil {
+			break
+		}
+		names := make(map[string]string)
+		total := len(progress)
+		schema, err := client.GetSchemaForGame(ctx, g.AppID)
+		if errors.Is(err, steamapi.ErrRateLimitExceeded) {
+			return synced, skipped, err
+		}
+		if err == nil && len(schema) > 0 {
+			total = len(schema)
+			for _, a := range schema {
+				names[a.Name] = a.DisplayName
+			}
+		}
+
+		var unlocks []model.AchievementUnlock
+		for _, a := range progress {
+			if a.Achieved == 0 {
+				continue
+			}
+			unlocks = append(unlocks, model.AchievementUnlock{
+				APIName:     a.APIName,
+				DisplayName: names[a.APIName],
+				UnlockedAt:  time.Unix(a.UnlockTime, 0),
+			})
+		}
+
+		if err := database.UpsertAchievements(steamID, g.AppID, total, unlocks); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save achievements for %s (%d): %v\n", g.Name, g.AppID, err)
+			continue
+		}
+		synced++
+	}
+	return synced, skipped, nil
+}
+
+func init() {
+	rootCmd.AddCommand(achievementsCmd)
+	achievementsCmd.AddCommand(achievementsSyncCmd)
+	achievementsCmd.Flags().StringVar(&achievementsOutput, "output", "table", "Output format 'table' or 'json'")
+	achievementsSyncCmd.Flags().IntVar(&achievementsRateLimit, "rate-limit-per-minute", 60, "Rate limit per minute")
+	achievementsSyncCmd.Flags().BoolVar(&achievementsIncludeNever, "include-unplayed", false, "Also fetch achievements for games with 0 playtime")
+}
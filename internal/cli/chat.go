@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/chat"
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/llm"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chatSession int64
+	chatLimit   int
+	chatOutput  string
+)
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Ask questions about your library in a conversation",
+	Long: `chat starts an interactive conversation with the LLM, which answers by
+looking up your library. Type 'exit' or press Ctrl-D to leave. Conversations
+are stored and can be resumed with --session.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		client, err := newLLMClient(llmBaseURL, llmModel, "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		chatClient, ok := client.(llm.ChatClient)
+		if !ok {
+			fmt.Fprintln(os.Stderr, "Error: the LLM provider does not support chat")
+			os.Exit(1)
+		}
+
+		if err := runChat(context.Background(), os.Stdin, os.Stdout, database, chatClient, steamID, chatSession); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var chatSessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List stored chat sessions",
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		sessions, err := database.GetChatSessions(steamID, chatLimit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching sessions: %v\n", err)
+			os.Exit(1)
+		}
+		if chatOutput == "json" {
+			if sessions == nil {
+				sessions = []model.ChatSession{}
+			}
+			encodeJSON(sessions)
+			return
+		}
+		if len(sessions) == 0 {
+			fmt.Println("No chat sessions stored yet.")
+			return
+		}
+		fmt.Printf("%-8s %-17s %s\n", "Session", "Last active", "Title")
+		fmt.Println("------------------------------------------------------------")
+		for _, s := range sessions {
+			fmt.Printf("%-8d %-17s %s\n", s.ID, s.UpdatedAt.Local().Format("2006-01-02 15:04"), s.Title)
+		}
+	},
+}
+
+// runChat reads questions from in line by line and writes answers to out.
+// The session is created with the first question unless sessionID resumes
+// an existing one.
+func runChat(ctx context.Context, in io.Reader, out io.Writer, database *db.DB, client llm.ChatClient, steamID string, sessionID int64) error {
+	conv := &chat.Conversation{Client: client, Tools: tools.NewLibrary(database, steamID)}
+
+	if sessionID != 0 {
+		session, err := database.GetChatSession(steamID, sessionID)
+		if err != nil {
+			return err
+		}
+		if session == nil {
+			return fmt.Errorf("no chat session %d", sessionID)
+		}
+		history, err := database.GetChatMessages(sessionID)
+		if err != nil {
+			return err
+		}
+		conv.History = history
+		_, _ = fmt.Fprintf(out, "Resuming session %d: %s\n", session.ID, session.Title)
+	}
+
+	scanner := bufio.NewScanner(in)
+	for {
+		_, _ = fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			_, _ = fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		input := strings.TrimSpace(scanner.Text())
+		if input == "" {
+			continue
+		}
+		if input == "exit" || input == "quit" {
+			return nil
+		}
+
+		answer, added, sendErr := conv.Send(ctx, input)
+		if len(added) > 0 {
+			now := time.Now()
+			if sessionID == 0 {
+				id, err := database.CreateChatSession(steamID, chatTitle(input), now)
+				if err != nil {
+					return err
+				}
+				sessionID = id
+			}
+			if err := database.AppendChatMessages(sessionID, added, now); err != nil {
+				return err
+			}
+		}
+		if sendErr != nil {
+			_, _ = fmt.Fprintf(out, "Error: %v\n", sendErr)
+			continue
+		}
+		_, _ = fmt.Fprintln(out, answer)
+	}
+}
+
+// chatTitle shortens the first question of a session to a title.
+func chatTitle(input string) string {
+	const max = 60
+	if len([]rune(input)) <= max {
+		return input
+	}
+	return string([]rune(input)[:max-3]) + "..."
+}
+
+func init() {
+	rootCmd.AddCommand(chatCmd)
+	chatCmd.AddCommand(chatSessionsCmd)
+
+	chatCmd.Flags().Int64Var(&chatSession, "session", 0, "Resume a stored session")
+	addLLMFlags(chatCmd, true, false)
+	chatCmd.Flags().Lookup("llm-model").Usage = "LLM Model (must support tool calling)"
+	chatSessionsCmd.Flags().IntVar(&chatLimit, "limit", 20, "Number of sessions to list")
+	chatSessionsCmd.Flags().StringVar(&chatOutput, "output", "table", "Output format 'table' or 'json'")
+}
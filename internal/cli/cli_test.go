@@ -3,17 +3,36 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/llm"
 	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/server"
+	"github.com/dajoen/steam-pick/internal/steamapi"
+	"github.com/dajoen/steam-pick/internal/steamlocal"
+	"github.com/dajoen/steam-pick/internal/tui"
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/time/rate"
 )
 
 type MockSteamClient struct {
-	Games []model.Game
+	Games        []model.Game
+	GamesErr     error
+	Achievements map[int][]model.PlayerAchievement
+	Schemas      map[int][]model.SchemaAchievement
 }
 
 func (m *MockSteamClient) ResolveVanityURL(ctx context.Context, vanityURL string) (string, error) {
@@ -21,10 +40,25 @@ func (m *MockSteamClient) ResolveVanityURL(ctx context.Context, vanityURL string
 }
 
 func (m *MockSteamClient) GetOwnedGames(ctx context.Context, steamID64 string, includeFree bool) ([]model.Game, error) {
-	return m.Games, nil
+	return m.Games, m.GamesErr
+}
+
+func (m *MockSteamClient) GetPlayerAchievements(ctx context.Context, steamID64 string, appID int) ([]model.PlayerAchievement, error) {
+	a, ok := m.Achievements[appID]
+	if !ok {
+		return nil, steamapi.ErrNoAchievements
+	}
+	return a, nil
+}
+
+func (m *MockSteamClient) GetSchemaForGame(ctx context.Context, appID int) ([]model.SchemaAchievement, error) {
+	return m.Schemas[appID], nil
 }
 
 func TestListCommand(t *testing.T) {
+	// Keep the database and cache out of the real user cache dir
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
 	// Mock dependencies
 	oldFactory := NewSteamClient
 	defer func() { NewSteamClient = oldFactory }()
@@ -46,6 +80,10 @@ func TestListCommand(t *testing.T) {
 	// Setup Viper
 	viper.Set("api_key", "test-key")
 	viper.Set("steamid64", "76561198000000000")
+	t.Cleanup(func() {
+		viper.Set("api_key", "")
+		viper.Set("steamid64", "")
+	})
 
 	// Run command
 	// We need to reset flags or use a new command instance, but listCmd is global.
@@ -64,3 +102,1094 @@ func TestListCommand(t *testing.T) {
 		t.Errorf("Expected output '1: Unplayed Game\\n', got '%s'", output)
 	}
 }
+
+func TestGetSteamIDAccount(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer viper.Set("account", "")
+
+	viper.Set("accounts", map[string]string{"partner": "76561198000000001"})
+	defer viper.Set("accounts", nil)
+
+	tests := []struct {
+		account string
+		want    string
+		wantErr bool
+	}{
+		{account: "partner", want: "76561198000000001"},
+		{account: "Partner", want: "76561198000000001"},
+		{account: "76561198000000002", want: "76561198000000002"},
+		{account: "stranger", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		viper.Set("account", tt.account)
+		got, err := getSteamID(context.Background(), nil, "76561198000000000", "")
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("getSteamID(%q) expected error, got %s", tt.account, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("getSteamID(%q) error: %v", tt.account, err)
+		}
+		if got != tt.want {
+			t.Errorf("getSteamID(%q) = %s, want %s", tt.account, got, tt.want)
+		}
+	}
+}
+
+func TestSetGameStatusTransitions(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	if err := setGameStatus(database, steamID, 1, model.StatusBeaten, false); err != nil {
+		t.Fatalf("first status should be allowed: %v", err)
+	}
+	if err := setGameStatus(database, steamID, 1, model.StatusBacklog, false); err == nil {
+		t.Error("expected beaten -> backlog to be rejected")
+	}
+	if err := setGameStatus(database, steamID, 1, model.StatusBacklog, true); err != nil {
+		t.Errorf("expected --force to allow beaten -> backlog: %v", err)
+	}
+	if st, _ := database.GetGameStatus(steamID, 1); st != model.StatusBacklog {
+		t.Errorf("got status %s, want backlog", st)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"72h", 72 * time.Hour},
+		{"30d", 30 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.in)
+		if err != nil {
+			t.Errorf("parseDuration(%q) error: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "xd", "-1d", "month"} {
+		if _, err := parseDuration(in); err == nil {
+			t.Errorf("parseDuration(%q) expected error", in)
+		}
+	}
+}
+
+type failingStoreClient struct {
+	t *testing.T
+}
+
+func (f *failingStoreClient) GetAppDetails(ctx context.Context, appID int, country string) (*model.AppDetails, error) {
+	f.t.Errorf("unexpected store lookup for %d", appID)
+	return nil, fmt.Errorf("no store")
+}
+
+func TestPickOfflineTurnBased(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	oldStore := NewStoreClient
+	defer func() { NewStoreClient = oldStore }()
+	NewStoreClient = func(timeout time.Duration) StoreClient {
+		return &failingStoreClient{t: t}
+	}
+
+	steamID := "76561198000000000"
+	viper.Set("steamid64", steamID)
+	t.Cleanup(func() { viper.Set("steamid64", "") })
+
+	database, err := openDB()
+	if err != nil {
+		t.Fatalf("openDB error: %v", err)
+	}
+	if err := database.UpsertGames(steamID, []model.Game{
+		{AppID: 1, Name: "Real-time Game"},
+		{AppID: 2, Name: "Turn-based Game"},
+		{AppID: 3, Name: "Unknown Game"},
+	}); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	for id, genre := range map[int]string{1: "Action", 2: "Turn-Based Strategy"} {
+		details := model.AppDetailsResponse{
+			strconv.Itoa(id): {Success: true, Data: model.AppDetails{Genres: []model.Genre{{Description: genre}}}},
+		}
+		if err := database.UpsertAppDetails(id, details); err != nil {
+			t.Fatalf("UpsertAppDetails error: %v", err)
+		}
+	}
+	_ = database.Close()
+
+	for _, f := range []string{"offline", "turn-based-only", "json"} {
+		_ = pickCmd.Flags().Set(f, "true")
+	}
+	defer func() {
+		for _, f := range []string{"offline", "turn-based-only", "json"} {
+			_ = pickCmd.Flags().Set(f, "false")
+		}
+	}()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	pickCmd.Run(pickCmd, []string{})
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	var picked model.Game
+	if err := json.NewDecoder(r).Decode(&picked); err != nil {
+		t.Fatalf("decoding pick output: %v", err)
+	}
+	if picked.AppID != 2 || !picked.IsTurnBased {
+		t.Errorf("expected the turn-based game to be picked, got %+v", picked)
+	}
+}
+
+type fakeLLMClient struct {
+	embedded []string
+}
+
+func (f *fakeLLMClient) Check(ctx context.Context) error { return nil }
+
+func (f *fakeLLMClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeLLMClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	f.embedded = append(f.embedded, text)
+	return []float32{float32(len(text)), 1}, nil
+}
+
+func TestEmbedGames(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	if err := database.UpsertGames(steamID, []model.Game{
+		{AppID: 1, Name: "Enriched"},
+		{AppID: 2, Name: "Not Enriched"},
+		{AppID: 3, Name: "Some DLC"},
+	}); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	for id, data := range map[int]model.AppDetails{
+		1: {Type: "game", ShortDescription: "A tactics game", Genres: []model.Genre{{Description: "Strategy"}}},
+		3: {Type: "dlc"},
+	} {
+		if err := database.UpsertAppDetails(id, model.AppDetailsResponse{strconv.Itoa(id): {Success: true, Data: data}}); err != nil {
+			t.Fatalf("UpsertAppDetails error: %v", err)
+		}
+	}
+
+	client := &fakeLLMClient{}
+	embedded, skipped, err := embedGames(context.Background(), database, client, steamID, "test", false)
+	if err != nil {
+		t.Fatalf("embedGames error: %v", err)
+	}
+	if embedded != 1 || skipped != 0 {
+		t.Errorf("got embedded=%d skipped=%d, want 1 and 0", embedded, skipped)
+	}
+	if len(client.embedded) != 1 || !strings.Contains(client.embedded[0], "Genres: Strategy") {
+		t.Errorf("unexpected embedded texts: %q", client.embedded)
+	}
+
+	embedded, skipped, err = embedGames(context.Background(), database, client, steamID, "test", false)
+	if err != nil {
+		t.Fatalf("embedGames error: %v", err)
+	}
+	if embedded != 0 || skipped != 1 {
+		t.Errorf("second run: got embedded=%d skipped=%d, want 0 and 1", embedded, skipped)
+	}
+}
+
+type unreachableLLMClient struct {
+	fakeLLMClient
+}
+
+func (u *unreachableLLMClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestSearchLibrary(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	if err := database.UpsertGames(steamID, []model.Game{{AppID: 1, Name: "Farm Game"}, {AppID: 2, Name: "Shooter"}}); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	for id, desc := range map[int]string{1: "Cozy farming and crafting", 2: "Fast shooting"} {
+		if err := database.UpsertAppDetails(id, model.AppDetailsResponse{strconv.Itoa(id): {Success: true, Data: model.AppDetails{Type: "game", ShortDescription: desc}}}); err != nil {
+			t.Fatalf("UpsertAppDetails error: %v", err)
+		}
+	}
+	_ = database.UpsertEmbedding(1, "test", "h1", []float32{1, 0})
+	_ = database.UpsertEmbedding(2, "test", "h2", []float32{0, 1})
+
+	semantic, err := searchLibrary(context.Background(), database, &queryLLMClient{vector: []float32{0.1, 1}}, steamID, "shooting", "test", 10)
+	if err != nil {
+		t.Fatalf("searchLibrary error: %v", err)
+	}
+	if len(semantic) != 2 || semantic[0].AppID != 2 || semantic[0].Method != "semantic" {
+		t.Errorf("unexpected semantic results: %+v", semantic)
+	}
+
+	keyword, err := searchLibrary(context.Background(), database, &unreachableLLMClient{}, steamID, "cozy farming", "test", 10)
+	if err != nil {
+		t.Fatalf("searchLibrary error: %v", err)
+	}
+	if len(keyword) != 1 || keyword[0].AppID != 1 || keyword[0].Method != "keyword" {
+		t.Errorf("unexpected keyword fallback results: %+v", keyword)
+	}
+}
+
+type queryLLMClient struct {
+	fakeLLMClient
+	vector []float32
+}
+
+func (q *queryLLMClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	return q.vector, nil
+}
+
+// chatLLMClient asks for the playtime of game 1 and then answers with the tool result.
+type chatLLMClient struct {
+	fakeLLMClient
+}
+
+func (c *chatLLMClient) Chat(ctx context.Context, messages []llm.Message, tools []llm.Tool) (llm.Message, error) {
+	last := messages[len(messages)-1]
+	if last.Role == "tool" {
+		return llm.Message{Role: "assistant", Content: "Tool said " + last.Content}, nil
+	}
+	return llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{
+		{ID: "call_0", Name: "get_playtime", Arguments: json.RawMessage(`{"appid": 1}`)},
+	}}, nil
+}
+
+func TestRunChat(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	if err := database.UpsertGames(steamID, []model.Game{{AppID: 1, Name: "Portal", PlaytimeForever: 90}}); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+
+	var out bytes.Buffer
+	in := strings.NewReader("How long did I play Portal?\nexit\n")
+	if err := runChat(context.Background(), in, &out, database, &chatLLMClient{}, steamID, 0); err != nil {
+		t.Fatalf("runChat error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"playtime_minutes":90`) {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	sessions, err := database.GetChatSessions(steamID, 10)
+	if err != nil || len(sessions) != 1 || sessions[0].Title != "How long did I play Portal?" {
+		t.Fatalf("unexpected sessions: %+v, %v", sessions, err)
+	}
+
+	out.Reset()
+	if err := runChat(context.Background(), strings.NewReader("And again?\n"), &out, database, &chatLLMClient{}, steamID, sessions[0].ID); err != nil {
+		t.Fatalf("resume error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Resuming session") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	messages, err := database.GetChatMessages(sessions[0].ID)
+	if err != nil || len(messages) != 8 {
+		t.Errorf("got %d stored messages, %v, want 8", len(messages), err)
+	}
+}
+
+func TestMCPTools(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	if err := database.UpsertGames(steamID, []model.Game{
+		{AppID: 1, Name: "Unplayed"},
+		{AppID: 2, Name: "Played", PlaytimeForever: 600},
+	}); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	registry := mcpTools(&libraryService{db: database, steamID: steamID})
+	ctx := context.Background()
+
+	res, err := registry.Call(ctx, "list_unplayed", nil)
+	if err != nil || !strings.Contains(res, `"Unplayed"`) || strings.Contains(res, `"Played"`) {
+		t.Errorf("list_unplayed: got %s, %v", res, err)
+	}
+
+	res, err = registry.Call(ctx, "pick_game", json.RawMessage(`{"seed": 42}`))
+	if err != nil || !strings.Contains(res, `"appid":1`) {
+		t.Errorf("pick_game: got %s, %v", res, err)
+	}
+	picks, err := database.GetPicksSince(steamID, time.Now().Add(-time.Hour))
+	if err != nil || len(picks) != 1 || picks[0].Flags["source"] != "mcp" {
+		t.Errorf("pick_game should record the pick with its source: got %+v, %v", picks, err)
+	}
+	if _, err := registry.Call(ctx, "pick_game", json.RawMessage(`{"exclude_recent": "1d"}`)); err == nil {
+		t.Error("pick_game: expected error when every game was picked recently")
+	}
+
+	if _, err := registry.Call(ctx, "get_taste_profile", nil); err == nil {
+		t.Error("get_taste_profile: expected error without a profile")
+	}
+	if err := database.UpsertTasteProfile(steamID, "genres", `[{"Key":"RPG","Value":3}]`); err != nil {
+		t.Fatalf("UpsertTasteProfile error: %v", err)
+	}
+	res, err = registry.Call(ctx, "get_taste_profile", nil)
+	if err != nil || !strings.Contains(res, `"key":"RPG"`) {
+		t.Errorf("get_taste_profile: got %s, %v", res, err)
+	}
+
+	res, err = registry.Call(ctx, "get_game_details", json.RawMessage(`{"appid": 2}`))
+	if err != nil || !strings.Contains(res, `"playtime_minutes":600`) {
+		t.Errorf("get_game_details: got %s, %v", res, err)
+	}
+}
+
+func TestAchievementsSync(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	viper.Set("api_key", "test-key")
+	viper.Set("steamid64", "76561198000000000")
+	t.Cleanup(func() {
+		viper.Set("api_key", "")
+		viper.Set("steamid64", "")
+	})
+
+	oldFactory := NewSteamClient
+	defer func() { NewSteamClient = oldFactory }()
+	NewSteamClient = func(apiKey string, ttl, vanityTTL, timeout time.Duration) (SteamClient, error) {
+		return &MockSteamClient{
+			Achievements: map[int][]model.PlayerAchievement{
+				620: {{APIName: "WAKE_UP", Achieved: 1, UnlockTime: 1700000000}, {APIName: "PARTNER", Achieved: 0}},
+			},
+			Schemas: map[int][]model.SchemaAchievement{
+				620: {{Name: "WAKE_UP", DisplayName: "Wake Up Call"}, {Name: "PARTNER"}, {Name: "SECRET"}},
+			},
+		}, nil
+	}
+
+	database, err := openDB()
+	if err != nil {
+		t.Fatalf("openDB error: %v", err)
+	}
+	steamID := "76561198000000000"
+	if err := database.UpsertGames(steamID, []model.Game{
+		{AppID: 620, Name: "Portal 2", PlaytimeForever: 60},
+		{AppID: 400, Name: "Portal", PlaytimeForever: 30},
+		{AppID: 70, Name: "Half-Life"},
+	}); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	_ = database.Close()
+
+	oldRate := achievementsRateLimit
+	achievementsRateLimit = 6000
+	defer func() { achievementsRateLimit = oldRate }()
+	achievementsSyncCmd.Run(achievementsSyncCmd, nil)
+
+	database, err = openDB()
+	if err != nil {
+		t.Fatalf("openDB error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+	progress, err := database.GetAchievementProgress(steamID)
+	if err != nil {
+		t.Fatalf("GetAchievementProgress error: %v", err)
+	}
+	if len(progress) != 1 || progress[0].AppID != 620 || progress[0].Unlocked != 1 || progress[0].Total != 3 {
+		t.Errorf("progress: got %+v", progress)
+	}
+	unlocks, err := database.GetAchievementUnlocks(steamID, 620)
+	if err != nil || len(unlocks) != 1 || unlocks[0].DisplayName != "Wake Up Call" {
+		t.Errorf("unlocks: got %+v, %v", unlocks, err)
+	}
+}
+
+func TestSyncAchievementsRateLimitsSchemas(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	client := &MockSteamClient{
+		Achievements: map[int][]model.PlayerAchievement{620: {{APIName: "WAKE_UP", Achieved: 1}}},
+		Schemas:      map[int][]model.SchemaAchievement{620: {{Name: "WAKE_UP"}}},
+	}
+	// Two requests fit in the burst; a third would wait an hour.
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 2)
+	synced, _, err := syncAchievements(context.Background(), database, client, "76561198000000000", []model.Game{{AppID: 620, Name: "Portal 2"}}, limiter)
+	if err != nil || synced != 1 {
+		t.Fatalf("syncAchievements: %d synced, %v", synced, err)
+	}
+	if tokens := limiter.Tokens(); tokens >= 1 {
+		t.Errorf("the achievements and schema requests should both take a token, %.1f left", tokens)
+	}
+}
+
+func TestSyncLibrary(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	if err := database.UpsertGames(steamID, []model.Game{
+		{AppID: 1, Name: "Kept", PlaytimeForever: 10},
+		{AppID: 2, Name: "Played More", PlaytimeForever: 10},
+		{AppID: 3, Name: "Refunded"},
+	}); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	// As stored by an earlier sync without free games.
+	if err := database.MarkNotFreeToPlay(steamID, []int{1, 2, 3}); err != nil {
+		t.Fatalf("MarkNotFreeToPlay error: %v", err)
+	}
+
+	client := &MockSteamClient{Games: []model.Game{
+		{AppID: 1, Name: "Kept", PlaytimeForever: 10},
+		{AppID: 2, Name: "Played More", PlaytimeForever: 60},
+		{AppID: 4, Name: "New"},
+	}}
+	res, err := syncLibrary(context.Background(), database, client, steamID, false)
+	if err != nil {
+		t.Fatalf("syncLibrary error: %v", err)
+	}
+	if res.New != 1 || res.Updated != 1 || res.Unchanged != 1 || len(res.Removed) != 1 || res.Removed[0].AppID != 3 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	games, err := database.GetOwnedGames(steamID)
+	if err != nil || len(games) != 3 {
+		t.Errorf("got %d owned games, %v, want 3", len(games), err)
+	}
+}
+
+func TestSyncWithoutFreeGamesKeepsFreeToPlay(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	ctx := context.Background()
+	paid := model.Game{AppID: 1, Name: "Portal 2", PlaytimeForever: 10}
+	free := model.Game{AppID: 440, Name: "Team Fortress 2", PlaytimeForever: 90}
+
+	if _, err := syncLibrary(ctx, database, &MockSteamClient{Games: []model.Game{paid, free}}, steamID, true); err != nil {
+		t.Fatalf("syncLibrary with free games error: %v", err)
+	}
+	res, err := syncLibrary(ctx, database, &MockSteamClient{Games: []model.Game{paid}}, steamID, false)
+	if err != nil {
+		t.Fatalf("syncLibrary error: %v", err)
+	}
+	if len(res.Removed) != 0 {
+		t.Errorf("sync without free games removed %+v", res.Removed)
+	}
+	if games, _ := database.GetOwnedGames(steamID); len(games) != 2 {
+		t.Errorf("got %d owned games, want 2", len(games))
+	}
+
+	// A game a sync without free games returned before is still removed.
+	res, err = syncLibrary(ctx, database, &MockSteamClient{Games: []model.Game{{AppID: 2, Name: "Other"}}}, steamID, false)
+	if err != nil {
+		t.Fatalf("syncLibrary error: %v", err)
+	}
+	if len(res.Removed) != 1 || res.Removed[0].AppID != 1 {
+		t.Errorf("removed %+v, want only appid 1", res.Removed)
+	}
+
+	// Games stored before the mark existed are told apart by their store
+	// details.
+	if err := database.UpsertGames(steamID, []model.Game{{AppID: 620, Name: "Portal 2"}, {AppID: 570, Name: "Dota 2"}}); err != nil {
+		t.Fatal(err)
+	}
+	for id, isFree := range map[int]bool{620: false, 570: true} {
+		details := model.AppDetailsResponse{strconv.Itoa(id): {Success: true, Data: model.AppDetails{Type: "game", IsFree: isFree}}}
+		if err := database.UpsertAppDetails(id, details); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err = syncLibrary(ctx, database, &MockSteamClient{Games: []model.Game{{AppID: 2, Name: "Other"}}}, steamID, false)
+	if err != nil {
+		t.Fatalf("syncLibrary error: %v", err)
+	}
+	if len(res.Removed) != 1 || res.Removed[0].AppID != 620 {
+		t.Errorf("removed %+v, want only appid 620", res.Removed)
+	}
+}
+
+func TestLibraryServiceChanges(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	if err := database.UpsertGames(steamID, []model.Game{{AppID: 1, Name: "Portal"}}); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	svc := &libraryService{db: database, steamID: steamID}
+	ctx := context.Background()
+
+	game, err := svc.SetStatus(ctx, 1, "beaten")
+	if err != nil || game.Status != model.StatusBeaten {
+		t.Fatalf("SetStatus: got %+v, %v", game, err)
+	}
+	var serr *server.Error
+	if _, err := svc.SetStatus(ctx, 1, "backlog"); !errors.As(err, &serr) || serr.Status != http.StatusConflict {
+		t.Errorf("beaten -> backlog: got %v, want a conflict", err)
+	}
+	if _, err := svc.SetNote(ctx, 2, "Not owned"); !errors.As(err, &serr) || serr.Status != http.StatusNotFound {
+		t.Errorf("note for a game outside the library: got %v, want not found", err)
+	}
+
+	game, err = svc.SetNote(ctx, 1, "Try the co-op campaign")
+	if err != nil || game.Note != "Try the co-op campaign" {
+		t.Errorf("SetNote: got %+v, %v", game, err)
+	}
+}
+
+func TestInteractivePick(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	var games []model.Game
+	for i := 1; i <= 20; i++ {
+		games = append(games, model.Game{AppID: i, Name: "Game " + strconv.Itoa(i)})
+	}
+	if err := database.UpsertGames(steamID, games); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	games, err = database.GetOwnedGames(steamID)
+	if err != nil {
+		t.Fatalf("GetOwnedGames error: %v", err)
+	}
+
+	// The session's picks are the plain picks with seeds 42 and 43.
+	ctx := context.Background()
+	var want []int
+	for _, seed := range []int64{42, 43} {
+		g, err := pickGame(ctx, database, steamID, games, pickOptions{Seed: seed}, time.Now())
+		if err != nil {
+			t.Fatalf("pickGame error: %v", err)
+		}
+		want = append(want, g.AppID)
+	}
+
+	opener := &recordingOpener{}
+	var out bytes.Buffer
+	picker := &tui.Picker{
+		Backend: &pickSession{
+			db:      database,
+			steamID: steamID,
+			games:   games,
+			opener:  opener,
+		},
+		Seed: 42,
+		In:   strings.NewReader("o\nr\na\n"),
+		Out:  &out,
+	}
+	accepted, err := picker.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if accepted == nil || accepted.AppID != want[1] {
+		t.Fatalf("accepted %+v, want appid %d", accepted, want[1])
+	}
+	if len(opener.urls) != 1 || opener.urls[0] != "https://store.steampowered.com/app/"+strconv.Itoa(want[0]) {
+		t.Errorf("opened %v, want the store page of %d", opener.urls, want[0])
+	}
+
+	status, err := database.GetGameStatus(steamID, accepted.AppID)
+	if err != nil || status != model.StatusPlaying {
+		t.Errorf("status after accept: got %q, %v", status, err)
+	}
+	picks, err := database.GetPicksSince(steamID, time.Time{})
+	if err != nil {
+		t.Fatalf("GetPicksSince error: %v", err)
+	}
+	if len(picks) != 1 || picks[0].AppID != accepted.AppID || picks[0].Seed != 43 || picks[0].Flags["interactive"] != "true" {
+		t.Errorf("recorded picks: got %+v", picks)
+	}
+}
+
+type recordingOpener struct {
+	urls []string
+	err  error
+}
+
+func (o *recordingOpener) Open(url string) error {
+	o.urls = append(o.urls, url)
+	return o.err
+}
+
+func TestLaunchGame(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	opener := &recordingOpener{err: errors.New("no handler")}
+	if err := launchGame(database, opener, steamID, 620); err == nil {
+		t.Fatal("launchGame succeeded with a failing opener")
+	}
+	if status, _ := database.GetGameStatus(steamID, 620); status != "" {
+		t.Errorf("status after failed launch: got %q, want none", status)
+	}
+
+	opener.err = nil
+	if err := setGameStatus(database, steamID, 620, model.StatusBeaten, false); err != nil {
+		t.Fatalf("setGameStatus error: %v", err)
+	}
+	if err := launchGame(database, opener, steamID, 620); err != nil {
+		t.Fatalf("launchGame error: %v", err)
+	}
+	if want := []string{"steam://rungameid/620", "steam://rungameid/620"}; !reflect.DeepEqual(opener.urls, want) {
+		t.Errorf("opened %v, want %v", opener.urls, want)
+	}
+	history, err := database.GetStatusHistory(steamID, 620)
+	if err != nil {
+		t.Fatalf("GetStatusHistory error: %v", err)
+	}
+	if len(history) != 2 || history[1].To != model.StatusPlaying || history[1].ChangedAt.IsZero() {
+		t.Errorf("status history after launch: got %+v", history)
+	}
+
+	// Launching a game that is already playing leaves its status alone.
+	if err := launchGame(database, opener, steamID, 620); err != nil {
+		t.Fatalf("launchGame error: %v", err)
+	}
+	if history, _ := database.GetStatusHistory(steamID, 620); len(history) != 2 {
+		t.Errorf("relaunch added status changes: got %+v", history)
+	}
+}
+
+func TestInteractivePickLaunch(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	games := []model.Game{{AppID: 10, Name: "Only"}}
+	if err := database.UpsertGames(steamID, games); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+
+	opener := &recordingOpener{}
+	picker := &tui.Picker{
+		Backend: &pickSession{db: database, steamID: steamID, games: games, opener: opener, launch: true},
+		Seed:    1,
+		In:      strings.NewReader("a\n"),
+		Out:     &bytes.Buffer{},
+	}
+	if _, err := picker.Run(context.Background()); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if len(opener.urls) != 1 || opener.urls[0] != "steam://rungameid/10" {
+		t.Errorf("opened %v, want steam://rungameid/10", opener.urls)
+	}
+	picks, err := database.GetPicksSince(steamID, time.Time{})
+	if err != nil || len(picks) != 1 || picks[0].Flags["launch"] != "true" {
+		t.Errorf("recorded picks: got %+v, %v", picks, err)
+	}
+}
+
+func TestPickGameWithoutDatabase(t *testing.T) {
+	games := []model.Game{{AppID: 1, Name: "Played", PlaytimeForever: 60}, {AppID: 2, Name: "Unplayed"}}
+	picked, err := pickGame(context.Background(), nil, "76561198000000000", games, pickOptions{Seed: 1}, time.Now())
+	if err != nil {
+		t.Fatalf("pickGame error: %v", err)
+	}
+	if picked.AppID != 2 || picked.StoreURL == "" {
+		t.Errorf("picked %+v, want appid 2 with a store URL", picked)
+	}
+}
+
+func TestPickInstalledOnly(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	games := []model.Game{{AppID: 1, Name: "Installed"}, {AppID: 2, Name: "Not installed"}}
+	if err := database.UpsertGames(steamID, games); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	ctx := context.Background()
+	opts := pickOptions{Seed: 1, InstalledOnly: true}
+
+	var empty nothingToPick
+	if _, err := pickGame(ctx, database, steamID, games, opts, time.Now()); !errors.As(err, &empty) {
+		t.Errorf("before a scan: got %v, want nothing to pick", err)
+	}
+
+	if err := database.ReplaceInstalledGames([]model.InstalledGame{{AppID: 1, Name: "Installed"}}, time.Now()); err != nil {
+		t.Fatalf("ReplaceInstalledGames error: %v", err)
+	}
+	for seed := int64(1); seed <= 10; seed++ {
+		opts.Seed = seed
+		picked, err := pickGame(ctx, database, steamID, games, opts, time.Now())
+		if err != nil || picked.AppID != 1 {
+			t.Fatalf("seed %d: got %+v, %v; want the installed game", seed, picked, err)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for in, want := range map[int64]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KiB", 5 << 30: "5.0 GiB"} {
+		if got := formatSize(in); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestImportLocalLibrary(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	if err := database.UpsertGames(steamID, []model.Game{
+		{AppID: 620, Name: "Portal 2", PlaytimeForever: 600, RTimeLastPlayed: 100},
+		{AppID: 400, Name: "Portal", PlaytimeForever: 0},
+		{AppID: 70, Name: "App 70"},
+	}); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+
+	res, err := importLocalLibrary(database, steamID, []model.Game{
+		{AppID: 620, Name: "Portal 2 (local)", PlaytimeForever: 95, RTimeLastPlayed: 200},
+		{AppID: 400, Name: "Portal"},
+		{AppID: 70, Name: "Half-Life"},
+		{AppID: 10, PlaytimeForever: 5},
+		{AppID: 30, Name: "Day of Defeat", PlaytimeForever: 2},
+	})
+	if err != nil {
+		t.Fatalf("importLocalLibrary error: %v", err)
+	}
+	if res.New != 2 || res.Updated != 2 || res.Unchanged != 1 {
+		t.Errorf("result: got %+v", res)
+	}
+
+	games, err := database.GetOwnedGames(steamID)
+	if err != nil {
+		t.Fatalf("GetOwnedGames error: %v", err)
+	}
+	got := map[int]model.Game{}
+	for _, g := range games {
+		got[g.AppID] = g
+	}
+	// The synced name and higher playtime stay; last played moves forward.
+	if g := got[620]; g.Name != "Portal 2" || g.PlaytimeForever != 600 || g.RTimeLastPlayed != 200 {
+		t.Errorf("620: got %+v", g)
+	}
+	if g := got[70]; g.Name != "Half-Life" {
+		t.Errorf("70 should take the manifest name over the placeholder, got %+v", g)
+	}
+	if g := got[10]; g.Name != "App 10" || g.PlaytimeForever != 5 {
+		t.Errorf("10: got %+v", g)
+	}
+
+	// A sync replaces the placeholder even though the playtime is the same,
+	// and tombstones the imported game the account does not own, e.g. a
+	// family-shared one, even without free-to-play games.
+	client := &MockSteamClient{Games: []model.Game{
+		{AppID: 620, Name: "Portal 2", PlaytimeForever: 600, RTimeLastPlayed: 200},
+		{AppID: 400, Name: "Portal"},
+		{AppID: 70, Name: "Half-Life"},
+		{AppID: 10, Name: "Counter-Strike", PlaytimeForever: 5},
+	}}
+	res, err = syncLibrary(context.Background(), database, client, steamID, false)
+	if err != nil {
+		t.Fatalf("syncLibrary error: %v", err)
+	}
+	if res.Updated != 1 || res.Unchanged != 3 || len(res.Removed) != 1 || res.Removed[0].AppID != 30 {
+		t.Errorf("sync result: got %+v", res)
+	}
+	games, err = database.GetOwnedGames(steamID)
+	if err != nil {
+		t.Fatalf("GetOwnedGames error: %v", err)
+	}
+	for _, g := range games {
+		if g.AppID == 10 && g.Name != "Counter-Strike" {
+			t.Errorf("10 should take the synced name, got %+v", g)
+		}
+	}
+}
+
+func TestTaggedPickAndExport(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	var games []model.Game
+	for i := 1; i <= 10; i++ {
+		games = append(games, model.Game{AppID: i, Name: "Game " + strconv.Itoa(i)})
+	}
+	if err := database.UpsertGames(steamID, games); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	if err := database.AddTag(steamID, "Couch", []int{3}, time.Now()); err != nil {
+		t.Fatalf("AddTag error: %v", err)
+	}
+	if err := database.ImportCollection(steamID, "Favorites", "favorite", []int{4}, time.Now()); err != nil {
+		t.Fatalf("ImportCollection error: %v", err)
+	}
+
+	ctx := context.Background()
+	for seed := int64(1); seed <= 5; seed++ {
+		picked, err := pickGame(ctx, database, steamID, games, pickOptions{Seed: seed, Tag: "couch"}, time.Now())
+		if err != nil || picked.AppID != 3 {
+			t.Fatalf("seed %d: got %+v, %v; want the tagged game", seed, picked, err)
+		}
+	}
+	var empty nothingToPick
+	if _, err := pickGame(ctx, database, steamID, games, pickOptions{Seed: 1, Tag: "Solo"}, time.Now()); !errors.As(err, &empty) {
+		t.Errorf("unknown tag: got %v, want nothing to pick", err)
+	}
+
+	// Only tags made in steam-pick are exported, with a stable ID once set.
+	collections, err := exportCollections(database, steamID)
+	if err != nil {
+		t.Fatalf("exportCollections error: %v", err)
+	}
+	if len(collections) != 1 || collections[0].Name != "Couch" || !strings.HasPrefix(collections[0].ID, "uc-") {
+		t.Fatalf("collections: got %+v", collections)
+	}
+	if err := database.SetTagCollectionID(steamID, "Couch", collections[0].ID); err != nil {
+		t.Fatalf("SetTagCollectionID error: %v", err)
+	}
+	again, _ := exportCollections(database, steamID)
+	if len(again) != 1 || again[0].ID != collections[0].ID {
+		t.Errorf("second export: got %+v, want ID %s", again, collections[0].ID)
+	}
+
+	// Deleting the exported tag deletes its collection on the next export.
+	if err := database.RemoveTag(steamID, "Couch", nil); err != nil {
+		t.Fatalf("RemoveTag error: %v", err)
+	}
+	deleted, _ := exportCollections(database, steamID)
+	if len(deleted) != 1 || deleted[0].ID != collections[0].ID || !deleted[0].Deleted {
+		t.Errorf("export after delete: got %+v", deleted)
+	}
+}
+
+func TestNewEnrichRegistryPriorities(t *testing.T) {
+	if _, err := newEnrichRegistry(nil, []string{"pcgw=200", "pcgw.genres=300", "steam.name=5"}); err != nil {
+		t.Fatalf("newEnrichRegistry error: %v", err)
+	}
+	for _, p := range []string{"pcgw", "pcgw=high", "gog=1", "pcgw.website=1"} {
+		if _, err := newEnrichRegistry(nil, []string{p}); err == nil {
+			t.Errorf("--priority %q was accepted", p)
+		}
+	}
+}
+
+func TestStoredAppDetails(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	// Details stored before sources were recorded came from the store.
+	legacy := model.AppDetailsResponse{"620": {Success: true, Data: model.AppDetails{Type: "game", Name: "Portal 2"}}}
+	if err := database.UpsertAppDetails(620, legacy); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.UpsertMergedAppDetails(400, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, sources, err := storedAppDetails(database, 620)
+	if err != nil || stored == nil || stored.Name != "Portal 2" {
+		t.Fatalf("storedAppDetails(620) = %+v, %v", stored, err)
+	}
+	if sources["type"] != "steam" || sources["name"] != "steam" {
+		t.Errorf("legacy sources: got %v", sources)
+	}
+	if stored, _, err := storedAppDetails(database, 400); err != nil || stored != nil {
+		t.Errorf("the stub should count as no details, got %+v, %v", stored, err)
+	}
+}
+
+func TestLLMFlagsShareDefaults(t *testing.T) {
+	for _, cmd := range []*cobra.Command{embedCmd, searchCmd, recommendCmd, mcpCmd, serveCmd, llmCheckCmd} {
+		f := cmd.Flags().Lookup("embed-model")
+		if f == nil || f.DefValue != defaultEmbedModel {
+			t.Errorf("%s: unexpected --embed-model flag %+v", cmd.Name(), f)
+		}
+	}
+	for _, cmd := range []*cobra.Command{chatCmd, recommendCmd, mcpCmd, serveCmd} {
+		f := cmd.Flags().Lookup("llm-model")
+		if f == nil || f.DefValue != defaultLLMModel {
+			t.Errorf("%s: unexpected --llm-model flag %+v", cmd.Name(), f)
+		}
+	}
+	if llmEmbedModel != defaultEmbedModel || llmModel != defaultLLMModel || llmBaseURL != "" {
+		t.Errorf("shared settings lost their defaults: %q %q %q", llmEmbedModel, llmModel, llmBaseURL)
+	}
+}
+
+func TestLoadOwnedGamesFallsBackToStoredLibrary(t *testing.T) {
+	steamID := "76561198000000000"
+	viper.Set("api_key", "test-key")
+	viper.Set("steamid64", steamID)
+	t.Cleanup(func() {
+		viper.Set("api_key", "")
+		viper.Set("steamid64", "")
+	})
+
+	oldFactory := NewSteamClient
+	defer func() { NewSteamClient = oldFactory }()
+	NewSteamClient = func(apiKey string, ttl, vanityTTL, timeout time.Duration) (SteamClient, error) {
+		return &MockSteamClient{GamesErr: errors.New("network is unreachable")}, nil
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("include-free-games", false, "")
+	cmd.Flags().Duration("cache-ttl", time.Hour, "")
+	cmd.Flags().Duration("timeout", time.Second, "")
+	cmd.Flags().Duration("sync-interval", 0, "")
+
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	// An empty database has nothing to fall back to.
+	if _, _, err := loadOwnedGames(context.Background(), cmd, database, false); err == nil {
+		t.Error("expected the fetch error without stored games")
+	}
+
+	if _, err := importLocalLibrary(database, steamID, []model.Game{{AppID: 620, PlaytimeForever: 5}}); err != nil {
+		t.Fatalf("importLocalLibrary error: %v", err)
+	}
+	gotID, games, err := loadOwnedGames(context.Background(), cmd, database, false)
+	if err != nil {
+		t.Fatalf("loadOwnedGames should fall back to the stored library: %v", err)
+	}
+	if gotID != steamID || len(games) != 1 || games[0].AppID != 620 {
+		t.Errorf("got %s, %+v", gotID, games)
+	}
+}
+
+func TestTagAddExportImportRoundTrip(t *testing.T) {
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	steamID := "76561198000000000"
+	path := filepath.Join(t.TempDir(), "cloud-storage-namespace-1.json")
+	importAll := func() {
+		t.Helper()
+		collections, err := steamlocal.ReadCollections(path)
+		if err != nil {
+			t.Fatalf("ReadCollections error: %v", err)
+		}
+		for _, c := range collections {
+			if err := database.ImportCollection(steamID, c.Name, c.ID, c.AppIDs, time.Now()); err != nil {
+				t.Fatalf("ImportCollection error: %v", err)
+			}
+		}
+	}
+	exportAll := func() {
+		t.Helper()
+		collections, err := exportCollections(database, steamID)
+		if err != nil {
+			t.Fatalf("exportCollections error: %v", err)
+		}
+		if err := steamlocal.WriteCollections(path, collections, time.Now()); err != nil {
+			t.Fatalf("WriteCollections error: %v", err)
+		}
+		for _, c := range collections {
+			if err := database.SetTagCollectionID(steamID, c.Name, c.ID); err != nil {
+				t.Fatalf("SetTagCollectionID error: %v", err)
+			}
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(`[["user-collections.favorite", {"key": "user-collections.favorite", "timestamp": 1700000000, "value": "{\"id\":\"favorite\",\"added\":[10],\"removed\":[]}", "version": "3"}]]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	importAll()
+
+	// Adding to the imported tag is refused instead of being undone by the
+	// next import.
+	if err := database.AddTag(steamID, "Favorites", []int{20}, time.Now()); !errors.Is(err, db.ErrSteamTag) {
+		t.Fatalf("AddTag to an imported tag: got %v, want ErrSteamTag", err)
+	}
+
+	// A tag made here survives export and import with its additions.
+	if err := database.AddTag(steamID, "Couch", []int{620}, time.Now()); err != nil {
+		t.Fatalf("AddTag error: %v", err)
+	}
+	exportAll()
+	if err := database.AddTag(steamID, "Couch", []int{400}, time.Now()); err != nil {
+		t.Fatalf("AddTag error: %v", err)
+	}
+	exportAll()
+	importAll()
+
+	tags, err := database.GetTags(steamID)
+	if err != nil {
+		t.Fatalf("GetTags error: %v", err)
+	}
+	got := map[string][]int{}
+	for _, tag := range tags {
+		got[tag.Name] = tag.AppIDs
+	}
+	if !reflect.DeepEqual(got["Couch"], []int{400, 620}) || !reflect.DeepEqual(got["Favorites"], []int{10}) {
+		t.Errorf("tags after the round trip: %v", got)
+	}
+}
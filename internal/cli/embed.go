@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/llm"
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/spf13/cobra"
+)
+
+var embedRefresh bool
+
+var embedCmd = &cobra.Command{
+	Use:   "embed",
+	Short: "Compute embeddings of enriched games for semantic recommendations",
+	Run: func(cmd *cobra.Command, args []string) {
+		if llmEmbedModel == "" {
+			fmt.Fprintln(os.Stderr, "Error: --embed-model is required")
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		client, err := newLLMClient(llmBaseURL, "", llmEmbedModel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		embedded, skipped, err := embedGames(context.Background(), database, client, steamID, llmEmbedModel, embedRefresh)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Embedded %d games (%d unchanged).\n", embedded, skipped)
+	},
+}
+
+// embedGames embeds every enriched game of the account whose text changed
+// since it was last embedded with model.
+func embedGames(ctx context.Context, database *db.DB, client llm.Client, steamID, model string, refresh bool) (embedded, skipped int, err error) {
+	games, err := database.GetGamesWithDetails(steamID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("fetching games: %w", err)
+	}
+	hashes, err := database.GetEmbeddingHashes(model)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, g := range games {
+		if !g.HasDetails || !g.Classification.IsGame() {
+			continue
+		}
+		desc, _ := database.GetAppDescription(g.AppID)
+		text := logic.EmbeddingText(g.Name, g.GenreNames(), g.CategoryNames(), desc)
+		sum := sha256.Sum256([]byte(text))
+		hash := hex.EncodeToString(sum[:])
+		if !refresh && hashes[g.AppID] == hash {
+			skipped++
+			continue
+		}
+
+		vector, err := client.Embed(ctx, text)
+		if err != nil {
+			return embedded, skipped, fmt.Errorf("embedding %s (%d): %w", g.Name, g.AppID, err)
+		}
+		if err := database.UpsertEmbedding(g.AppID, model, hash, vector); err != nil {
+			return embedded, skipped, err
+		}
+		embedded++
+	}
+	return embedded, skipped, nil
+}
+
+func init() {
+	rootCmd.AddCommand(embedCmd)
+	addLLMFlags(embedCmd, false, true)
+	embedCmd.Flags().BoolVar(&embedRefresh, "refresh", false, "Re-embed every game")
+}
@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/enrich"
 	"github.com/dajoen/steam-pick/internal/model"
 	"github.com/dajoen/steam-pick/internal/pcgw"
 	"github.com/dajoen/steam-pick/internal/steamapi"
@@ -18,14 +22,23 @@ import (
 )
 
 var (
-	enrichRateLimit int
-	enrichWorkers   int
-	enrichRefresh   bool
+	enrichRateLimit  int
+	enrichWorkers    int
+	enrichRefresh    bool
+	enrichPriorities []string
 )
 
 var enrichCmd = &cobra.Command{
 	Use:   "enrich",
 	Short: "Enrich game data with store details",
+	Long: `enrich fills in the details of owned games from every registered source
+and records which source supplied each field. The Steam Store supplies all
+fields; PCGamingWiki supplies names, developers, publishers and genres.
+
+Where two sources supply a field the higher priority wins. The defaults are
+steam=100 and pcgw=10, so PCGamingWiki only fills gaps. Change them with
+--priority source=N for a whole source or --priority source.field=N for one
+field, e.g. --priority pcgw.genres=200.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		apiKey, err := getAPIKey()
 		if err != nil {
@@ -49,17 +62,23 @@ var enrichCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		database, err := db.New("steam-pick")
+		database, err := openDB()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 		defer func() { _ = database.Close() }()
 
+		steamID, err := getSteamID(context.Background(), client, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 		var gamesToEnrich []model.Game
 		if enrichRefresh {
 			fmt.Println("Refresh enabled: Fetching all owned games...")
-			games, err := database.GetOwnedGames()
+			games, err := database.GetOwnedGames(steamID)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error fetching games from DB: %v\n", err)
 				os.Exit(1)
@@ -67,7 +86,7 @@ var enrichCmd = &cobra.Command{
 			gamesToEnrich = games
 		} else {
 			fmt.Println("Fetching games missing details...")
-			games, err := database.GetGamesMissingDetails()
+			games, err := database.GetGamesMissingDetails(steamID)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error fetching missing games from DB: %v\n", err)
 				os.Exit(1)
@@ -86,8 +105,11 @@ var enrichCmd = &cobra.Command{
 		r := rate.Limit(float64(enrichRateLimit) / 60.0)
 		limiter := rate.NewLimiter(r, 1)
 
-		// PCGW client
-		pcgwClient := pcgw.NewClient()
+		registry, err := newEnrichRegistry(client, enrichPriorities)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
 		var wg sync.WaitGroup
 		sem := make(chan struct{}, enrichWorkers)
@@ -119,39 +141,54 @@ var enrichCmd = &cobra.Command{
 				}
 
 				fmt.Printf("[%d/%d] Fetching details for %s (%d)...\n", idx+1, len(gamesToEnrich), g.Name, g.AppID)
-				details, err := client.GetAppDetails(ctx, g.AppID)
-				if err != nil {
+				res, err := registry.Enrich(ctx, g.AppID)
+				if err != nil || ctx.Err() != nil {
+					// Context cancelled; don't store a stub for a partial result.
+					return
+				}
+				for _, err := range res.Errors {
 					if errors.Is(err, steamapi.ErrRateLimitExceeded) {
 						fmt.Fprintf(os.Stderr, "Rate limit exceeded! Stopping enrichment.\n")
 						cancel()
 						return
 					}
+				}
 
-					// Try PCGamingWiki fallback
-					fmt.Printf("Steam Store failed for %s (%d). Trying PCGamingWiki...\n", g.Name, g.AppID)
-					pcgwDetails, pcgwErr := pcgwClient.GetAppDetails(ctx, g.AppID)
-					if pcgwErr == nil {
-						// Use the name from our DB since PCGW might not return it cleanly
-						entry := (*pcgwDetails)[fmt.Sprintf("%d", g.AppID)]
-						entry.Data.Name = g.Name
-						(*pcgwDetails)[fmt.Sprintf("%d", g.AppID)] = entry
-
-						details = pcgwDetails
-						fmt.Printf("Found details for %s on PCGamingWiki.\n", g.Name)
-					} else {
-						// Don't print error if context was cancelled
-						if ctx.Err() == nil {
-							fmt.Fprintf(os.Stderr, "Failed to fetch details for %s (%d): %v (PCGW: %v)\n", g.Name, g.AppID, err, pcgwErr)
-						}
-						// If both fail, we still want to save a stub so we don't retry forever.
-						// We create a fake "failed" response which UpsertAppDetails handles by creating a stub.
-						details = &model.AppDetailsResponse{
-							fmt.Sprintf("%d", g.AppID): {Success: false},
-						}
+				stored, sources, err := storedAppDetails(database, g.AppID)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to read details for %s (%d): %v\n", g.Name, g.AppID, err)
+					return
+				}
+
+				if !res.Found() {
+					if !notFoundAnywhere(res) {
+						// Keep what we have and retry on the next run.
+						fmt.Fprintf(os.Stderr, "Failed to fetch details for %s (%d): %s\n", g.Name, g.AppID, sourceErrors(res))
+						return
+					}
+					if stored != nil {
+						return
+					}
+					// No source knows the game; save a stub so we don't retry forever.
+					if err := database.UpsertMergedAppDetails(g.AppID, nil, nil); err != nil {
+						fmt.Fprintf(os.Stderr, "Failed to save details for %s (%d): %v\n", g.Name, g.AppID, err)
 					}
+					return
+				}
+
+				if stored != nil {
+					res = registry.Merge(stored, sources, res)
+				}
+				details := &res.Details
+				if details.Name == "" {
+					// Sources without a name still belong to the game in our library.
+					details.Name = g.Name
+				}
+				if len(res.Errors) > 0 {
+					fmt.Printf("Filled %s (%d) from %s (%s).\n", g.Name, g.AppID, strings.Join(detailSources(res), ", "), sourceErrors(res))
 				}
 
-				if err := database.UpsertAppDetails(g.AppID, *details); err != nil {
+				if err := database.UpsertMergedAppDetails(g.AppID, details, res.Sources); err != nil {
 					fmt.Fprintf(os.Stderr, "Failed to save details for %s (%d): %v\n", g.Name, g.AppID, err)
 				}
 			}(i, game)
@@ -171,4 +208,87 @@ func init() {
 	enrichCmd.Flags().IntVar(&enrichRateLimit, "rate-limit-per-minute", 30, "Rate limit per minute")
 	enrichCmd.Flags().IntVar(&enrichWorkers, "workers", 1, "Number of concurrent workers")
 	enrichCmd.Flags().BoolVar(&enrichRefresh, "refresh", false, "Refresh existing data")
+	enrichCmd.Flags().StringArrayVar(&enrichPriorities, "priority", nil, "Source priority as source=N or source.field=N (repeatable)")
+}
+
+// newEnrichRegistry registers the Steam Store and PCGamingWiki and applies
+// priority overrides of the form source=N or source.field=N.
+func newEnrichRegistry(store enrich.StoreDetailsClient, priorities []string) (*enrich.Registry, error) {
+	registry := enrich.NewRegistry()
+	if err := registry.Register(&enrich.Steam{Client: store}, 100); err != nil {
+		return nil, err
+	}
+	if err := registry.Register(&enrich.PCGW{Client: pcgw.NewClient()}, 10); err != nil {
+		return nil, err
+	}
+	for _, p := range priorities {
+		key, value, ok := strings.Cut(p, "=")
+		n, err := strconv.Atoi(value)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid --priority %q (use source=N or source.field=N)", p)
+		}
+		name, field, _ := strings.Cut(key, ".")
+		if err := registry.SetPriority(name, field, n); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// storedAppDetails returns an app's stored details and the source of each
+// field. Details stored before sources were recorded came from the Steam
+// Store; the unavailable stub counts as no details.
+func storedAppDetails(database *db.DB, appID int) (*model.AppDetails, map[string]string, error) {
+	stored, err := database.GetAppDetails(appID)
+	if err != nil || stored == nil {
+		return nil, nil, err
+	}
+	sources, err := database.GetAppDetailSources(appID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sources) == 0 {
+		if stored.Type == "" && stored.Name == "Unavailable" {
+			return nil, nil, nil
+		}
+		for _, field := range enrich.SetFields(stored) {
+			sources[field] = "steam"
+		}
+	}
+	return stored, sources, nil
+}
+
+// notFoundAnywhere reports whether every source that was asked has no entry
+// for the app, as opposed to failing.
+func notFoundAnywhere(res *enrich.Result) bool {
+	for _, err := range res.Errors {
+		if !errors.Is(err, enrich.ErrNotFound) {
+			return false
+		}
+	}
+	return len(res.Errors) > 0
+}
+
+// detailSources returns the sources that supplied fields, sorted.
+func detailSources(res *enrich.Result) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, s := range res.Sources {
+		if !seen[s] {
+			seen[s] = true
+			names = append(names, s)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sourceErrors describes the failed sources of a result.
+func sourceErrors(res *enrich.Result) string {
+	var msgs []string
+	for name, err := range res.Errors {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", name, err))
+	}
+	sort.Strings(msgs)
+	return strings.Join(msgs, "; ")
 }
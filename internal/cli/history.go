@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyPeriod string
+	historyOutput string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [appid]",
+	Short: "Show playtime history recorded by sync",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		appID := 0
+		if len(args) == 1 {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid appid %q\n", args[0])
+				os.Exit(1)
+			}
+			appID = id
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		snapshots, err := database.GetPlaytimeSnapshots(steamID, appID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching history: %v\n", err)
+			os.Exit(1)
+		}
+
+		periods, err := logic.PlaytimeByPeriod(snapshots, historyPeriod)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		started := logic.StartedSinceLastSync(snapshots)
+
+		if historyOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			out := struct {
+				Periods []logic.PeriodPlaytime   `json:"periods"`
+				Started []model.PlaytimeSnapshot `json:"started_since_last_sync"`
+			}{periods, started}
+			if err := enc.Encode(out); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(snapshots) == 0 {
+			fmt.Println("No playtime history yet. Run 'steam-pick sync' to record snapshots.")
+			return
+		}
+
+		fmt.Printf("Playtime per %s:\n", historyPeriod)
+		fmt.Printf("%-12s %s\n", "Period", "Minutes")
+		fmt.Println("------------------------------")
+		for _, p := range periods {
+			fmt.Printf("%-12s %d\n", p.Period, p.Minutes)
+		}
+
+		fmt.Println()
+		if len(started) == 0 {
+			fmt.Println("No backlog games started since the last sync.")
+			return
+		}
+		fmt.Println("Backlog games started since the last sync:")
+		for _, s := range started {
+			fmt.Printf("%d: %s (%d min)\n", s.AppID, s.Name, s.PlaytimeForever)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().StringVar(&historyPeriod, "period", "day", "Group playtime by 'day', 'week' or 'month'")
+	historyCmd.Flags().StringVar(&historyOutput, "output", "table", "Output format 'table' or 'json'")
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/dajoen/steam-pick/internal/launcher"
+	"github.com/dajoen/steam-pick/internal/llm"
 	"github.com/dajoen/steam-pick/internal/model"
 	"github.com/dajoen/steam-pick/internal/steamapi"
 	"github.com/dajoen/steam-pick/internal/storeapi"
@@ -13,11 +15,13 @@ import (
 type SteamClient interface {
 	ResolveVanityURL(ctx context.Context, vanityURL string) (string, error)
 	GetOwnedGames(ctx context.Context, steamID64 string, includeFree bool) ([]model.Game, error)
+	GetPlayerAchievements(ctx context.Context, steamID64 string, appID int) ([]model.PlayerAchievement, error)
+	GetSchemaForGame(ctx context.Context, appID int) ([]model.SchemaAchievement, error)
 }
 
 // StoreClient defines the interface for Steam Store API interactions.
 type StoreClient interface {
-	IsTurnBased(ctx context.Context, appID int, country string) (bool, error)
+	GetAppDetails(ctx context.Context, appID int, country string) (*model.AppDetails, error)
 }
 
 // ClientFactoryFunc is a function that creates a SteamClient.
@@ -26,6 +30,12 @@ type ClientFactoryFunc func(apiKey string, ttl, vanityTTL, timeout time.Duration
 // StoreClientFactoryFunc is a function that creates a StoreClient.
 type StoreClientFactoryFunc func(timeout time.Duration) StoreClient
 
+// LLMClientFactoryFunc is a function that creates an llm.Client.
+type LLMClientFactoryFunc func(cfg llm.Config) (llm.Client, error)
+
+// OpenerFactoryFunc is a function that creates a launcher.Opener.
+type OpenerFactoryFunc func() launcher.Opener
+
 // Default factories
 var (
 	NewSteamClient ClientFactoryFunc = func(apiKey string, ttl, vanityTTL, timeout time.Duration) (SteamClient, error) {
@@ -34,4 +44,8 @@ var (
 	NewStoreClient StoreClientFactoryFunc = func(timeout time.Duration) StoreClient {
 		return storeapi.NewClient(timeout)
 	}
+	NewLLMClient LLMClientFactoryFunc = llm.New
+	NewOpener    OpenerFactoryFunc    = func() launcher.Opener {
+		return &launcher.DefaultOpener{}
+	}
 )
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/dajoen/steam-pick/internal/launcher"
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var launchCmd = &cobra.Command{
+	Use:   "launch <appid>",
+	Short: "Launch a game in the Steam client and mark it as playing",
+	Long: `launch opens steam://rungameid/<appid>, which makes the Steam client
+start the game, installing it first when needed. The game's status becomes
+playing; a game already playing keeps its original timestamp.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid appid %q\n", args[0])
+			os.Exit(1)
+		}
+
+		database, steamID := openPickDB()
+		defer func() { _ = database.Close() }()
+
+		if err := launchGame(database, NewOpener(), steamID, appID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Launched %d; it is now playing.\n", appID)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(launchCmd)
+}
+
+// launchGame launches a game and marks it as playing. The status is only
+// changed once the Steam client has been asked to start the game.
+func launchGame(store logic.StatusStore, opener launcher.Opener, steamID string, appID int) error {
+	if err := launcher.Launch(opener, appID); err != nil {
+		return err
+	}
+	// Every status may move to playing, so no transition is refused here.
+	return setGameStatus(store, steamID, appID, model.StatusPlaying, false)
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// loadOwnedGames returns the selected account and its games. The local
+// database answers while its data is newer than --sync-interval; otherwise
+// the library is fetched from the Steam API and stored. When the fetch is
+// not possible, e.g. without an API key after 'local import' or without a
+// network, the stored games answer instead. offline never touches the
+// network. Without a database the library is always fetched.
+func loadOwnedGames(ctx context.Context, cmd *cobra.Command, database *db.DB, offline bool) (string, []model.Game, error) {
+	syncInterval, _ := cmd.Flags().GetDuration("sync-interval")
+	includeFree, _ := cmd.Flags().GetBool("include-free-games")
+	steamIDFlag := viper.GetString("steamid64")
+	vanity := viper.GetString("vanity")
+
+	// Resolve the account without the API when possible so the local
+	// database can answer on its own.
+	steamID, err := getSteamID(ctx, nil, steamIDFlag, vanity)
+	if offline {
+		if err != nil {
+			return "", nil, err
+		}
+		games, err := database.GetOwnedGames(steamID)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(games) == 0 {
+			return "", nil, fmt.Errorf("no games stored for %s (run 'steam-pick sync' or 'steam-pick local import' first)", steamID)
+		}
+		return steamID, games, nil
+	}
+
+	var stored []model.Game
+	if err == nil && database != nil {
+		stored, _ = database.GetOwnedGames(steamID)
+		if len(stored) > 0 {
+			if lastUpdate, err := database.GetLastUpdate(steamID); err == nil && time.Since(lastUpdate) <= syncInterval {
+				return steamID, stored, nil
+			}
+		}
+	}
+
+	fetchedID, games, err := fetchOwnedGames(ctx, cmd, steamIDFlag, vanity, includeFree)
+	if err != nil {
+		if len(stored) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using the stored library\n", err)
+			return steamID, stored, nil
+		}
+		return "", nil, err
+	}
+	steamID = fetchedID
+
+	if database == nil {
+		return steamID, games, nil
+	}
+	if err := database.UpsertGames(steamID, games); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to cache games: %v\n", err)
+	} else if err := markFetched(database, steamID, games, includeFree); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to cache games: %v\n", err)
+	}
+	if statuses, err := database.GetGameStatuses(steamID); err == nil {
+		logic.ApplyStatuses(games, statuses)
+	}
+	if classes, err := database.GetClassifications(); err == nil {
+		logic.ApplyClassifications(games, classes)
+	}
+	return steamID, games, nil
+}
+
+// fetchOwnedGames resolves the account and fetches its games from the Steam
+// API.
+func fetchOwnedGames(ctx context.Context, cmd *cobra.Command, steamIDFlag, vanity string, includeFree bool) (string, []model.Game, error) {
+	apiKey, err := getAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ttl, _ := cmd.Flags().GetDuration("cache-ttl")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	vanityTTL := viper.GetDuration("auth_cache_ttl")
+
+	client, err := NewSteamClient(apiKey, ttl, vanityTTL, timeout)
+	if err != nil {
+		return "", nil, fmt.Errorf("initializing client: %w", err)
+	}
+
+	steamID, err := getSteamID(ctx, client, steamIDFlag, vanity)
+	if err != nil {
+		return "", nil, err
+	}
+
+	games, err := client.GetOwnedGames(ctx, steamID, includeFree)
+	if err != nil {
+		return "", nil, fmt.Errorf("fetching games: %w", err)
+	}
+	return steamID, games, nil
+}
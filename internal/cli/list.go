@@ -7,9 +7,7 @@ import (
 	"os"
 	"time"
 
-	"github.com/dajoen/steam-pick/internal/db"
 	"github.com/dajoen/steam-pick/internal/logic"
-	"github.com/dajoen/steam-pick/internal/model"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -31,76 +29,58 @@ func init() {
 	listCmd.Flags().Duration("cache-ttl", 24*time.Hour, "Cache TTL")
 	listCmd.Flags().Duration("timeout", 15*time.Second, "HTTP Timeout")
 	listCmd.Flags().Duration("sync-interval", 24*time.Hour, "Time before forcing a sync")
+	listCmd.Flags().String("where", "", whereUsage)
+	listCmd.Flags().Bool("include-non-games", false, "Include DLC, soundtracks, tools and demos")
+	listCmd.Flags().Bool("installed-only", false, "Only list games found by 'local scan'")
+	listCmd.Flags().String("tag", "", "Only list games with this tag")
+	listCmd.Flags().Bool("offline", false, "Only use the local database, never the network")
 
 	_ = viper.BindPFlag("steamid64", listCmd.Flags().Lookup("steamid64"))
 	_ = viper.BindPFlag("vanity", listCmd.Flags().Lookup("vanity"))
 }
 
 func runList(cmd *cobra.Command, args []string) {
-	syncInterval, _ := cmd.Flags().GetDuration("sync-interval")
-	includeFree, _ := cmd.Flags().GetBool("include-free-games")
 	limit, _ := cmd.Flags().GetInt("limit")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
+	where, _ := cmd.Flags().GetString("where")
+	includeNonGames, _ := cmd.Flags().GetBool("include-non-games")
+	installedOnly, _ := cmd.Flags().GetBool("installed-only")
+	offline, _ := cmd.Flags().GetBool("offline")
+	tag, _ := cmd.Flags().GetString("tag")
 
-	database, err := db.New("steam-pick")
+	database, err := openDB()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
 		os.Exit(1)
 	}
 	defer func() { _ = database.Close() }()
 
-	lastUpdate, err := database.GetLastUpdate()
-	shouldSync := err != nil || time.Since(lastUpdate) > syncInterval
-
-	var games []model.Game
-	if !shouldSync {
-		games, err = database.GetOwnedGames()
-		if err != nil || len(games) == 0 {
-			shouldSync = true
-		}
+	steamID, games, err := loadOwnedGames(context.Background(), cmd, database, offline)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	if shouldSync {
-		apiKey, err := getAPIKey()
+	unplayed, err := filterWhere(database, steamID, logic.FilterUnplayed(games, includeNonGames), where)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if installedOnly {
+		unplayed, err = filterInstalled(database, unplayed)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-
-		steamID := viper.GetString("steamid64")
-		vanity := viper.GetString("vanity")
-
-		ttl, _ := cmd.Flags().GetDuration("cache-ttl")
-		timeout, _ := cmd.Flags().GetDuration("timeout")
-		vanityTTL := viper.GetDuration("auth_cache_ttl")
-
-		client, err := NewSteamClient(apiKey, ttl, vanityTTL, timeout)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
-			os.Exit(1)
-		}
-
-		ctx := context.Background()
-
-		steamID, err = getSteamID(ctx, client, steamID, vanity)
+	}
+	if tag != "" {
+		unplayed, err = filterTagged(database, steamID, unplayed, tag)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-
-		games, err = client.GetOwnedGames(ctx, steamID, includeFree)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error fetching games: %v\n", err)
-			os.Exit(1)
-		}
-
-		if err := database.UpsertGames(games); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to cache games: %v\n", err)
-		}
 	}
 
-	unplayed := logic.FilterUnplayed(games)
-
 	if len(unplayed) == 0 {
 		if jsonOutput {
 			fmt.Println("[]")
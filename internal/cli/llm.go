@@ -7,14 +7,27 @@ import (
 
 	"github.com/dajoen/steam-pick/internal/llm"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+// The LLM settings are shared by every command that talks to a model.
+// Each command registers them with the same defaults, so the order the
+// commands register their flags in does not matter.
 var (
 	llmBaseURL    string
 	llmModel      string
 	llmEmbedModel string
 )
 
+// llmBaseURLUsage documents the base URL flags, whose default depends on
+// --llm-provider.
+const llmBaseURLUsage = "LLM Base URL (default http://localhost:11434 for ollama, http://localhost:8080 for openai)"
+
+const (
+	defaultLLMModel   = "llama3"
+	defaultEmbedModel = "nomic-embed-text"
+)
+
 var llmCmd = &cobra.Command{
 	Use:   "llm",
 	Short: "Manage LLM integration",
@@ -24,21 +37,20 @@ var llmCheckCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Check LLM connection",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg := llm.Config{
-			BaseURL:    llmBaseURL,
-			Model:      llmModel,
-			EmbedModel: llmEmbedModel,
+		client, err := newLLMClient(llmBaseURL, llmModel, llmEmbedModel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		client := llm.NewOllamaClient(cfg)
 
-		fmt.Printf("Checking connection to %s...\n", llmBaseURL)
+		fmt.Printf("Checking connection to %s...\n", llmBaseURLOrDefault(llmBaseURL))
 		if err := client.Check(context.Background()); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 		fmt.Println("Connection successful.")
 
-		if llmModel != "" {
+		if cmd.Flags().Changed("model") {
 			fmt.Printf("Checking generation with model %s...\n", llmModel)
 			res, err := client.Generate(context.Background(), "Hello")
 			if err != nil {
@@ -47,14 +59,57 @@ var llmCheckCmd = &cobra.Command{
 			}
 			fmt.Printf("Response: %s\n", res)
 		}
+
+		if cmd.Flags().Changed("embed-model") {
+			fmt.Printf("Checking embeddings with model %s...\n", llmEmbedModel)
+			v, err := client.Embed(context.Background(), "Hello")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Embedding dimensions: %d\n", len(v))
+		}
 	},
 }
 
+// newLLMClient creates a client for the provider selected with
+// --llm-provider or llm_provider in the config file.
+func newLLMClient(baseURL, model, embedModel string) (llm.Client, error) {
+	return NewLLMClient(llm.Config{
+		Provider:   viper.GetString("llm_provider"),
+		BaseURL:    llmBaseURLOrDefault(baseURL),
+		Model:      model,
+		EmbedModel: embedModel,
+		APIKey:     viper.GetString("llm_api_key"),
+	})
+}
+
+// llmBaseURLOrDefault returns baseURL, or the default of the configured
+// provider when it is empty.
+func llmBaseURLOrDefault(baseURL string) string {
+	if baseURL != "" {
+		return baseURL
+	}
+	return llm.DefaultBaseURL(viper.GetString("llm_provider"))
+}
+
+// addLLMFlags registers --llm-base-url and, when asked for, --llm-model and
+// --embed-model.
+func addLLMFlags(cmd *cobra.Command, model, embed bool) {
+	cmd.Flags().StringVar(&llmBaseURL, "llm-base-url", "", llmBaseURLUsage)
+	if model {
+		cmd.Flags().StringVar(&llmModel, "llm-model", defaultLLMModel, "LLM Model")
+	}
+	if embed {
+		cmd.Flags().StringVar(&llmEmbedModel, "embed-model", defaultEmbedModel, "Embedding model")
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(llmCmd)
 	llmCmd.AddCommand(llmCheckCmd)
 
-	llmCheckCmd.Flags().StringVar(&llmBaseURL, "base-url", "http://localhost:11434", "LLM Base URL")
-	llmCheckCmd.Flags().StringVar(&llmModel, "model", "", "Model name for generation check")
-	llmCheckCmd.Flags().StringVar(&llmEmbedModel, "embed-model", "", "Model name for embedding check")
+	llmCheckCmd.Flags().StringVar(&llmBaseURL, "base-url", "", llmBaseURLUsage)
+	llmCheckCmd.Flags().StringVar(&llmModel, "model", defaultLLMModel, "Model name for generation check (checked only when set)")
+	llmCheckCmd.Flags().StringVar(&llmEmbedModel, "embed-model", defaultEmbedModel, "Model name for embedding check (checked only when set)")
 }
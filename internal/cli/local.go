@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/steamlocal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	localScanOutput   string
+	localImportOutput string
+)
+
+var localCmd = &cobra.Command{
+	Use:   "local",
+	Short: "Read the local Steam client installation",
+}
+
+var localScanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Record which games are installed in the local Steam libraries",
+	Long: `scan reads libraryfolders.vdf and the appmanifest_*.acf files of every
+Steam library and stores the fully installed apps with their install
+directory, size on disk and last update. Each scan replaces the previous one.
+
+Use --installed-only with list and pick afterwards.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		root := steamRoot()
+		scan, err := steamlocal.ScanInstalled(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, p := range scan.Problems {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", p)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		if err := database.ReplaceInstalledGames(scan.Games, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving installed games: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Installs are stored for every app; only owned ones are shown.
+		owned, err := ownedInstalled(database, scan.Games)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if localScanOutput == "json" {
+			if owned == nil {
+				owned = []model.InstalledGame{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(owned); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		var total int64
+		for _, g := range owned {
+			total += g.SizeOnDisk
+		}
+		fmt.Printf("Scanned %d libraries under %s: %d installed apps, %d owned games (%s).\n",
+			len(scan.Libraries), root, len(scan.Games), len(owned), formatSize(total))
+		if len(owned) == 0 {
+			return
+		}
+		fmt.Println()
+		fmt.Printf("%-8s %-40s %10s %-16s %s\n", "AppID", "Name", "Size", "Updated", "Path")
+		for _, g := range owned {
+			updated := ""
+			if !g.LastUpdated.IsZero() {
+				updated = g.LastUpdated.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%-8d %-40s %10s %-16s %s\n", g.AppID, g.Name, formatSize(g.SizeOnDisk), updated, g.InstallDir)
+		}
+	},
+}
+
+var localImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the library from the local Steam client, without the Web API",
+	Long: `import reads per-app playtime and last-played times from the account's
+userdata/<id>/config/localconfig.vdf and the app manifests the account
+installed, and stores them as the account's library. It also refreshes the
+installed games like 'local scan'. The next 'sync' removes imported games
+the Web API does not return, such as family-shared ones.
+
+The account is the configured one, or else the one last signed in to the
+client. Stored names and higher playtimes from an earlier sync are kept.
+Afterwards 'list --offline', 'pick --offline' and 'profile' work without an
+API key or network.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		root := steamRoot()
+		steamID, err := localSteamID(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		lib, err := steamlocal.ImportLibrary(root, steamID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, p := range lib.Scan.Problems {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", p)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		res, err := importLocalLibrary(database, steamID, lib.Games)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error importing games: %v\n", err)
+			os.Exit(1)
+		}
+		if err := database.ReplaceInstalledGames(lib.Scan.Games, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving installed games: %v\n", err)
+			os.Exit(1)
+		}
+
+		if localImportOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(res); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		fmt.Printf("Imported %d games for %s from %s: %d new, %d updated, %d unchanged; %d installed.\n",
+			len(lib.Games), steamID, lib.ConfigPath, res.New, res.Updated, res.Unchanged, len(lib.Scan.Games))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(localCmd)
+	localCmd.AddCommand(localScanCmd)
+	localCmd.AddCommand(localImportCmd)
+
+	localScanCmd.Flags().StringVar(&localScanOutput, "output", "table", "Output format 'table' or 'json'")
+	localImportCmd.Flags().StringVar(&localImportOutput, "output", "table", "Output format 'table' or 'json'")
+}
+
+// steamRoot returns the configured Steam installation directory.
+func steamRoot() string {
+	if root := viper.GetString("steam_root"); root != "" {
+		return root
+	}
+	return steamlocal.DefaultRoot()
+}
+
+// localSteamID returns the configured account, or else the one last signed
+// in to the Steam client at root, which is then remembered.
+func localSteamID(root string) (string, error) {
+	steamID, err := getSteamID(context.Background(), nil, "", "")
+	if err == nil {
+		return steamID, nil
+	}
+	steamID, lerr := steamlocal.MostRecentUser(root)
+	if lerr != nil {
+		return "", err
+	}
+	_ = saveUserCache(steamID, "")
+	return steamID, nil
+}
+
+// ownedInstalled keeps the installed games the configured account owns.
+// Without a configured account every installed game is kept.
+func ownedInstalled(database *db.DB, installed []model.InstalledGame) ([]model.InstalledGame, error) {
+	steamID, err := getSteamID(context.Background(), nil, "", "")
+	if err != nil {
+		return installed, nil
+	}
+	games, err := database.GetOwnedGames(steamID)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[int]bool, len(games))
+	for _, g := range games {
+		owned[g.AppID] = true
+	}
+	var result []model.InstalledGame
+	for _, g := range installed {
+		if owned[g.AppID] {
+			result = append(result, g)
+		}
+	}
+	return result, nil
+}
+
+// importLocalLibrary merges games read from the local client into the
+// stored library. Stored names win over manifest names, and playtime and
+// last-played only move forward, since other machines may have synced more.
+// Games the client cannot name are called "App <appid>" until a sync. New
+// games are marked local-only, so a sync tombstones those the account
+// does not own.
+func importLocalLibrary(database *db.DB, steamID string, local []model.Game) (*model.SyncResult, error) {
+	stored, err := database.GetOwnedGames(steamID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]model.Game, len(stored))
+	for _, g := range stored {
+		byID[g.AppID] = g
+	}
+
+	res := &model.SyncResult{Removed: []model.Game{}}
+	var changed []model.Game
+	var added []int
+	for _, g := range local {
+		old, ok := byID[g.AppID]
+		if !ok {
+			if g.Name == "" {
+				g.Name = fmt.Sprintf("App %d", g.AppID)
+			}
+			changed = append(changed, g)
+			added = append(added, g.AppID)
+			res.New++
+			continue
+		}
+		merged := old
+		merged.PlaytimeForever = max(old.PlaytimeForever, g.PlaytimeForever)
+		merged.RTimeLastPlayed = max(old.RTimeLastPlayed, g.RTimeLastPlayed)
+		if g.Name != "" && old.Name == fmt.Sprintf("App %d", g.AppID) {
+			merged.Name = g.Name
+		}
+		if merged == old {
+			res.Unchanged++
+			continue
+		}
+		changed = append(changed, merged)
+		res.Updated++
+	}
+	if err := database.UpsertGames(steamID, changed); err != nil {
+		return nil, err
+	}
+	if err := database.MarkLocalOnly(steamID, added); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// filterInstalled keeps the games found by the last 'local scan'.
+func filterInstalled(database *db.DB, games []model.Game) ([]model.Game, error) {
+	installed, err := database.GetInstalledApps()
+	if err != nil {
+		return nil, err
+	}
+	var result []model.Game
+	for _, g := range games {
+		if installed[g.AppID] {
+			result = append(result, g)
+		}
+	}
+	return result, nil
+}
+
+// formatSize formats a byte count with a binary unit, e.g. "12.3 GiB".
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
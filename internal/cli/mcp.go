@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/dajoen/steam-pick/internal/mcp"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/server"
+	"github.com/dajoen/steam-pick/internal/tools"
+	"github.com/dajoen/steam-pick/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve the library to AI assistants over the Model Context Protocol",
+	Long: `mcp speaks the Model Context Protocol on stdin and stdout so editor
+assistants can list, pick and recommend games from the local database.
+It never syncs; run 'sync' and 'enrich' to keep the database current.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		svc := &libraryService{
+			db:         database,
+			steamID:    steamID,
+			llmBaseURL: llmBaseURL,
+			llmModel:   llmModel,
+			embedModel: llmEmbedModel,
+		}
+		server := &mcp.Server{Name: "steam-pick", Version: version.Version, Tools: mcpTools(svc)}
+		if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+	addLLMFlags(mcpCmd, true, true)
+}
+
+// mcpTools returns the library tools plus the list, pick, profile and
+// recommend commands as tools. Everything reads the local database only.
+func mcpTools(svc *libraryService) *tools.Registry {
+	r := tools.NewLibrary(svc.db, svc.steamID)
+
+	r.Register(tools.Tool{
+		Name:        "list_unplayed",
+		Description: "List unplayed games, like 'steam-pick list'.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"where": {"type": "string", "description": "Filter expression, e.g. 'genre:RPG and platform:linux'"},
+				"include_non_games": {"type": "boolean", "description": "Include DLC, soundtracks, tools and demos"},
+				"limit": {"type": "integer", "description": "Maximum number of games (default 50)"}
+			}
+		}`),
+		ReadOnly: true,
+		Run: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				Where           string `json:"where"`
+				IncludeNonGames bool   `json:"include_non_games"`
+				Limit           int    `json:"limit"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments: %w", err)
+			}
+			if args.Limit <= 0 {
+				args.Limit = 50
+			}
+			games, err := svc.Games(ctx, server.GamesQuery{
+				UnplayedOnly:    true,
+				IncludeNonGames: args.IncludeNonGames,
+				Where:           args.Where,
+				Limit:           args.Limit,
+			})
+			if games == nil && err == nil {
+				games = []model.Game{}
+			}
+			return games, err
+		},
+	})
+
+	r.Register(tools.Tool{
+		Name:        "pick_game",
+		Description: "Pick a random unplayed game to play, like 'steam-pick pick'. The pick is recorded in the pick history.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"turn_based_only": {"type": "boolean", "description": "Only pick turn-based games (uses enriched data)"},
+				"where": {"type": "string", "description": "Filter expression, e.g. 'playtime < 60 and genre:Strategy'"},
+				"exclude_recent": {"type": "string", "description": "Skip games picked within this duration, e.g. 7d"},
+				"include_non_games": {"type": "boolean", "description": "Include DLC, soundtracks, tools and demos"},
+				"seed": {"type": "integer", "description": "Random seed for a reproducible pick"}
+			}
+		}`),
+		Run: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var req server.PickRequest
+			if err := json.Unmarshal(raw, &req); err != nil {
+				return nil, fmt.Errorf("invalid arguments: %w", err)
+			}
+			req.Record = true
+			req.Source = "mcp"
+			return svc.Pick(ctx, req)
+		},
+	})
+
+	r.Register(tools.Tool{
+		Name:        "get_game_details",
+		Description: "Get a game's playtime, status and stored Steam Store details (type, genres, platforms, price, Metacritic score and more).",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"required": ["appid"],
+			"properties": {"appid": {"type": "integer", "description": "Steam AppID"}}
+		}`),
+		ReadOnly: true,
+		Run: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			var args struct {
+				AppID int `json:"appid"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments: %w", err)
+			}
+			return svc.Game(ctx, args.AppID)
+		},
+	})
+
+	r.Register(tools.Tool{
+		Name:        "get_taste_profile",
+		Description: "Get the genres the player likes most, with weights, as computed by 'steam-pick profile'.",
+		Parameters:  json.RawMessage(`{"type": "object", "properties": {}}`),
+		ReadOnly:    true,
+		Run: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			return svc.Profile(ctx)
+		},
+	})
+
+	r.Register(tools.Tool{
+		Name:        "recommend",
+		Description: "Recommend games from the backlog that match the taste profile, like 'steam-pick recommend'. The run is stored in the recommendation history.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"top": {"type": "integer", "description": "Number of recommendations (default 5)"},
+				"mode": {"type": "string", "enum": ["backlog", "discovery"]},
+				"strategy": {"type": "string", "enum": ["genre", "semantic", "llm-rerank"]},
+				"explain": {"type": "boolean", "description": "Ask the LLM for a reason per game"},
+				"where": {"type": "string", "description": "Filter expression"}
+			}
+		}`),
+		Run: func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+			args := struct {
+				Top      int    `json:"top"`
+				Mode     string `json:"mode"`
+				Strategy string `json:"strategy"`
+				Explain  bool   `json:"explain"`
+				Where    string `json:"where"`
+			}{Top: 5, Mode: "backlog", Strategy: "genre"}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments: %w", err)
+			}
+			recommendations, err := svc.Recommendations(ctx, server.RecommendRequest{
+				Top:      args.Top,
+				Mode:     args.Mode,
+				Strategy: args.Strategy,
+				Where:    args.Where,
+				Explain:  args.Explain,
+				Record:   true,
+			})
+			if recommendations == nil && err == nil {
+				recommendations = []model.Recommendation{}
+			}
+			return recommendations, err
+		},
+	})
+
+	return r
+}
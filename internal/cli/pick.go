@@ -3,15 +3,20 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/launcher"
 	"github.com/dajoen/steam-pick/internal/logic"
 	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/tui"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"github.com/spf13/pflag"
 )
 
 var pickCmd = &cobra.Command{
@@ -34,71 +39,378 @@ func init() {
 	pickCmd.Flags().Bool("json", false, "Output JSON")
 	pickCmd.Flags().Duration("cache-ttl", 24*time.Hour, "Cache TTL")
 	pickCmd.Flags().Duration("timeout", 15*time.Second, "HTTP Timeout")
+	pickCmd.Flags().Duration("sync-interval", 24*time.Hour, "Time before forcing a sync")
+	pickCmd.Flags().Bool("offline", false, "Only use the local database, never the network")
+	pickCmd.Flags().String("where", "", whereUsage)
+	pickCmd.Flags().Bool("include-non-games", false, "Include DLC, soundtracks, tools and demos")
+	pickCmd.Flags().String("exclude-recent", "", "Skip games picked within this duration (e.g. 72h, 7d, 2w)")
+	pickCmd.Flags().Bool("installed-only", false, "Only pick games found by 'local scan'")
+	pickCmd.Flags().String("tag", "", "Only pick games with this tag")
+	pickCmd.Flags().Bool("interactive", false, "Pick in a terminal UI with reroll, accept, skip, snooze and live filters")
+	pickCmd.Flags().Bool("launch", false, "Launch the picked game in Steam and mark it as playing (on accept with --interactive)")
+
+	pickCmd.AddCommand(pickSnoozeCmd)
+	pickCmd.AddCommand(pickRejectCmd)
 }
 
-func runPick(cmd *cobra.Command, args []string) {
-	apiKey, err := getAPIKey()
+var pickSnoozeCmd = &cobra.Command{
+	Use:   "snooze <appid> <duration>",
+	Short: "Keep a game out of picks for a while (e.g. 30d)",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid appid %q\n", args[0])
+			os.Exit(1)
+		}
+		d, err := parseDuration(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		database, steamID := openPickDB()
+		defer func() { _ = database.Close() }()
+
+		until := time.Now().Add(d)
+		if err := database.SnoozeGame(steamID, appID, until); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Snoozed %d until %s.\n", appID, until.Format("2006-01-02 15:04"))
+	},
+}
+
+var pickRejectCmd = &cobra.Command{
+	Use:   "reject <appid>",
+	Short: "Never pick a game again",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid appid %q\n", args[0])
+			os.Exit(1)
+		}
+
+		database, steamID := openPickDB()
+		defer func() { _ = database.Close() }()
+
+		if err := database.RejectGame(steamID, appID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d will no longer be picked.\n", appID)
+	},
+}
+
+func openPickDB() (*db.DB, string) {
+	database, err := openDB()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	steamID, err := getSteamID(context.Background(), nil, "", "")
+	if err != nil {
+		_ = database.Close()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return database, steamID
+}
 
-	steamID := viper.GetString("steamid64")
-	vanity := viper.GetString("vanity")
+// pickExclusions returns the games pick must skip: rejected, snoozed and,
+// when recent > 0, picked within that window.
+func pickExclusions(database *db.DB, steamID string, now time.Time, recent time.Duration) (map[int]bool, error) {
+	excluded, err := database.GetExcludedApps(steamID, now)
+	if err != nil {
+		return nil, err
+	}
+	if recent > 0 {
+		picks, err := database.GetPicksSince(steamID, now.Add(-recent))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range picks {
+			excluded[p.AppID] = true
+		}
+	}
+	return excluded, nil
+}
 
-	ttl, _ := cmd.Flags().GetDuration("cache-ttl")
+func runPick(cmd *cobra.Command, args []string) {
 	timeout, _ := cmd.Flags().GetDuration("timeout")
-	includeFree, _ := cmd.Flags().GetBool("include-free-games")
 	seed, _ := cmd.Flags().GetInt64("seed")
 	turnBased, _ := cmd.Flags().GetBool("turn-based-only")
 	maxLookups, _ := cmd.Flags().GetInt("max-store-lookups")
 	country, _ := cmd.Flags().GetString("country-code")
 	sleep, _ := cmd.Flags().GetDuration("sleep")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
-	vanityTTL := viper.GetDuration("auth_cache_ttl")
+	offline, _ := cmd.Flags().GetBool("offline")
+	excludeRecentFlag, _ := cmd.Flags().GetString("exclude-recent")
+	where, _ := cmd.Flags().GetString("where")
+	includeNonGames, _ := cmd.Flags().GetBool("include-non-games")
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	installedOnly, _ := cmd.Flags().GetBool("installed-only")
+	tag, _ := cmd.Flags().GetString("tag")
+	launch, _ := cmd.Flags().GetBool("launch")
 
-	client, err := NewSteamClient(apiKey, ttl, vanityTTL, timeout)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+	if interactive && jsonOutput {
+		fmt.Fprintln(os.Stderr, "Error: --interactive cannot be combined with --json")
 		os.Exit(1)
 	}
 
+	var excludeRecent time.Duration
+	if excludeRecentFlag != "" {
+		d, err := parseDuration(excludeRecentFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		excludeRecent = d
+	}
+
+	// Record the seed actually used so every pick can be reproduced.
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	// History, statuses and local filters live in the database; a plain
+	// pick still works without it.
+	database, err := openDB()
+	if err != nil {
+		if flag := pickDBFlag(cmd); flag != "" {
+			fmt.Fprintf(os.Stderr, "Error: --%s needs the local database: %v\n", flag, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: picking without history, the database is unavailable: %v\n", err)
+		database = nil
+	} else {
+		defer func() { _ = database.Close() }()
+	}
+
 	ctx := context.Background()
 
-	steamID, err = getSteamID(ctx, client, steamID, vanity)
+	steamID, games, err := loadOwnedGames(ctx, cmd, database, offline)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	games, err := client.GetOwnedGames(ctx, steamID, includeFree)
+	if interactive {
+		// Turn-based can be switched on live, so keep a store client ready.
+		var store StoreClient
+		if !offline {
+			store = NewStoreClient(timeout)
+		}
+		picker := &tui.Picker{
+			Backend: &pickSession{
+				db:      database,
+				steamID: steamID,
+				games:   games,
+				opts: pickOptions{
+					ExcludeRecent: excludeRecent,
+					InstalledOnly: installedOnly,
+					Tag:           tag,
+					Store:         store,
+					MaxLookups:    maxLookups,
+					Country:       country,
+					Sleep:         sleep,
+				},
+				opener: NewOpener(),
+				launch: launch,
+			},
+			Filters: tui.Filters{Where: where, TurnBasedOnly: turnBased, IncludeNonGames: includeNonGames},
+			Seed:    seed,
+		}
+		if err := runInteractivePick(ctx, picker); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	now := time.Now()
+	var store StoreClient
+	if turnBased && !offline {
+		store = NewStoreClient(timeout)
+	}
+	picked, err := pickGame(ctx, database, steamID, games, pickOptions{
+		Seed:            seed,
+		IncludeNonGames: includeNonGames,
+		ExcludeRecent:   excludeRecent,
+		Where:           where,
+		TurnBased:       turnBased,
+		InstalledOnly:   installedOnly,
+		Tag:             tag,
+		Store:           store,
+		MaxLookups:      maxLookups,
+		Country:         country,
+		Sleep:           sleep,
+	}, now)
+	var empty nothingToPick
+	if errors.As(err, &empty) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(0)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error fetching games: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	unplayed := logic.FilterUnplayed(games)
+	flags := make(map[string]string)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+	if database != nil {
+		if err := database.RecordPick(steamID, picked.AppID, seed, flags, now); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record pick: %v\n", err)
+		}
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(picked); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		fmt.Printf("Name: %s\n", picked.Name)
+		fmt.Printf("AppID: %d\n", picked.AppID)
+		fmt.Printf("Store URL: %s\n", picked.StoreURL)
+		if picked.IsTurnBased {
+			fmt.Println("Turn-based: Yes")
+		}
+	}
+
+	if launch {
+		if database == nil {
+			err = launcher.Launch(NewOpener(), picked.AppID)
+		} else {
+			err = launchGame(database, NewOpener(), steamID, picked.AppID)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error launching %d: %v\n", picked.AppID, err)
+			os.Exit(1)
+		}
+		if !jsonOutput {
+			if database == nil {
+				fmt.Println("Launched.")
+			} else {
+				fmt.Println("Launched; status is now playing.")
+			}
+		}
+	}
+}
+
+// pickDBFlag returns the first set flag of pick that needs the database.
+func pickDBFlag(cmd *cobra.Command) string {
+	for _, name := range []string{"interactive", "offline", "where", "tag", "installed-only", "exclude-recent"} {
+		if cmd.Flags().Changed(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// pickOptions are the filters and turn-based lookup settings of a pick.
+type pickOptions struct {
+	Seed            int64
+	IncludeNonGames bool
+	ExcludeRecent   time.Duration
+	Where           string
+	TurnBased       bool
+	InstalledOnly   bool
+	Tag             string
+	// Store looks up games without enriched data; nil skips them.
+	Store      StoreClient
+	MaxLookups int
+	Country    string
+	Sleep      time.Duration
+}
+
+// nothingToPick is returned by pickGame when the filters leave no game.
+type nothingToPick string
+
+func (n nothingToPick) Error() string { return string(n) }
+
+// pickGame picks an unplayed game from games. It does not record the pick.
+// Without a database only the unplayed and turn-based filters apply.
+func pickGame(ctx context.Context, database *db.DB, steamID string, games []model.Game, opts pickOptions, now time.Time) (*model.Game, error) {
+	unplayed := logic.FilterUnplayed(games, opts.IncludeNonGames)
 	if len(unplayed) == 0 {
-		fmt.Fprintln(os.Stderr, "No unplayed games found.")
-		os.Exit(0)
+		return nil, nothingToPick("No unplayed games found.")
+	}
+	if database == nil {
+		return pickFrom(ctx, unplayed, nil, opts)
 	}
 
-	var picked *model.Game
+	excluded, err := pickExclusions(database, steamID, now, opts.ExcludeRecent)
+	if err != nil {
+		return nil, fmt.Errorf("loading pick history: %w", err)
+	}
+	unplayed = logic.ExcludeApps(unplayed, excluded)
+	if len(unplayed) == 0 {
+		return nil, nothingToPick("No unplayed games left after snoozed, rejected and recent picks.")
+	}
+
+	unplayed, err = filterWhere(database, steamID, unplayed, opts.Where)
+	if err != nil {
+		return nil, err
+	}
+	if len(unplayed) == 0 {
+		return nil, nothingToPick("No unplayed games match --where.")
+	}
+
+	if opts.Tag != "" {
+		unplayed, err = filterTagged(database, steamID, unplayed, opts.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("loading tags: %w", err)
+		}
+		if len(unplayed) == 0 {
+			return nil, nothingToPick(fmt.Sprintf("No unplayed games are tagged %q.", opts.Tag))
+		}
+	}
+
+	if opts.InstalledOnly {
+		unplayed, err = filterInstalled(database, unplayed)
+		if err != nil {
+			return nil, fmt.Errorf("loading installed games: %w", err)
+		}
+		if len(unplayed) == 0 {
+			return nil, nothingToPick("No unplayed games are installed (run 'steam-pick local scan' to refresh).")
+		}
+	}
+
+	var known map[int]model.GameDetails
+	if opts.TurnBased {
+		// Enriched games are checked against stored genres and categories;
+		// only the rest needs the store API.
+		stored, err := database.GetGamesWithDetails(steamID)
+		if err != nil {
+			return nil, fmt.Errorf("fetching game details: %w", err)
+		}
+		known = make(map[int]model.GameDetails, len(stored))
+		for _, g := range stored {
+			if g.HasDetails {
+				known[g.AppID] = g
+			}
+		}
+	}
+	return pickFrom(ctx, unplayed, known, opts)
+}
 
-	if turnBased {
-		storeClient := NewStoreClient(timeout)
+// pickFrom picks from filtered games, preferring turn-based ones when asked.
+// known holds the stored details of enriched games.
+func pickFrom(ctx context.Context, unplayed []model.Game, known map[int]model.GameDetails, opts pickOptions) (*model.Game, error) {
+	var picked *model.Game
 
+	if opts.TurnBased {
 		// Shuffle unplayed list to check random games
 		shuffled := make([]model.Game, len(unplayed))
 		copy(shuffled, unplayed)
 
-		var src rand.Source
-		if seed != 0 {
-			src = rand.NewSource(seed)
-		} else {
-			src = rand.NewSource(time.Now().UnixNano())
-		}
-		r := rand.New(src)
+		r := rand.New(rand.NewSource(opts.Seed))
 
 		r.Shuffle(len(shuffled), func(i, j int) {
 			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
@@ -106,19 +418,28 @@ func runPick(cmd *cobra.Command, args []string) {
 
 		lookups := 0
 		for i := range shuffled {
-			if lookups >= maxLookups {
-				break
+			if d, ok := known[shuffled[i].AppID]; ok {
+				if logic.IsTurnBased(d.GenreNames(), d.CategoryNames()) {
+					picked = &shuffled[i]
+					picked.IsTurnBased = true
+					break
+				}
+				continue
+			}
+
+			if opts.Store == nil || lookups >= opts.MaxLookups {
+				continue
 			}
 
-			isTurn, err := storeClient.IsTurnBased(ctx, shuffled[i].AppID, country)
-			if err == nil && isTurn {
+			details, err := opts.Store.GetAppDetails(ctx, shuffled[i].AppID, opts.Country)
+			if err == nil && logic.IsTurnBasedApp(*details) {
 				picked = &shuffled[i]
 				picked.IsTurnBased = true
 				break
 			}
 
 			lookups++
-			time.Sleep(sleep)
+			time.Sleep(opts.Sleep)
 		}
 
 		if picked == nil {
@@ -127,30 +448,13 @@ func runPick(cmd *cobra.Command, args []string) {
 	}
 
 	if picked == nil {
-		picked = logic.PickGame(unplayed, seed)
+		picked = logic.PickGame(unplayed, opts.Seed)
 	}
-
 	if picked == nil {
 		// Should not happen if unplayed > 0
-		fmt.Fprintln(os.Stderr, "Failed to pick a game.")
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to pick a game")
 	}
 
 	picked.StoreURL = fmt.Sprintf("https://store.steampowered.com/app/%d", picked.AppID)
-
-	if jsonOutput {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		if err := enc.Encode(picked); err != nil {
-			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-			os.Exit(1)
-		}
-	} else {
-		fmt.Printf("Name: %s\n", picked.Name)
-		fmt.Printf("AppID: %d\n", picked.AppID)
-		fmt.Printf("Store URL: %s\n", picked.StoreURL)
-		if picked.IsTurnBased {
-			fmt.Println("Turn-based: Yes")
-		}
-	}
+	return picked, nil
 }
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/launcher"
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/tui"
+)
+
+// pickSession is the interactive picker's backend. opts holds the settings
+// that stay fixed during the session; seed and filters come from the picker.
+// With launch set, accepting a game also launches it.
+type pickSession struct {
+	db      *db.DB
+	steamID string
+	games   []model.Game
+	opts    pickOptions
+	opener  launcher.Opener
+	launch  bool
+}
+
+func (p *pickSession) Pick(ctx context.Context, f tui.Filters, seed int64, skipped map[int]bool) (*model.Game, error) {
+	opts := p.opts
+	opts.Seed = seed
+	opts.Where = f.Where
+	opts.TurnBased = f.TurnBasedOnly
+	opts.IncludeNonGames = f.IncludeNonGames
+	return pickGame(ctx, p.db, p.steamID, logic.ExcludeApps(p.games, skipped), opts, time.Now())
+}
+
+func (p *pickSession) Accept(ctx context.Context, g model.Game, f tui.Filters, seed int64) error {
+	if p.launch {
+		if err := launcher.Launch(p.opener, g.AppID); err != nil {
+			return err
+		}
+	}
+	if err := setGameStatus(p.db, p.steamID, g.AppID, model.StatusPlaying, false); err != nil {
+		return err
+	}
+	flags := map[string]string{"interactive": "true"}
+	if f.TurnBasedOnly {
+		flags["turn-based-only"] = "true"
+	}
+	if f.Where != "" {
+		flags["where"] = f.Where
+	}
+	if f.IncludeNonGames {
+		flags["include-non-games"] = "true"
+	}
+	if p.opts.InstalledOnly {
+		flags["installed-only"] = "true"
+	}
+	if p.opts.Tag != "" {
+		flags["tag"] = p.opts.Tag
+	}
+	if p.opts.ExcludeRecent > 0 {
+		flags["exclude-recent"] = p.opts.ExcludeRecent.String()
+	}
+	if p.launch {
+		flags["launch"] = "true"
+	}
+	return p.db.RecordPick(p.steamID, g.AppID, seed, flags, time.Now())
+}
+
+func (p *pickSession) Snooze(ctx context.Context, g model.Game, duration string) (time.Time, error) {
+	d, err := parseDuration(duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+	until := time.Now().Add(d)
+	return until, p.db.SnoozeGame(p.steamID, g.AppID, until)
+}
+
+func (p *pickSession) Open(url string) error {
+	return p.opener.Open(url)
+}
+
+// runInteractivePick runs the picker on stdin and stdout, with single key
+// presses when stdin is a terminal.
+func runInteractivePick(ctx context.Context, picker *tui.Picker) error {
+	picker.In = os.Stdin
+	picker.Out = os.Stdout
+	if restore, err := tui.MakeRaw(int(os.Stdin.Fd())); err == nil {
+		picker.Raw = true
+		defer func() { _ = restore() }()
+	}
+	_, err := picker.Run(ctx)
+	return err
+}
@@ -1,20 +1,23 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"math"
 	"os"
 	"sort"
 	"time"
 
 	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/dajoen/steam-pick/internal/model"
 	"github.com/spf13/cobra"
 )
 
 var (
 	profileRecencyHalfLifeDays int
 	profileMinHours            int
+	profileCompletionWeight    float64
 	profileOutput              string
 )
 
@@ -22,14 +25,20 @@ var profileCmd = &cobra.Command{
 	Use:   "profile",
 	Short: "Analyze taste profile",
 	Run: func(cmd *cobra.Command, args []string) {
-		database, err := db.New("steam-pick")
+		database, err := openDB()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 		defer func() { _ = database.Close() }()
 
-		games, err := database.GetGamesWithDetails()
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		games, err := database.GetGamesWithDetails(steamID)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error fetching games: %v\n", err)
 			os.Exit(1)
@@ -37,22 +46,19 @@ var profileCmd = &cobra.Command{
 
 		genreScores := make(map[string]float64)
 
-		now := time.Now().Unix()
-		halfLifeSecs := float64(profileRecencyHalfLifeDays * 24 * 3600)
+		weighting := logic.Weighting{
+			RecencyHalfLifeDays: profileRecencyHalfLifeDays,
+			MinHours:            profileMinHours,
+			CompletionWeight:    profileCompletionWeight,
+		}
+		now := time.Now()
 
 		for _, g := range games {
-			playtimeHours := float64(g.PlaytimeForever) / 60.0
-			if playtimeHours < float64(profileMinHours) {
+			weight := weighting.Weight(g, now)
+			if weight == 0 {
 				continue
 			}
 
-			weight := playtimeHours
-			if g.RTimeLastPlayed > 0 && profileRecencyHalfLifeDays > 0 {
-				ageSecs := float64(now - int64(g.RTimeLastPlayed))
-				decay := math.Pow(0.5, ageSecs/halfLifeSecs)
-				weight *= decay
-			}
-
 			var genres []struct {
 				Description string `json:"description"`
 			}
@@ -66,13 +72,9 @@ var profileCmd = &cobra.Command{
 		}
 
 		// Sort by score
-		type kv struct {
-			Key   string
-			Value float64
-		}
-		var ss []kv
+		var ss []model.GenreScore
 		for k, v := range genreScores {
-			ss = append(ss, kv{k, v})
+			ss = append(ss, model.GenreScore{Key: k, Value: v})
 		}
 		sort.Slice(ss, func(i, j int) bool {
 			return ss[i].Value > ss[j].Value
@@ -86,7 +88,7 @@ var profileCmd = &cobra.Command{
 				os.Exit(1)
 			}
 		} else {
-			fmt.Println("Taste Profile (Weighted by Playtime & Recency):")
+			fmt.Println("Taste Profile (Weighted by Playtime, Completion & Recency):")
 			fmt.Printf("%-30s %s\n", "Genre", "Score")
 			fmt.Println("---------------------------------------------")
 			for _, kv := range ss {
@@ -95,15 +97,35 @@ var profileCmd = &cobra.Command{
 		}
 
 		profileJSON, _ := json.Marshal(ss)
-		if err := database.UpsertTasteProfile("genres", string(profileJSON)); err != nil {
+		if err := database.UpsertTasteProfile(steamID, "genres", string(profileJSON)); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Failed to save profile: %v\n", err)
 		}
+		// Semantic recommendations weigh played games the same way.
+		weightingJSON, _ := json.Marshal(weighting)
+		if err := database.UpsertTasteProfile(steamID, "weighting", string(weightingJSON)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to save profile weighting: %v\n", err)
+		}
 	},
 }
 
+// loadTasteProfile returns the stored genre profile, best first, and its
+// JSON form.
+func loadTasteProfile(database *db.DB, steamID string) ([]model.GenreScore, string, error) {
+	profileJSON, err := database.GetTasteProfile(steamID, "genres")
+	if err != nil {
+		return nil, "", err
+	}
+	var profile []model.GenreScore
+	if err := json.Unmarshal([]byte(profileJSON), &profile); err != nil {
+		return nil, profileJSON, err
+	}
+	return profile, profileJSON, nil
+}
+
 func init() {
 	rootCmd.AddCommand(profileCmd)
-	profileCmd.Flags().IntVar(&profileRecencyHalfLifeDays, "recency-half-life-days", 365, "Half-life in days for recency decay")
-	profileCmd.Flags().IntVar(&profileMinHours, "min-hours", 2, "Minimum playtime in hours to consider")
+	profileCmd.Flags().IntVar(&profileRecencyHalfLifeDays, "recency-half-life-days", logic.DefaultWeighting.RecencyHalfLifeDays, "Half-life in days for recency decay")
+	profileCmd.Flags().IntVar(&profileMinHours, "min-hours", logic.DefaultWeighting.MinHours, "Minimum playtime in hours to consider")
+	profileCmd.Flags().Float64Var(&profileCompletionWeight, "completion-weight", logic.DefaultWeighting.CompletionWeight, "Extra weight for fully completed games (0 disables)")
 	profileCmd.Flags().StringVar(&profileOutput, "output", "table", "Output format 'table' or 'json'")
 }
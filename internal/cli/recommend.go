@@ -2,14 +2,20 @@ package cli
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dajoen/steam-pick/internal/db"
 	"github.com/dajoen/steam-pick/internal/llm"
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/query"
 	"github.com/spf13/cobra"
 )
 
@@ -18,157 +24,315 @@ var (
 	recommendTop     int
 	recommendExplain bool
 	recommendOutput  string
+	recommendWhere   string
+
+	recommendStrategy         string
+	recommendRerankCandidates int
 )
 
+// semanticLikedGames is how many of the most played games the semantic
+// strategy compares candidates with.
+const semanticLikedGames = 20
+
 var recommendCmd = &cobra.Command{
 	Use:   "recommend",
 	Short: "Recommend games based on taste profile",
 	Run: func(cmd *cobra.Command, args []string) {
-		database, err := db.New("steam-pick")
+		database, err := openDB()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 		defer func() { _ = database.Close() }()
 
-		// Load profile
-		profileJSON, err := database.GetTasteProfile("genres")
+		steamID, err := getSteamID(context.Background(), nil, "", "")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading profile (run 'profile' first): %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		type GenreScore struct {
-			Key   string
-			Value float64
-		}
-		var profile []GenreScore
-		if err := json.Unmarshal([]byte(profileJSON), &profile); err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing profile: %v\n", err)
+		recommendations, err := recommendGames(context.Background(), database, steamID, recommendOptions{
+			Mode:             recommendMode,
+			Strategy:         recommendStrategy,
+			Where:            recommendWhere,
+			Top:              recommendTop,
+			Explain:          recommendExplain,
+			RerankCandidates: recommendRerankCandidates,
+			EmbedModel:       llmEmbedModel,
+			LLMBaseURL:       llmBaseURL,
+			LLMModel:         llmModel,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		profileMap := make(map[string]float64)
-		for _, p := range profile {
-			profileMap[p.Key] = p.Value
+		if recommendOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(recommendations); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			fmt.Println("Recommendations:")
+			fmt.Printf("%-10s %-40s %s\n", "AppID", "Name", "Score")
+			fmt.Println("------------------------------------------------------------")
+			for _, r := range recommendations {
+				fmt.Printf("%-10d %-40s %.2f\n", r.AppID, r.Name, r.Score)
+				if r.Explanation != "" {
+					fmt.Printf("  Explanation: %s\n", r.Explanation)
+				}
+			}
 		}
+	},
+}
+
+// recommendOptions mirror the recommend flags.
+type recommendOptions struct {
+	Mode             string
+	Strategy         string
+	Where            string
+	Top              int
+	Explain          bool
+	RerankCandidates int
+	EmbedModel       string
+	LLMBaseURL       string
+	LLMModel         string
+	// ReadOnly skips storing the run and caching explanations.
+	ReadOnly bool
+}
+
+func (o recommendOptions) validate() error {
+	if o.Strategy != "genre" && o.Strategy != "semantic" && o.Strategy != "llm-rerank" {
+		return fmt.Errorf("unknown strategy %q (use genre, semantic or llm-rerank)", o.Strategy)
+	}
+	if o.Top < 0 {
+		return fmt.Errorf("--top must be >= 0")
+	}
+	_, err := query.Parse(o.Where)
+	return err
+}
+
+// recommendGames scores the account's games, optionally re-ranks and
+// explains the best ones with the LLM, and stores the run.
+func recommendGames(ctx context.Context, database *db.DB, steamID string, opts recommendOptions) ([]model.Recommendation, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	// Load profile; only the genre strategy needs it to score.
+	profile, profileJSON, err := loadTasteProfile(database, steamID)
+	if err != nil && opts.Strategy != "semantic" {
+		return nil, fmt.Errorf("loading profile (run 'profile' first): %w", err)
+	}
+
+	// The profile version ties stored runs and cached explanations to
+	// the taste profile they were made with.
+	weightingJSON, _ := database.GetTasteProfile(steamID, "weighting")
+	profileVersion := versionHash(profileJSON, weightingJSON)
+
+	profileMap := make(map[string]float64)
+	for _, p := range profile {
+		profileMap[p.Key] = p.Value
+	}
+
+	where, err := query.Parse(opts.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load candidates
+	games, err := database.GetGamesWithDetails(steamID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching games: %w", err)
+	}
+
+	var recommendations []model.Recommendation
+	genresByID := make(map[int][]string)
 
-		// Load candidates
-		games, err := database.GetGamesWithDetails()
+	var embeddings map[int][]float32
+	var liked []logic.WeightedVector
+	likedIDs := make(map[int]bool)
+	if opts.Strategy == "semantic" {
+		embeddings, liked, err = semanticProfile(database, steamID, games, opts.EmbedModel)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error fetching games: %v\n", err)
-			os.Exit(1)
+			return nil, err
 		}
-
-		type Recommendation struct {
-			AppID       int     `json:"appid"`
-			Name        string  `json:"name"`
-			Score       float64 `json:"score"`
-			Explanation string  `json:"explanation,omitempty"`
+		for _, l := range liked {
+			likedIDs[l.AppID] = true
 		}
+	}
 
-		var recommendations []Recommendation
-
-		for _, g := range games {
-			// Backlog mode: playtime < 2 hours (120 mins)
-			if recommendMode == "backlog" && g.PlaytimeForever > 120 {
+	for _, g := range games {
+		if !where(g) {
+			continue
+		}
+		if opts.Mode == "backlog" {
+			// An explicit status decides; otherwise playtime < 2 hours
+			// (120 mins) and not every achievement unlocked.
+			if g.Status != "" {
+				if g.Status != model.StatusBacklog {
+					continue
+				}
+			} else if g.PlaytimeForever > 120 || (g.AchievementsTotal > 0 && g.Completion() >= 1) {
 				continue
 			}
+		}
 
-			var genres []struct {
-				Description string `json:"description"`
+		score := 0.0
+		if opts.Strategy == "semantic" {
+			v, ok := embeddings[g.AppID]
+			if !ok || likedIDs[g.AppID] {
+				continue
 			}
-			_ = json.Unmarshal([]byte(g.Genres), &genres)
-
-			score := 0.0
-			for _, genre := range genres {
-				if s, ok := profileMap[genre.Description]; ok {
+			score = logic.SemanticScore(v, liked)
+		} else {
+			for _, genre := range g.GenreNames() {
+				if s, ok := profileMap[genre]; ok {
 					score += s
 				}
 			}
+		}
 
-			if score > 0 {
-				recommendations = append(recommendations, Recommendation{
-					AppID: g.AppID,
-					Name:  g.Name,
-					Score: score,
-				})
-			}
+		if score > 0 {
+			genresByID[g.AppID] = g.GenreNames()
+			recommendations = append(recommendations, model.Recommendation{
+				AppID: g.AppID,
+				Name:  g.Name,
+				Score: score,
+			})
 		}
+	}
 
-		// Sort
-		sort.Slice(recommendations, func(i, j int) bool {
-			return recommendations[i].Score > recommendations[j].Score
-		})
+	// Sort
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
 
-		// Top N
-		if recommendTop < 0 {
-			fmt.Fprintln(os.Stderr, "Error: --top must be >= 0")
-			os.Exit(1)
+	// Get top 5 genres from profile for context
+	var topGenres []string
+	for i := 0; i < 5 && i < len(profile); i++ {
+		topGenres = append(topGenres, profile[i].Key)
+	}
+
+	// Let the model reorder the best genre-scored candidates; the
+	// heuristic order stands when its answer is unusable.
+	if opts.Strategy == "llm-rerank" && len(recommendations) > 0 {
+		n := opts.RerankCandidates
+		if n < opts.Top {
+			n = opts.Top
 		}
-		if len(recommendations) > recommendTop {
-			recommendations = recommendations[:recommendTop]
+		if n > len(recommendations) {
+			n = len(recommendations)
 		}
+		head := recommendations[:n]
 
-		// Explain
-		if recommendExplain {
-			cfg := llm.Config{
-				BaseURL:    llmBaseURL,
-				Model:      llmModel,
-				EmbedModel: llmEmbedModel,
-			}
-			if cfg.BaseURL == "" {
-				cfg.BaseURL = "http://localhost:11434"
+		candidates := make([]llm.RankCandidate, len(head))
+		byID := make(map[int]model.Recommendation, len(head))
+		for i, r := range head {
+			desc, _ := database.GetAppDescription(r.AppID)
+			candidates[i] = llm.RankCandidate{AppID: r.AppID, Name: r.Name, Genres: genresByID[r.AppID], Description: desc}
+			byID[r.AppID] = r
+		}
+
+		client, err := newLLMClient(opts.LLMBaseURL, opts.LLMModel, "")
+		if err != nil {
+			return nil, err
+		}
+		ranked, err := rerankWithLLM(ctx, client, topGenres, candidates)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: LLM ranking unusable (%v), keeping heuristic order.\n", err)
+		} else {
+			reordered := make([]model.Recommendation, 0, len(recommendations))
+			for _, rg := range ranked {
+				r := byID[rg.AppID]
+				r.Explanation = rg.Reason
+				reordered = append(reordered, r)
 			}
+			recommendations = append(reordered, recommendations[n:]...)
+		}
+	}
 
-			client := llm.NewOllamaClient(cfg)
+	// Top N
+	if len(recommendations) > opts.Top {
+		recommendations = recommendations[:opts.Top]
+	}
 
-			// Get top 5 genres from profile for context
-			var topGenres []string
-			for i := 0; i < 5 && i < len(profile); i++ {
-				topGenres = append(topGenres, profile[i].Key)
-			}
+	// Explain
+	if opts.Explain {
+		client, err := newLLMClient(opts.LLMBaseURL, opts.LLMModel, "")
+		if err != nil {
+			return nil, err
+		}
 
-			for i := range recommendations {
-				rec := &recommendations[i]
-				desc, _ := database.GetAppDescription(rec.AppID)
-
-				prompt := fmt.Sprintf(
-					"I like %s. Why should I play %s? It is described as: %s. Keep it short.",
-					strings.Join(topGenres, ", "),
-					rec.Name,
-					desc,
-				)
-
-				fmt.Printf("Generating explanation for %s...\n", rec.Name)
-				expl, err := client.Generate(context.Background(), prompt)
-				if err == nil {
-					rec.Explanation = strings.TrimSpace(expl)
-				} else {
-					fmt.Fprintf(os.Stderr, "LLM error: %v\n", err)
-				}
+		for i := range recommendations {
+			rec := &recommendations[i]
+			if rec.Explanation != "" {
+				continue
 			}
-		}
+			desc, _ := database.GetAppDescription(rec.AppID)
 
-		if recommendOutput == "json" {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			if err := enc.Encode(recommendations); err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-				os.Exit(1)
+			key := db.ExplanationKey{
+				AppID:          rec.AppID,
+				Model:          opts.LLMModel,
+				ProfileVersion: profileVersion,
+				GameVersion:    versionHash(rec.Name, desc),
 			}
-		} else {
-			fmt.Println("Recommendations:")
-			fmt.Printf("%-10s %-40s %s\n", "AppID", "Name", "Score")
-			fmt.Println("------------------------------------------------------------")
-			for _, r := range recommendations {
-				fmt.Printf("%-10d %-40s %.2f\n", r.AppID, r.Name, r.Score)
-				if r.Explanation != "" {
-					fmt.Printf("  Explanation: %s\n", r.Explanation)
+			if cached, ok, err := database.GetCachedExplanation(steamID, key); err == nil && ok {
+				rec.Explanation = cached
+				continue
+			}
+
+			prompt := fmt.Sprintf(
+				"I like %s. Why should I play %s? It is described as: %s. Keep it short.",
+				strings.Join(topGenres, ", "),
+				rec.Name,
+				desc,
+			)
+
+			fmt.Fprintf(os.Stderr, "Generating explanation for %s...\n", rec.Name)
+			expl, err := client.Generate(ctx, prompt)
+			if err == nil {
+				rec.Explanation = strings.TrimSpace(expl)
+				if opts.ReadOnly {
+					continue
 				}
+				if err := database.CacheExplanation(steamID, key, rec.Explanation); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to cache explanation: %v\n", err)
+				}
+			} else {
+				fmt.Fprintf(os.Stderr, "LLM error: %v\n", err)
 			}
 		}
-	},
+	}
+
+	if opts.ReadOnly {
+		return recommendations, nil
+	}
+
+	run := model.RecommendationRun{
+		CreatedAt:      time.Now(),
+		Strategy:       opts.Strategy,
+		Mode:           opts.Mode,
+		ProfileVersion: profileVersion,
+	}
+	if opts.Explain || opts.Strategy == "llm-rerank" {
+		run.Model = opts.LLMModel
+	}
+	for _, r := range recommendations {
+		run.Results = append(run.Results, model.RecommendationResult{
+			AppID:  r.AppID,
+			Name:   r.Name,
+			Score:  r.Score,
+			Reason: r.Explanation,
+		})
+	}
+	if _, err := database.SaveRecommendationRun(steamID, run); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save recommendations: %v\n", err)
+	}
+
+	return recommendations, nil
 }
 
 func init() {
@@ -177,7 +341,61 @@ func init() {
 	recommendCmd.Flags().IntVar(&recommendTop, "top", 10, "Number of recommendations")
 	recommendCmd.Flags().BoolVar(&recommendExplain, "explain", false, "Explain recommendations using LLM")
 	recommendCmd.Flags().StringVar(&recommendOutput, "output", "table", "Output format 'table' or 'json'")
+	recommendCmd.Flags().StringVar(&recommendWhere, "where", "", whereUsage)
+	recommendCmd.Flags().StringVar(&recommendStrategy, "strategy", "genre", "Strategy: 'genre' (taste profile), 'semantic' (embeddings) or 'llm-rerank' (LLM orders the top genre matches)")
+	recommendCmd.Flags().IntVar(&recommendRerankCandidates, "rerank-candidates", 20, "Genre-scored candidates sent to the LLM by llm-rerank")
+	addLLMFlags(recommendCmd, true, true)
+}
+
+// semanticProfile loads the embeddings for model and the most played games
+// that have one, weighted the way profile weighs them.
+func semanticProfile(database *db.DB, steamID string, games []model.GameDetails, embedModel string) (map[int][]float32, []logic.WeightedVector, error) {
+	embeddings, err := database.GetEmbeddings(embedModel)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, nil, fmt.Errorf("no embeddings for model %s (run 'steam-pick embed' first)", embedModel)
+	}
+
+	weighting := logic.DefaultWeighting
+	if w, err := database.GetTasteProfile(steamID, "weighting"); err == nil {
+		_ = json.Unmarshal([]byte(w), &weighting)
+	}
+
+	now := time.Now()
+	var liked []logic.WeightedVector
+	for _, g := range games {
+		v, ok := embeddings[g.AppID]
+		if !ok {
+			continue
+		}
+		if w := weighting.Weight(g, now); w > 0 {
+			liked = append(liked, logic.WeightedVector{AppID: g.AppID, Weight: w, Vector: v})
+		}
+	}
+	if len(liked) == 0 {
+		return nil, nil, fmt.Errorf("no played games with embeddings to compare against")
+	}
+	return embeddings, logic.TopWeighted(liked, semanticLikedGames), nil
+}
+
+// rerankWithLLM asks the model to rank candidates and returns its ranking
+// after validation and repair.
+func rerankWithLLM(ctx context.Context, client llm.Client, topGenres []string, candidates []llm.RankCandidate) ([]llm.RankedGame, error) {
+	out, err := client.Generate(ctx, llm.RankingPrompt(topGenres, candidates))
+	if err != nil {
+		return nil, err
+	}
+	return llm.ParseRanking(out, candidates)
+}
 
-	recommendCmd.Flags().StringVar(&llmBaseURL, "llm-base-url", "http://localhost:11434", "LLM Base URL")
-	recommendCmd.Flags().StringVar(&llmModel, "llm-model", "llama3", "LLM Model")
+// versionHash returns a short hash identifying the given inputs.
+func versionHash(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
 }
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recommendHistoryLimit  int
+	recommendHistoryOutput string
+)
+
+var recommendHistoryCmd = &cobra.Command{
+	Use:   "history [run-id]",
+	Short: "Browse past recommend runs",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(args) == 0 {
+			runs, err := database.GetRecommendationRuns(steamID, recommendHistoryLimit)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error fetching runs: %v\n", err)
+				os.Exit(1)
+			}
+			if recommendHistoryOutput == "json" {
+				if runs == nil {
+					runs = []model.RecommendationRun{}
+				}
+				encodeJSON(runs)
+				return
+			}
+			if len(runs) == 0 {
+				fmt.Println("No recommend runs stored yet.")
+				return
+			}
+			fmt.Printf("%-6s %-17s %-12s %-10s %s\n", "Run", "Date", "Strategy", "Mode", "Model")
+			fmt.Println("------------------------------------------------------------")
+			for _, r := range runs {
+				fmt.Printf("%-6d %-17s %-12s %-10s %s\n", r.ID, r.CreatedAt.Local().Format("2006-01-02 15:04"), r.Strategy, r.Mode, r.Model)
+			}
+			return
+		}
+
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid run id %q\n", args[0])
+			os.Exit(1)
+		}
+		run, err := database.GetRecommendationRun(steamID, id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching run: %v\n", err)
+			os.Exit(1)
+		}
+		if run == nil {
+			fmt.Fprintf(os.Stderr, "Error: no recommend run %d\n", id)
+			os.Exit(1)
+		}
+
+		if recommendHistoryOutput == "json" {
+			encodeJSON(run)
+			return
+		}
+		fmt.Printf("Run %d (%s, %s strategy, %s mode)\n", run.ID, run.CreatedAt.Local().Format("2006-01-02 15:04"), run.Strategy, run.Mode)
+		fmt.Printf("%-10s %-40s %s\n", "AppID", "Name", "Score")
+		fmt.Println("------------------------------------------------------------")
+		for _, r := range run.Results {
+			fmt.Printf("%-10d %-40s %.2f\n", r.AppID, r.Name, r.Score)
+			if r.Reason != "" {
+				fmt.Printf("  Explanation: %s\n", r.Reason)
+			}
+		}
+	},
+}
+
+func encodeJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	recommendCmd.AddCommand(recommendHistoryCmd)
+	recommendHistoryCmd.Flags().IntVar(&recommendHistoryLimit, "limit", 20, "Number of runs to list")
+	recommendHistoryCmd.Flags().StringVar(&recommendHistoryOutput, "output", "table", "Output format 'table' or 'json'")
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/dajoen/steam-pick/internal/cache"
+	"github.com/dajoen/steam-pick/internal/db"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -41,11 +42,19 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&gopassPath, "gopass-path", "", "Gopass path to Steam API Key (e.g. steam/api-key)")
 	rootCmd.PersistentFlags().Duration("auth-cache-ttl", 30*time.Minute, "Cache TTL for Vanity URL and API Key")
 	rootCmd.PersistentFlags().String("gpg-key", "", "GPG Key ID for cache encryption")
+	rootCmd.PersistentFlags().String("account", "", "Account to use: a SteamID64 or an alias from the 'accounts' config map")
+	rootCmd.PersistentFlags().String("llm-provider", "ollama", "LLM provider: 'ollama' or 'openai' (OpenAI-compatible servers such as llama.cpp and vLLM)")
+	rootCmd.PersistentFlags().String("llm-api-key", "", "Bearer token for the LLM provider")
+	rootCmd.PersistentFlags().String("steam-root", "", "Steam client installation directory (default: the platform's usual location)")
 
 	_ = viper.BindPFlag("api_key", rootCmd.PersistentFlags().Lookup("api-key"))
 	_ = viper.BindPFlag("gopass_path", rootCmd.PersistentFlags().Lookup("gopass-path"))
 	_ = viper.BindPFlag("auth_cache_ttl", rootCmd.PersistentFlags().Lookup("auth-cache-ttl"))
 	_ = viper.BindPFlag("gpg_key", rootCmd.PersistentFlags().Lookup("gpg-key"))
+	_ = viper.BindPFlag("account", rootCmd.PersistentFlags().Lookup("account"))
+	_ = viper.BindPFlag("llm_provider", rootCmd.PersistentFlags().Lookup("llm-provider"))
+	_ = viper.BindPFlag("llm_api_key", rootCmd.PersistentFlags().Lookup("llm-api-key"))
+	_ = viper.BindPFlag("steam_root", rootCmd.PersistentFlags().Lookup("steam-root"))
 }
 
 func initConfig() {
@@ -73,6 +82,12 @@ func initConfig() {
 	}
 }
 
+// openDB opens the local database. Rows stored before multi-account support
+// are assigned to the configured steamid64.
+func openDB() (*db.DB, error) {
+	return db.New("steam-pick", db.WithDefaultSteamID(viper.GetString("steamid64")))
+}
+
 func getAPIKey() (string, error) {
 	key := viper.GetString("api_key")
 	if key != "" {
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/llm"
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchTop     int
+	searchOutput  string
+	searchKeyword bool
+)
+
+// searchResult is a library game matching a search. Method is "semantic"
+// or "keyword".
+type searchResult struct {
+	AppID  int     `json:"appid"`
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Method string  `json:"method"`
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search your library in natural language",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var client llm.Client
+		if !searchKeyword {
+			client, err = newLLMClient(llmBaseURL, "", llmEmbedModel)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		results, err := searchLibrary(context.Background(), database, client, steamID, strings.Join(args, " "), llmEmbedModel, searchTop)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if searchOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if results == nil {
+				results = []searchResult{}
+			}
+			if err := enc.Encode(results); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(results) == 0 {
+			fmt.Println("No matching games found.")
+			return
+		}
+		fmt.Printf("Search results (%s):\n", results[0].Method)
+		fmt.Printf("%-10s %-40s %s\n", "AppID", "Name", "Score")
+		fmt.Println("------------------------------------------------------------")
+		for _, r := range results {
+			fmt.Printf("%-10d %-40s %.2f\n", r.AppID, r.Name, r.Score)
+		}
+	},
+}
+
+// searchLibrary ranks the account's games by embedding similarity to text.
+// Without a client, stored embeddings or a reachable LLM it falls back to
+// the keyword index.
+func searchLibrary(ctx context.Context, database *db.DB, client llm.Client, steamID, text, embedModel string, top int) ([]searchResult, error) {
+	if client != nil {
+		results, err := searchSemantic(ctx, database, client, steamID, text, embedModel, top)
+		if err == nil {
+			return results, nil
+		}
+		fmt.Fprintf(os.Stderr, "Semantic search unavailable (%v), falling back to keyword search.\n", err)
+	}
+
+	hits, err := database.SearchKeywords(steamID, text, top)
+	if err != nil {
+		return nil, err
+	}
+	var results []searchResult
+	for _, h := range hits {
+		results = append(results, searchResult{AppID: h.AppID, Name: h.Name, Score: h.Score, Method: "keyword"})
+	}
+	return results, nil
+}
+
+func searchSemantic(ctx context.Context, database *db.DB, client llm.Client, steamID, text, embedModel string, top int) ([]searchResult, error) {
+	embeddings, err := database.GetEmbeddings(embedModel)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("no embeddings for model %s, run 'steam-pick embed'", embedModel)
+	}
+
+	q, err := client.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	games, err := database.GetOwnedGames(steamID)
+	if err != nil {
+		return nil, err
+	}
+	var results []searchResult
+	for _, g := range games {
+		v, ok := embeddings[g.AppID]
+		if !ok {
+			continue
+		}
+		results = append(results, searchResult{
+			AppID:  g.AppID,
+			Name:   g.Name,
+			Score:  logic.CosineSimilarity(q, v),
+			Method: "semantic",
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if top > 0 && len(results) > top {
+		results = results[:top]
+	}
+	return results, nil
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntVar(&searchTop, "top", 10, "Number of results")
+	searchCmd.Flags().StringVar(&searchOutput, "output", "table", "Output format 'table' or 'json'")
+	addLLMFlags(searchCmd, false, true)
+	searchCmd.Flags().BoolVar(&searchKeyword, "keyword", false, "Use the keyword index only")
+}
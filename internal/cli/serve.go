@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	serveAddr            string
+	serveReadWrite       bool
+	serveShutdownTimeout time.Duration
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the library as a JSON REST API",
+	Long: `serve exposes the local database over HTTP:
+
+  GET  /games              ?unplayed=true&where=...&limit=N&include_non_games=true
+  GET  /games/{appid}
+  POST /pick               {"turn_based_only": true, "where": "...", "exclude_recent": "7d"}
+  GET  /recommendations    ?top=N&mode=backlog&strategy=genre&where=...&explain=true
+  GET  /profile
+  POST /sync
+  PUT  /games/{appid}/status  {"status": "playing"}
+  PUT  /games/{appid}/note    {"note": "..."}
+
+and a web dashboard at / with the backlog, the taste profile, a random
+pick and status and note editing.
+
+The server is read-only unless --read-write is set: changes and /sync are
+refused and picks are not stored. Recommendation runs are never stored.
+POST and PUT requests must be sent with Content-Type: application/json, so
+other web pages cannot send them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		srv := &server.Server{
+			Service: &libraryService{
+				db:         database,
+				steamID:    steamID,
+				llmBaseURL: llmBaseURL,
+				llmModel:   llmModel,
+				embedModel: llmEmbedModel,
+			},
+			ReadOnly: !serveReadWrite,
+		}
+		mode := "read-only"
+		if serveReadWrite {
+			mode = "read-write"
+		}
+		fmt.Fprintf(os.Stderr, "Serving %s on http://%s (%s)\n", steamID, serveAddr, mode)
+		if err := srv.ListenAndServe(ctx, serveAddr, serveShutdownTimeout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, "Server stopped.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().BoolVar(&serveReadWrite, "read-write", false, "Allow /sync, status and note changes, and store picks and recommendation runs")
+	serveCmd.Flags().DurationVar(&serveShutdownTimeout, "shutdown-timeout", 10*time.Second, "Time to finish requests in flight on shutdown")
+	addLLMFlags(serveCmd, true, true)
+}
+
+// libraryService runs the commands' logic for one account against the
+// local database. It backs both serve and mcp.
+type libraryService struct {
+	db         *db.DB
+	steamID    string
+	llmBaseURL string
+	llmModel   string
+	embedModel string
+}
+
+func (s *libraryService) Games(ctx context.Context, q server.GamesQuery) ([]model.Game, error) {
+	games, err := s.db.GetOwnedGames(s.steamID)
+	if err != nil {
+		return nil, err
+	}
+	if q.UnplayedOnly {
+		games = logic.FilterUnplayed(games, q.IncludeNonGames)
+	} else if !q.IncludeNonGames {
+		var only []model.Game
+		for _, g := range games {
+			if g.Classification.IsGame() {
+				only = append(only, g)
+			}
+		}
+		games = only
+	}
+	games, err = filterWhere(s.db, s.steamID, games, q.Where)
+	if err != nil {
+		return nil, server.BadRequest(err)
+	}
+	if q.Limit > 0 && len(games) > q.Limit {
+		games = games[:q.Limit]
+	}
+	return games, nil
+}
+
+func (s *libraryService) Game(ctx context.Context, appID int) (*model.GameInfo, error) {
+	games, err := s.db.GetOwnedGames(s.steamID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range games {
+		if g.AppID != appID {
+			continue
+		}
+		details, err := s.db.GetAppDetails(g.AppID)
+		if err != nil {
+			return nil, err
+		}
+		sources, err := s.db.GetAppDetailSources(g.AppID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.GameInfo{
+			AppID:           g.AppID,
+			Name:            g.Name,
+			PlaytimeMinutes: g.PlaytimeForever,
+			Status:          g.Status,
+			Classification:  g.Classification,
+			Note:            g.Note,
+			Details:         details,
+			DetailSources:   sources,
+		}, nil
+	}
+	return nil, server.NotFound(fmt.Errorf("game %d is not in the library", appID))
+}
+
+func (s *libraryService) SetStatus(ctx context.Context, appID int, status string) (*model.GameInfo, error) {
+	next, err := model.ParseStatus(status)
+	if err != nil {
+		return nil, server.BadRequest(err)
+	}
+	game, err := s.Game(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if err := logic.SetStatus(s.db, s.steamID, game.AppID, next, false, time.Now()); err != nil {
+		var te *logic.TransitionError
+		if errors.As(err, &te) {
+			return nil, &server.Error{Status: http.StatusConflict, Err: err}
+		}
+		return nil, err
+	}
+	return s.Game(ctx, appID)
+}
+
+func (s *libraryService) SetNote(ctx context.Context, appID int, note string) (*model.GameInfo, error) {
+	if _, err := s.Game(ctx, appID); err != nil {
+		return nil, err
+	}
+	if err := s.db.SetGameNote(s.steamID, appID, note, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.Game(ctx, appID)
+}
+
+func (s *libraryService) Pick(ctx context.Context, req server.PickRequest) (*model.Game, error) {
+	opts := pickOptions{
+		Seed:            req.Seed,
+		IncludeNonGames: req.IncludeNonGames,
+		Where:           req.Where,
+		TurnBased:       req.TurnBasedOnly,
+	}
+	if req.ExcludeRecent != "" {
+		d, err := parseDuration(req.ExcludeRecent)
+		if err != nil {
+			return nil, server.BadRequest(err)
+		}
+		opts.ExcludeRecent = d
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	games, err := s.db.GetOwnedGames(s.steamID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	picked, err := pickGame(ctx, s.db, s.steamID, games, opts, now)
+	var empty nothingToPick
+	if errors.As(err, &empty) {
+		return nil, server.NotFound(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Record {
+		flags := map[string]string{}
+		if req.Source != "" {
+			flags["source"] = req.Source
+		}
+		if req.TurnBasedOnly {
+			flags["turn-based-only"] = "true"
+		}
+		if req.Where != "" {
+			flags["where"] = req.Where
+		}
+		if req.ExcludeRecent != "" {
+			flags["exclude-recent"] = req.ExcludeRecent
+		}
+		if req.IncludeNonGames {
+			flags["include-non-games"] = "true"
+		}
+		if err := s.db.RecordPick(s.steamID, picked.AppID, opts.Seed, flags, now); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record pick: %v\n", err)
+		}
+	}
+	return picked, nil
+}
+
+func (s *libraryService) Recommendations(ctx context.Context, req server.RecommendRequest) ([]model.Recommendation, error) {
+	opts := recommendOptions{
+		Mode:             req.Mode,
+		Strategy:         req.Strategy,
+		Where:            req.Where,
+		Top:              req.Top,
+		Explain:          req.Explain,
+		RerankCandidates: 20,
+		EmbedModel:       s.embedModel,
+		LLMBaseURL:       s.llmBaseURL,
+		LLMModel:         s.llmModel,
+		ReadOnly:         !req.Record,
+	}
+	if err := opts.validate(); err != nil {
+		return nil, server.BadRequest(err)
+	}
+	return recommendGames(ctx, s.db, s.steamID, opts)
+}
+
+func (s *libraryService) Profile(ctx context.Context) ([]server.GenreWeight, error) {
+	profile, _, err := loadTasteProfile(s.db, s.steamID)
+	if err != nil {
+		return nil, server.NotFound(fmt.Errorf("no taste profile (run 'steam-pick profile' first): %w", err))
+	}
+	weights := make([]server.GenreWeight, len(profile))
+	for i, g := range profile {
+		weights[i] = server.GenreWeight{Key: g.Key, Value: g.Value}
+	}
+	return weights, nil
+}
+
+func (s *libraryService) Sync(ctx context.Context) (*model.SyncResult, error) {
+	apiKey, err := getAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewSteamClient(apiKey, 24*time.Hour, viper.GetDuration("auth_cache_ttl"), 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return syncLibrary(ctx, s.db, client, s.steamID, false)
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusForce  bool
+	statusFilter string
+	statusOutput string
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Track games through backlog, playing, beaten and dropped",
+}
+
+var statusSetCmd = &cobra.Command{
+	Use:   "set <appid> <status>",
+	Short: "Set the status of a game",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid appid %q\n", args[0])
+			os.Exit(1)
+		}
+		next, err := model.ParseStatus(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := setGameStatus(database, steamID, appID, next, statusForce); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d is now %s.\n", appID, next)
+	},
+}
+
+var statusShowCmd = &cobra.Command{
+	Use:   "show <appid>",
+	Short: "Show the status history of a game",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid appid %q\n", args[0])
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		changes, err := database.GetStatusHistory(steamID, appID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching status history: %v\n", err)
+			os.Exit(1)
+		}
+
+		if statusOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(changes); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(changes) == 0 {
+			fmt.Printf("No status recorded for %d.\n", appID)
+			return
+		}
+		for _, c := range changes {
+			from := string(c.From)
+			if from == "" {
+				from = "-"
+			}
+			fmt.Printf("%s  %s -> %s\n", c.ChangedAt.Local().Format("2006-01-02 15:04"), from, c.To)
+		}
+	},
+}
+
+var statusListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List games with a status",
+	Run: func(cmd *cobra.Command, args []string) {
+		var filter model.Status
+		if statusFilter != "" {
+			st, err := model.ParseStatus(statusFilter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			filter = st
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		steamID, err := getSteamID(context.Background(), nil, "", "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		games, err := database.GetOwnedGames(steamID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching games: %v\n", err)
+			os.Exit(1)
+		}
+
+		var result []model.Game
+		for _, g := range games {
+			if g.Status == "" || (filter != "" && g.Status != filter) {
+				continue
+			}
+			result = append(result, g)
+		}
+		sort.Slice(result, func(i, j int) bool {
+			if result[i].Status != result[j].Status {
+				return result[i].Status < result[j].Status
+			}
+			return result[i].Name < result[j].Name
+		})
+
+		if statusOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		for _, g := range result {
+			fmt.Printf("%-8s %d: %s\n", g.Status, g.AppID, g.Name)
+		}
+	},
+}
+
+// setGameStatus changes a game's status from the command line. force skips
+// the transition rules.
+func setGameStatus(store logic.StatusStore, steamID string, appID int, next model.Status, force bool) error {
+	err := logic.SetStatus(store, steamID, appID, next, force, time.Now())
+	var te *logic.TransitionError
+	if errors.As(err, &te) {
+		return fmt.Errorf("%w (use --force to override)", err)
+	}
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.AddCommand(statusSetCmd)
+	statusCmd.AddCommand(statusShowCmd)
+	statusCmd.AddCommand(statusListCmd)
+
+	statusSetCmd.Flags().BoolVar(&statusForce, "force", false, "Allow any status transition")
+	statusListCmd.Flags().StringVar(&statusFilter, "status", "", "Only list games with this status")
+	statusCmd.PersistentFlags().StringVar(&statusOutput, "output", "table", "Output format 'table' or 'json'")
+}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/dajoen/steam-pick/internal/db"
 	"github.com/dajoen/steam-pick/internal/model"
-	"github.com/dajoen/steam-pick/internal/steamapi"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -30,7 +29,7 @@ var syncCmd = &cobra.Command{
 		}
 
 		vanityTTL := viper.GetDuration("auth_cache_ttl")
-		client, err := steamapi.NewClient(apiKey, 24*time.Hour, vanityTTL, 30*time.Second)
+		client, err := NewSteamClient(apiKey, 24*time.Hour, vanityTTL, 30*time.Second)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -42,7 +41,7 @@ var syncCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		database, err := db.New("steam-pick")
+		database, err := openDB()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -50,55 +49,135 @@ var syncCmd = &cobra.Command{
 		defer func() { _ = database.Close() }()
 
 		fmt.Printf("Fetching games for SteamID: %s\n", syncSteamID)
-		games, err := client.GetOwnedGames(context.Background(), syncSteamID, syncIncludeFreeToPlay)
+		res, err := syncLibrary(context.Background(), database, client, syncSteamID, syncIncludeFreeToPlay)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error fetching games: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Fetch existing games to compare
-		existingGames, err := database.GetOwnedGames()
-		if err != nil {
-			// If error (e.g. empty table), just proceed
-			existingGames = []model.Game{}
+		fmt.Printf("Sync Summary: %d new, %d updated, %d unchanged, %d removed.\n", res.New, res.Updated, res.Unchanged, len(res.Removed))
+		for _, g := range res.Removed {
+			fmt.Printf("Removed from library: %s (%d)\n", g.Name, g.AppID)
 		}
-
-		existingMap := make(map[int]model.Game)
-		for _, g := range existingGames {
-			existingMap[g.AppID] = g
+		if res.New+res.Updated+len(res.Removed) == 0 {
+			fmt.Println("Database is already up to date.")
 		}
 
-		var newGames, updatedGames []model.Game
-		unchangedCount := 0
+		fmt.Println("Sync complete.")
+	},
+}
 
-		for _, g := range games {
-			if existing, ok := existingMap[g.AppID]; !ok {
-				newGames = append(newGames, g)
+// syncLibrary fetches the account's games, stores new and changed ones with
+// a playtime snapshot and marks games Steam no longer returns as removed.
+// Renamed games, such as the "App <appid>" placeholders of a local import,
+// take Steam's name without a snapshot.
+func syncLibrary(ctx context.Context, database *db.DB, client SteamClient, steamID string, includeFree bool) (*model.SyncResult, error) {
+	games, err := client.GetOwnedGames(ctx, steamID, includeFree)
+	if err != nil {
+		return nil, fmt.Errorf("fetching games: %w", err)
+	}
+
+	// Fetch existing games to compare
+	existingGames, err := database.GetOwnedGames(steamID)
+	if err != nil {
+		// If error (e.g. empty table), just proceed
+		existingGames = []model.Game{}
+	}
+
+	existingMap := make(map[int]model.Game)
+	for _, g := range existingGames {
+		existingMap[g.AppID] = g
+	}
+
+	var newGames, updatedGames, renamedGames []model.Game
+	res := &model.SyncResult{Removed: []model.Game{}}
+	fetched := make(map[int]bool, len(games))
+
+	for _, g := range games {
+		fetched[g.AppID] = true
+		if existing, ok := existingMap[g.AppID]; !ok {
+			newGames = append(newGames, g)
+		} else {
+			// Check if updated
+			if g.PlaytimeForever != existing.PlaytimeForever || g.RTimeLastPlayed != existing.RTimeLastPlayed {
+				updatedGames = append(updatedGames, g)
+			} else if g.Name != "" && g.Name != existing.Name {
+				renamedGames = append(renamedGames, g)
 			} else {
-				// Check if updated
-				if g.PlaytimeForever != existing.PlaytimeForever || g.RTimeLastPlayed != existing.RTimeLastPlayed {
-					updatedGames = append(updatedGames, g)
-				} else {
-					unchangedCount++
-				}
+				res.Unchanged++
+			}
+		}
+	}
+	res.New = len(newGames)
+	res.Updated = len(updatedGames) + len(renamedGames)
+
+	// An empty response usually means a private profile, not an empty
+	// library, so it never tombstones anything. A fetch without free games
+	// only tombstones games an earlier such fetch returned, games the store
+	// lists as not free and games only the local client listed; free-to-play
+	// games stored by a fetch with them are simply missing from it.
+	if len(games) > 0 {
+		var notFree map[int]bool
+		if !includeFree {
+			if notFree, err = database.GetNotFreeToPlayApps(steamID); err != nil {
+				return nil, fmt.Errorf("loading games: %w", err)
 			}
 		}
+		localOnly, err := database.GetLocalOnlyApps(steamID)
+		if err != nil {
+			return nil, fmt.Errorf("loading games: %w", err)
+		}
+		for _, g := range existingGames {
+			if !fetched[g.AppID] && (includeFree || notFree[g.AppID] || localOnly[g.AppID]) {
+				res.Removed = append(res.Removed, g)
+			}
+		}
+	}
 
-		fmt.Printf("Sync Summary: %d new, %d updated, %d unchanged.\n", len(newGames), len(updatedGames), unchangedCount)
+	if len(res.Removed) > 0 {
+		removedIDs := make([]int, 0, len(res.Removed))
+		for _, g := range res.Removed {
+			removedIDs = append(removedIDs, g.AppID)
+		}
+		if err := database.MarkGamesRemoved(steamID, removedIDs); err != nil {
+			return nil, fmt.Errorf("recording removed games: %w", err)
+		}
+	}
 
-		gamesToSave := append(newGames, updatedGames...)
-		if len(gamesToSave) > 0 {
-			fmt.Printf("Saving %d games to database...\n", len(gamesToSave))
-			if err := database.UpsertGames(gamesToSave); err != nil {
-				fmt.Fprintf(os.Stderr, "Error saving games: %v\n", err)
-				os.Exit(1)
-			}
-		} else {
-			fmt.Println("Database is already up to date.")
+	gamesToSave := append(newGames, updatedGames...)
+	if len(gamesToSave)+len(renamedGames) > 0 {
+		if err := database.UpsertGames(steamID, append(gamesToSave, renamedGames...)); err != nil {
+			return nil, fmt.Errorf("saving games: %w", err)
 		}
 
-		fmt.Println("Sync complete.")
-	},
+		previous := make(map[int]int, len(existingMap))
+		for id, g := range existingMap {
+			previous[id] = g.PlaytimeForever
+		}
+		if err := database.RecordPlaytimeSnapshots(steamID, time.Now(), gamesToSave, previous); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record playtime history: %v\n", err)
+		}
+	}
+	if err := markFetched(database, steamID, games, includeFree); err != nil {
+		return nil, fmt.Errorf("saving games: %w", err)
+	}
+	return res, nil
+}
+
+// markFetched records that the Web API returned games: they are no longer
+// local-only, and a fetch without free games marks them not free-to-play.
+func markFetched(database *db.DB, steamID string, games []model.Game, includeFree bool) error {
+	ids := make([]int, len(games))
+	for i, g := range games {
+		ids[i] = g.AppID
+	}
+	if err := database.ClearLocalOnly(steamID, ids); err != nil {
+		return err
+	}
+	if includeFree {
+		return nil
+	}
+	return database.MarkNotFreeToPlay(steamID, ids)
 }
 
 func init() {
@@ -0,0 +1,363 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/steamlocal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagListOutput string
+	tagExportDry  bool
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Group games with user tags, shared with Steam collections",
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <tag> <appid>...",
+	Short: "Tag games, creating the tag when needed",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		appIDs, err := parseAppIDs(args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		database, steamID := openPickDB()
+		defer func() { _ = database.Close() }()
+
+		if err := database.AddTag(steamID, args[0], appIDs, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", steamTagError(args[0], err))
+			os.Exit(1)
+		}
+		fmt.Printf("Tagged %d games %q.\n", len(appIDs), args[0])
+	},
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove <tag> [appid]...",
+	Short: "Untag games, or delete the tag when no games are given",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		appIDs, err := parseAppIDs(args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		database, steamID := openPickDB()
+		defer func() { _ = database.Close() }()
+
+		if err := database.RemoveTag(steamID, args[0], appIDs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", steamTagError(args[0], err))
+			os.Exit(1)
+		}
+		if len(appIDs) == 0 {
+			fmt.Printf("Deleted tag %q.\n", args[0])
+		} else {
+			fmt.Printf("Untagged %d games %q.\n", len(appIDs), args[0])
+		}
+	},
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list [tag]",
+	Short: "List tags, or the games with a tag",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		database, steamID := openPickDB()
+		defer func() { _ = database.Close() }()
+
+		if len(args) == 1 {
+			games, err := database.GetOwnedGames(steamID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			tagged, err := filterTagged(database, steamID, games, args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if tagListOutput == "json" {
+				if tagged == nil {
+					tagged = []model.Game{}
+				}
+				encodeJSON(tagged)
+				return
+			}
+			if len(tagged) == 0 {
+				fmt.Printf("No games in the library are tagged %q.\n", args[0])
+				return
+			}
+			for _, g := range tagged {
+				fmt.Printf("%d: %s\n", g.AppID, g.Name)
+			}
+			return
+		}
+
+		tags, err := database.GetTags(steamID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if tagListOutput == "json" {
+			if tags == nil {
+				tags = []model.Tag{}
+			}
+			encodeJSON(tags)
+			return
+		}
+		if len(tags) == 0 {
+			fmt.Println("No tags yet. Use 'steam-pick tag add' or 'steam-pick tag import'.")
+			return
+		}
+		fmt.Printf("%-30s %-10s %6s %s\n", "Tag", "Source", "Games", "Collection")
+		for _, t := range tags {
+			fmt.Printf("%-30s %-10s %6d %s\n", t.Name, t.Source, len(t.AppIDs), t.CollectionID)
+		}
+	},
+}
+
+var tagImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the Steam client's collections as tags",
+	Long: `import reads the account's collections from the Steam client's
+userdata/<id>/config/cloudstorage/cloud-storage-namespace-1.json. Each
+collection becomes a tag holding exactly the collection's games; tags with
+the same name are replaced. Dynamic collections have no stored games and
+are skipped, as are the collections of tags deleted since the last export.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		root := steamRoot()
+		steamID, err := localSteamID(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		path, err := steamlocal.CollectionsPath(root, steamID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		collections, err := steamlocal.ReadCollections(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		// Collections of tags deleted here come back only after export
+		// deleted them in Steam, so they are not imported in between.
+		deletedIDs, err := database.GetDeletedCollections(steamID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		deleted := make(map[string]bool, len(deletedIDs))
+		for _, id := range deletedIDs {
+			deleted[id] = true
+		}
+
+		imported, dynamic := 0, 0
+		for _, c := range collections {
+			if c.Dynamic {
+				dynamic++
+				continue
+			}
+			if deleted[c.ID] {
+				continue
+			}
+			if err := database.ImportCollection(steamID, c.Name, c.ID, c.AppIDs, time.Now()); err != nil {
+				fmt.Fprintf(os.Stderr, "Error importing %q: %v\n", c.Name, err)
+				os.Exit(1)
+			}
+			fmt.Printf("%-30s %d games\n", c.Name, len(c.AppIDs))
+			imported++
+		}
+		fmt.Printf("Imported %d collections from %s", imported, path)
+		if dynamic > 0 {
+			fmt.Printf(" (skipped %d dynamic)", dynamic)
+		}
+		fmt.Println(".")
+	},
+}
+
+var tagExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write tags created in steam-pick to the Steam client as collections",
+	Long: `export adds the tags created with 'tag add' to the Steam client's
+cloud-storage-namespace-1.json, or updates the collections they were
+exported as before. The collections of exported tags deleted since are
+deleted. Other collections are left alone and the previous file is kept as
+a .bak next to it.
+
+Quit the Steam client first: it rewrites the file while running.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		root := steamRoot()
+		steamID, err := localSteamID(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		path, err := steamlocal.CollectionsPath(root, steamID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() { _ = database.Close() }()
+
+		collections, err := exportCollections(database, steamID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(collections) == 0 {
+			fmt.Println("No tags created in steam-pick to export.")
+			return
+		}
+
+		if tagExportDry {
+			data, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				data = []byte("[]")
+			} else if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			merged, err := steamlocal.MergeCollections(data, collections, time.Now())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(merged))
+			return
+		}
+
+		if err := steamlocal.WriteCollections(path, collections, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// Remember the IDs so the next export updates the same collections.
+		exported := 0
+		var deleted []string
+		for _, c := range collections {
+			if c.Deleted {
+				deleted = append(deleted, c.ID)
+				continue
+			}
+			if err := database.SetTagCollectionID(steamID, c.Name, c.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			exported++
+		}
+		if err := database.ClearDeletedCollections(steamID, deleted); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Exported %d tags to %s", exported, path)
+		if len(deleted) > 0 {
+			fmt.Printf(" (deleted %d collections)", len(deleted))
+		}
+		fmt.Println(".")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagListCmd)
+	tagCmd.AddCommand(tagImportCmd)
+	tagCmd.AddCommand(tagExportCmd)
+
+	tagListCmd.Flags().StringVar(&tagListOutput, "output", "table", "Output format 'table' or 'json'")
+	tagExportCmd.Flags().BoolVar(&tagExportDry, "dry-run", false, "Print the new file instead of writing it")
+}
+
+// exportCollections returns the tags created in steam-pick as collections,
+// followed by the collections of deleted tags to delete. Tags exported for
+// the first time get a new collection ID.
+func exportCollections(database *db.DB, steamID string) ([]steamlocal.Collection, error) {
+	tags, err := database.GetTags(steamID)
+	if err != nil {
+		return nil, err
+	}
+	var collections []steamlocal.Collection
+	for _, t := range tags {
+		if t.Source != model.TagSourceUser {
+			continue
+		}
+		id := t.CollectionID
+		if id == "" {
+			if id, err = steamlocal.NewCollectionID(); err != nil {
+				return nil, err
+			}
+		}
+		collections = append(collections, steamlocal.Collection{ID: id, Name: t.Name, AppIDs: t.AppIDs})
+	}
+	deleted, err := database.GetDeletedCollections(steamID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range deleted {
+		collections = append(collections, steamlocal.Collection{ID: id, Deleted: true})
+	}
+	return collections, nil
+}
+
+// steamTagError explains how to change a tag imported from Steam.
+func steamTagError(tag string, err error) error {
+	if errors.Is(err, db.ErrSteamTag) {
+		return fmt.Errorf("%q: %w; change the collection in the Steam client and run 'steam-pick tag import'", tag, err)
+	}
+	return err
+}
+
+// filterTagged keeps the games with a tag.
+func filterTagged(database *db.DB, steamID string, games []model.Game, tag string) ([]model.Game, error) {
+	tagged, err := database.GetTaggedApps(steamID, tag)
+	if err != nil {
+		return nil, err
+	}
+	var result []model.Game
+	for _, g := range games {
+		if tagged[g.AppID] {
+			result = append(result, g)
+		}
+	}
+	return result, nil
+}
+
+func parseAppIDs(args []string) ([]int, error) {
+	ids := make([]int, 0, len(args))
+	for _, a := range args {
+		id, err := strconv.Atoi(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid appid %q", a)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
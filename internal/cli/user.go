@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/dajoen/steam-pick/internal/cache"
@@ -14,13 +15,32 @@ type UserCache struct {
 	Vanity    string `json:"vanity"`
 }
 
+// getSteamID resolves the account to work on. client may be nil for commands
+// that only use the local database; vanity names then resolve from the cache.
 func getSteamID(ctx context.Context, client SteamClient, steamIDFlag, vanityFlag string) (string, error) {
+	// 0. The global --account selector wins over everything else
+	if account := viper.GetString("account"); account != "" {
+		if id, ok := lookupAccount(account); ok {
+			return id, nil
+		}
+		if client == nil {
+			return "", fmt.Errorf("unknown account %q (use a SteamID64 or an alias from the 'accounts' config map)", account)
+		}
+		return client.ResolveVanityURL(ctx, account)
+	}
+
 	// 1. Prefer explicit flags
 	if steamIDFlag != "" {
 		_ = saveUserCache(steamIDFlag, "")
 		return steamIDFlag, nil
 	}
 	if vanityFlag != "" {
+		if client == nil {
+			if cached := loadUserCache(); cached != nil && cached.Vanity == vanityFlag {
+				return cached.SteamID64, nil
+			}
+			return "", fmt.Errorf("resolving vanity name %q requires the Steam Web API", vanityFlag)
+		}
 		id, err := client.ResolveVanityURL(ctx, vanityFlag)
 		if err != nil {
 			return "", err
@@ -30,16 +50,8 @@ func getSteamID(ctx context.Context, client SteamClient, steamIDFlag, vanityFlag
 	}
 
 	// 2. Check Cache
-	c, err := cache.New[UserCache]("steam-pick")
-	if err == nil {
-		gpgKey := viper.GetString("gpg_key")
-		if gpgKey != "" {
-			c.WithEncryption(gpgKey)
-		}
-		// Use a long TTL for user preference (e.g. 30 days)
-		if cached, found, _ := c.Get("last_user", 720*time.Hour); found {
-			return cached.SteamID64, nil
-		}
+	if cached := loadUserCache(); cached != nil {
+		return cached.SteamID64, nil
 	}
 
 	// 3. Check Config
@@ -50,6 +62,39 @@ func getSteamID(ctx context.Context, client SteamClient, steamIDFlag, vanityFlag
 	return "", fmt.Errorf("--steamid64 or --vanity is required (or run 'steam-pick login')")
 }
 
+// lookupAccount maps an --account value to a SteamID64. The value is either a
+// SteamID64 or an alias from the 'accounts' config map, e.g.
+//
+//	accounts:
+//	  me: "76561198000000000"
+//	  partner: "76561198000000001"
+func lookupAccount(account string) (string, bool) {
+	if len(account) == 17 && isNumeric(account) {
+		return account, true
+	}
+	if id := viper.GetStringMapString("accounts")[strings.ToLower(account)]; id != "" {
+		return id, true
+	}
+	return "", false
+}
+
+func loadUserCache() *UserCache {
+	c, err := cache.New[UserCache]("steam-pick")
+	if err != nil {
+		return nil
+	}
+	gpgKey := viper.GetString("gpg_key")
+	if gpgKey != "" {
+		c.WithEncryption(gpgKey)
+	}
+	// Use a long TTL for user preference (e.g. 30 days)
+	cached, found, _ := c.Get("last_user", 720*time.Hour)
+	if !found {
+		return nil
+	}
+	return cached
+}
+
 func saveUserCache(steamID, vanity string) error {
 	c, err := cache.New[UserCache]("steam-pick")
 	if err != nil {
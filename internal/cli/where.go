@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/query"
+)
+
+const whereUsage = `Filter expression, e.g. 'genre:RPG and category:"Full controller support" and platform:linux and playtime<120'`
+
+// filterWhere keeps the games matching a --where expression. Details come
+// from the local database; games without stored details only match on
+// fields like name and playtime.
+func filterWhere(database *db.DB, steamID string, games []model.Game, where string) ([]model.Game, error) {
+	if where == "" {
+		return games, nil
+	}
+	pred, err := query.Parse(where)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := database.GetGamesWithDetails(steamID)
+	if err != nil {
+		return nil, err
+	}
+	details := make(map[int]model.GameDetails, len(stored))
+	for _, g := range stored {
+		details[g.AppID] = g
+	}
+
+	var matched []model.Game
+	for _, g := range games {
+		d, ok := details[g.AppID]
+		if !ok {
+			d = model.GameDetails{Genres: "[]", Categories: "[]", Platforms: "{}"}
+		}
+		// The in-memory game is fresher than the stored one.
+		d.Game = g
+		if pred(d) {
+			matched = append(matched, g)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"database/sql"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// UpsertAchievements replaces the stored achievement state of a game.
+func (d *DB) UpsertAchievements(steamID string, appID, total int, unlocks []model.AchievementUnlock) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var last sql.NullTime
+	for _, u := range unlocks {
+		if !last.Valid || u.UnlockedAt.After(last.Time) {
+			last = sql.NullTime{Time: u.UnlockedAt.UTC(), Valid: true}
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO achievement_progress (steamid, appid, unlocked, total, last_unlocked_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(steamid, appid) DO UPDATE SET
+			unlocked=excluded.unlocked,
+			total=excluded.total,
+			last_unlocked_at=excluded.last_unlocked_at,
+			updated_at=CURRENT_TIMESTAMP
+	`, steamID, appID, len(unlocks), total, last)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM achievement_unlocks WHERE steamid = ? AND appid = ?", steamID, appID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO achievement_unlocks (steamid, appid, apiname, display_name, unlocked_at)
+		VALUES (?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, u := range unlocks {
+		if _, err := stmt.Exec(steamID, appID, u.APIName, u.DisplayName, u.UnlockedAt.UTC()); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAchievementProgress returns the achievement completion of every owned
+// game that has been synced, most complete first.
+func (d *DB) GetAchievementProgress(steamID string) ([]model.AchievementProgress, error) {
+	rows, err := d.Query(`
+		SELECT
+			ap.appid, COALESCE(g.name, ''), ap.unlocked, ap.total, ap.last_unlocked_at
+		FROM achievement_progress ap
+		JOIN owned_games g ON g.steamid = ap.steamid AND g.appid = ap.appid
+		WHERE ap.steamid = ? AND g.removed_at IS NULL AND ap.total > 0
+		ORDER BY CAST(ap.unlocked AS REAL) / ap.total DESC, ap.unlocked DESC
+	`, steamID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var progress []model.AchievementProgress
+	for rows.Next() {
+		var p model.AchievementProgress
+		var last sql.NullTime
+		if err := rows.Scan(&p.AppID, &p.Name, &p.Unlocked, &p.Total, &last); err != nil {
+			return nil, err
+		}
+		if last.Valid {
+			t := last.Time
+			p.LastUnlock = &t
+		}
+		progress = append(progress, p)
+	}
+	return progress, rows.Err()
+}
+
+// GetAchievementUnlocks returns the unlocked achievements of a game, oldest first.
+func (d *DB) GetAchievementUnlocks(steamID string, appID int) ([]model.AchievementUnlock, error) {
+	rows, err := d.Query(`
+		SELECT apiname, COALESCE(display_name, ''), unlocked_at
+		FROM achievement_unlocks
+		WHERE steamid = ? AND appid = ?
+		ORDER BY unlocked_at
+	`, steamID, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var unlocks []model.AchievementUnlock
+	for rows.Next() {
+		var u model.AchievementUnlock
+		if err := rows.Scan(&u.APIName, &u.DisplayName, &u.UnlockedAt); err != nil {
+			return nil, err
+		}
+		unlocks = append(unlocks, u)
+	}
+	return unlocks, rows.Err()
+}
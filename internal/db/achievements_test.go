@@ -0,0 +1,63 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func TestAchievements(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	if err := d.UpsertGames(testSteamID, []model.Game{{AppID: 1, Name: "Game 1"}, {AppID: 2, Name: "Game 2"}}); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+
+	first := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	last := first.Add(48 * time.Hour)
+	unlocks := []model.AchievementUnlock{
+		{APIName: "ACH_WIN", DisplayName: "Winner", UnlockedAt: last},
+		{APIName: "ACH_START", DisplayName: "Starter", UnlockedAt: first},
+	}
+	if err := d.UpsertAchievements(testSteamID, 1, 2, unlocks); err != nil {
+		t.Fatalf("UpsertAchievements failed: %v", err)
+	}
+	if err := d.UpsertAchievements(testSteamID, 2, 4, unlocks[1:]); err != nil {
+		t.Fatalf("UpsertAchievements failed: %v", err)
+	}
+
+	progress, err := d.GetAchievementProgress(testSteamID)
+	if err != nil {
+		t.Fatalf("GetAchievementProgress failed: %v", err)
+	}
+	if len(progress) != 2 {
+		t.Fatalf("Expected 2 games, got %d", len(progress))
+	}
+	if progress[0].AppID != 1 || progress[0].Completion() != 1 {
+		t.Errorf("Expected game 1 first and complete, got %+v", progress[0])
+	}
+	if progress[0].LastUnlock == nil || !progress[0].LastUnlock.Equal(last) {
+		t.Errorf("Expected last unlock %v, got %v", last, progress[0].LastUnlock)
+	}
+
+	got, err := d.GetAchievementUnlocks(testSteamID, 1)
+	if err != nil {
+		t.Fatalf("GetAchievementUnlocks failed: %v", err)
+	}
+	if len(got) != 2 || got[0].APIName != "ACH_START" {
+		t.Errorf("Expected unlocks ordered by time, got %+v", got)
+	}
+
+	games, _ := d.GetGamesWithDetails(testSteamID)
+	for _, g := range games {
+		if g.AppID == 2 && (g.AchievementsUnlocked != 1 || g.AchievementsTotal != 4) {
+			t.Errorf("Expected 1/4 achievements for game 2, got %d/%d", g.AchievementsUnlocked, g.AchievementsTotal)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/llm"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// CreateChatSession starts a new conversation and returns its ID.
+func (d *DB) CreateChatSession(steamID, title string, at time.Time) (int64, error) {
+	res, err := d.Exec(`
+		INSERT INTO chat_sessions (steamid, created_at, updated_at, title) VALUES (?, ?, ?, ?)
+	`, steamID, at, at, title)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// GetChatSession returns a conversation of the account, or nil when it does
+// not exist.
+func (d *DB) GetChatSession(steamID string, id int64) (*model.ChatSession, error) {
+	var s model.ChatSession
+	err := d.QueryRow(`
+		SELECT id, created_at, updated_at, COALESCE(title, '') FROM chat_sessions WHERE steamid = ? AND id = ?
+	`, steamID, id).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt, &s.Title)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetChatSessions returns the account's conversations, most recently
+// active first.
+func (d *DB) GetChatSessions(steamID string, limit int) ([]model.ChatSession, error) {
+	rows, err := d.Query(`
+		SELECT id, created_at, updated_at, COALESCE(title, '') FROM chat_sessions
+		WHERE steamid = ?
+		ORDER BY updated_at DESC, id DESC
+		LIMIT ?
+	`, steamID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var sessions []model.ChatSession
+	for rows.Next() {
+		var s model.ChatSession
+		if err := rows.Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt, &s.Title); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// AppendChatMessages adds messages to a conversation.
+func (d *DB) AppendChatMessages(sessionID int64, messages []llm.Message, at time.Time) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO chat_messages (session_id, role, content, tool_calls, tool_call_id, name, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, m := range messages {
+		var toolCalls interface{}
+		if len(m.ToolCalls) > 0 {
+			b, err := json.Marshal(m.ToolCalls)
+			if err != nil {
+				return err
+			}
+			toolCalls = string(b)
+		}
+		if _, err := stmt.Exec(sessionID, m.Role, m.Content, toolCalls, m.ToolCallID, m.Name, at); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("UPDATE chat_sessions SET updated_at = ? WHERE id = ?", at, sessionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetChatMessages returns the messages of a conversation in order.
+func (d *DB) GetChatMessages(sessionID int64) ([]llm.Message, error) {
+	rows, err := d.Query(`
+		SELECT role, COALESCE(content, ''), tool_calls, COALESCE(tool_call_id, ''), COALESCE(name, '')
+		FROM chat_messages WHERE session_id = ? ORDER BY id
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var messages []llm.Message
+	for rows.Next() {
+		var m llm.Message
+		var toolCalls sql.NullString
+		if err := rows.Scan(&m.Role, &m.Content, &toolCalls, &m.ToolCallID, &m.Name); err != nil {
+			return nil, err
+		}
+		if toolCalls.Valid {
+			if err := json.Unmarshal([]byte(toolCalls.String), &m.ToolCalls); err != nil {
+				return nil, err
+			}
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
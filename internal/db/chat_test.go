@@ -0,0 +1,59 @@
+package db_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/llm"
+)
+
+func TestChatSessions(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first, err := d.CreateChatSession(testSteamID, "Strategy games", base)
+	if err != nil {
+		t.Fatalf("CreateChatSession failed: %v", err)
+	}
+	second, err := d.CreateChatSession(testSteamID, "Racing", base.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("CreateChatSession failed: %v", err)
+	}
+
+	messages := []llm.Message{
+		{Role: "user", Content: "Short strategy games?"},
+		{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "call_0", Name: "query_games", Arguments: json.RawMessage(`{"genre":"Strategy"}`)}}},
+		{Role: "tool", Content: `[]`, ToolCallID: "call_0", Name: "query_games"},
+		{Role: "assistant", Content: "None."},
+	}
+	if err := d.AppendChatMessages(first, messages, base.Add(time.Hour)); err != nil {
+		t.Fatalf("AppendChatMessages failed: %v", err)
+	}
+
+	got, err := d.GetChatMessages(first)
+	if err != nil {
+		t.Fatalf("GetChatMessages failed: %v", err)
+	}
+	if len(got) != 4 || got[1].ToolCalls[0].Name != "query_games" || string(got[1].ToolCalls[0].Arguments) != `{"genre":"Strategy"}` || got[2].ToolCallID != "call_0" {
+		t.Errorf("unexpected messages: %+v", got)
+	}
+
+	sessions, err := d.GetChatSessions(testSteamID, 10)
+	if err != nil {
+		t.Fatalf("GetChatSessions failed: %v", err)
+	}
+	// The session with new messages is the most recently active.
+	if len(sessions) != 2 || sessions[0].ID != first || sessions[1].ID != second {
+		t.Errorf("unexpected sessions: %+v", sessions)
+	}
+
+	if s, err := d.GetChatSession("76561198000000001", first); err != nil || s != nil {
+		t.Errorf("session of another account: got %+v, %v", s, err)
+	}
+}
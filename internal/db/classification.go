@@ -0,0 +1,73 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// ReclassifyApps recomputes the classification of every stored app from its
+// store details. It runs as part of migration 9, so it reads only columns
+// that exist at that schema version.
+func (d *DB) ReclassifyApps() error {
+	rows, err := d.Query("SELECT appid, type, categories, genres FROM app_details")
+	if err != nil {
+		return err
+	}
+	classes := make(map[int]model.AppClass)
+	for rows.Next() {
+		var appID int
+		var typ, categories, genres sql.NullString
+		if err := rows.Scan(&appID, &typ, &categories, &genres); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		a := model.AppDetails{Type: typ.String}
+		for _, f := range []struct {
+			src sql.NullString
+			dst interface{}
+		}{{categories, &a.Categories}, {genres, &a.Genres}} {
+			if !f.src.Valid || f.src.String == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(f.src.String), f.dst); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("decoding app %d details: %w", appID, err)
+			}
+		}
+		classes[appID] = model.ClassifyApp(a)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for appID, class := range classes {
+		if _, err := d.Exec("UPDATE app_details SET classification = ? WHERE appid = ?", string(class), appID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetClassifications returns the stored classification of every enriched app.
+func (d *DB) GetClassifications() (map[int]model.AppClass, error) {
+	rows, err := d.Query("SELECT appid, classification FROM app_details WHERE classification IS NOT NULL AND classification != ''")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	classes := make(map[int]model.AppClass)
+	for rows.Next() {
+		var appID int
+		var class model.AppClass
+		if err := rows.Scan(&appID, &class); err != nil {
+			return nil, err
+		}
+		classes[appID] = class
+	}
+	return classes, rows.Err()
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,9 +15,23 @@ import (
 
 type DB struct {
 	*sql.DB
+	defaultSteamID string
+	// fts reports whether the keyword index is an FTS5 table.
+	fts bool
 }
 
-func New(appName string) (*DB, error) {
+// Option configures a DB when it is opened.
+type Option func(*DB)
+
+// WithDefaultSteamID sets the account that rows stored before multi-account
+// support are assigned to.
+func WithDefaultSteamID(steamID string) Option {
+	return func(d *DB) {
+		d.defaultSteamID = steamID
+	}
+}
+
+func New(appName string, opts ...Option) (*DB, error) {
 	userCacheDir, err := os.UserCacheDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user cache dir: %w", err)
@@ -27,10 +42,10 @@ func New(appName string) (*DB, error) {
 	}
 
 	dbPath := filepath.Join(dir, "steampick.db")
-	return NewWithDSN(dbPath)
+	return NewWithDSN(dbPath, opts...)
 }
 
-func NewWithDSN(dsn string) (*DB, error) {
+func NewWithDSN(dsn string, opts ...Option) (*DB, error) {
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open db: %w", err)
@@ -40,15 +55,58 @@ func NewWithDSN(dsn string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping db: %w", err)
 	}
 
-	d := &DB{db}
+	d := &DB{DB: db}
+	for _, opt := range opts {
+		opt(d)
+	}
 	if err := d.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to migrate db: %w", err)
 	}
+	if err := d.claimPendingRows(); err != nil {
+		return nil, fmt.Errorf("failed to assign rows to %s: %w", d.defaultSteamID, err)
+	}
+	// A binary built with FTS5 upgrades an index created without it.
+	if err := d.ensureSearchIndex(); err != nil {
+		return nil, fmt.Errorf("failed to prepare search index: %w", err)
+	}
 
 	return d, nil
 }
 
-func (d *DB) UpsertGames(games []model.Game) error {
+// ClaimUnassignedRows assigns rows that have no SteamID64 yet to steamID.
+// It is a no-op when steamID is empty.
+func (d *DB) ClaimUnassignedRows(steamID string) error {
+	if steamID == "" {
+		return nil
+	}
+	for _, table := range []string{"owned_games", "taste_profile", "recommendations"} {
+		_, err := d.Exec("UPDATE OR IGNORE "+table+" SET steamid = ? WHERE steamid = ''", steamID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claimPendingRows assigns rows left unassigned by a migration that ran
+// without a configured account, once an account is known.
+func (d *DB) claimPendingRows() error {
+	if d.defaultSteamID == "" {
+		return nil
+	}
+	var pending int
+	err := d.QueryRow("SELECT COUNT(*) FROM meta WHERE key = 'unclaimed_rows'").Scan(&pending)
+	if err != nil || pending == 0 {
+		return err
+	}
+	if err := d.ClaimUnassignedRows(d.defaultSteamID); err != nil {
+		return err
+	}
+	_, err = d.Exec("DELETE FROM meta WHERE key = 'unclaimed_rows'")
+	return err
+}
+
+func (d *DB) UpsertGames(steamID string, games []model.Game) error {
 	tx, err := d.Begin()
 	if err != nil {
 		return err
@@ -57,12 +115,12 @@ func (d *DB) UpsertGames(games []model.Game) error {
 
 	stmt, err := tx.Prepare(`
 		INSERT INTO owned_games (
-			appid, name, playtime_forever, rtime_last_played, img_icon_url,
+			steamid, appid, name, playtime_forever, rtime_last_played, img_icon_url,
 			has_community_visible_stats, playtime_windows_forever,
 			playtime_mac_forever, playtime_linux_forever, playtime_deck_forever,
 			updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
-		ON CONFLICT(appid) DO UPDATE SET
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(steamid, appid) DO UPDATE SET
 			name=excluded.name,
 			playtime_forever=excluded.playtime_forever,
 			rtime_last_played=excluded.rtime_last_played,
@@ -72,6 +130,7 @@ func (d *DB) UpsertGames(games []model.Game) error {
 			playtime_mac_forever=excluded.playtime_mac_forever,
 			playtime_linux_forever=excluded.playtime_linux_forever,
 			playtime_deck_forever=excluded.playtime_deck_forever,
+			removed_at=NULL,
 			updated_at=CURRENT_TIMESTAMP
 	`)
 	if err != nil {
@@ -81,7 +140,7 @@ func (d *DB) UpsertGames(games []model.Game) error {
 
 	for _, g := range games {
 		_, err := stmt.Exec(
-			g.AppID, g.Name, g.PlaytimeForever, g.RTimeLastPlayed, g.ImgIconURL,
+			steamID, g.AppID, g.Name, g.PlaytimeForever, g.RTimeLastPlayed, g.ImgIconURL,
 			g.HasCommunityVisibleStats, g.PlaytimeWindowsForever,
 			g.PlaytimeMacForever, g.PlaytimeLinuxForever, g.PlaytimeDeckForever,
 		)
@@ -93,8 +152,16 @@ func (d *DB) UpsertGames(games []model.Game) error {
 	return tx.Commit()
 }
 
-func (d *DB) GetOwnedGames() ([]model.Game, error) {
-	rows, err := d.Query("SELECT appid, name, playtime_forever, rtime_last_played FROM owned_games")
+func (d *DB) GetOwnedGames(steamID string) ([]model.Game, error) {
+	rows, err := d.Query(`
+		SELECT g.appid, g.name, g.playtime_forever, g.rtime_last_played, COALESCE(s.status, ''),
+			COALESCE(ad.classification, ''), COALESCE(ad.header_image, ''), COALESCE(n.note, '')
+		FROM owned_games g
+		LEFT JOIN game_status s ON s.steamid = g.steamid AND s.appid = g.appid
+		LEFT JOIN app_details ad ON ad.appid = g.appid
+		LEFT JOIN game_notes n ON n.steamid = g.steamid AND n.appid = g.appid
+		WHERE g.steamid = ? AND g.removed_at IS NULL
+	`, steamID)
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +170,7 @@ func (d *DB) GetOwnedGames() ([]model.Game, error) {
 	var games []model.Game
 	for rows.Next() {
 		var g model.Game
-		if err := rows.Scan(&g.AppID, &g.Name, &g.PlaytimeForever, &g.RTimeLastPlayed); err != nil {
+		if err := rows.Scan(&g.AppID, &g.Name, &g.PlaytimeForever, &g.RTimeLastPlayed, &g.Status, &g.Classification, &g.HeaderImage, &g.Note); err != nil {
 			return nil, err
 		}
 		games = append(games, g)
@@ -111,22 +178,156 @@ func (d *DB) GetOwnedGames() ([]model.Game, error) {
 	return games, nil
 }
 
-func (d *DB) GetLastUpdate() (time.Time, error) {
+// MarkGamesRemoved tombstones games that are no longer in the account's
+// library. Upserting a game again clears the tombstone.
+func (d *DB) MarkGamesRemoved(steamID string, appIDs []int) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`
+		UPDATE owned_games SET removed_at = CURRENT_TIMESTAMP
+		WHERE steamid = ? AND appid = ? AND removed_at IS NULL
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, id := range appIDs {
+		if _, err := stmt.Exec(steamID, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// MarkNotFreeToPlay records that games were returned by a fetch without
+// free-to-play games, so a later such fetch may tombstone them.
+func (d *DB) MarkNotFreeToPlay(steamID string, appIDs []int) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`
+		UPDATE owned_games SET not_free_to_play = 1
+		WHERE steamid = ? AND appid = ? AND NOT not_free_to_play
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, id := range appIDs {
+		if _, err := stmt.Exec(steamID, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetNotFreeToPlayApps returns the games marked by MarkNotFreeToPlay and
+// those the Steam Store lists as not free, which covers games stored before
+// the mark existed.
+func (d *DB) GetNotFreeToPlayApps(steamID string) (map[int]bool, error) {
+	rows, err := d.Query(`
+		SELECT g.appid FROM owned_games g
+		LEFT JOIN app_details ad ON ad.appid = g.appid
+		WHERE g.steamid = ? AND (g.not_free_to_play OR (ad.is_free = 0 AND COALESCE(ad.type, '') != ''))
+	`, steamID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	apps := make(map[int]bool)
+	for rows.Next() {
+		var appID int
+		if err := rows.Scan(&appID); err != nil {
+			return nil, err
+		}
+		apps[appID] = true
+	}
+	return apps, rows.Err()
+}
+
+// MarkLocalOnly records that games were only read from the local client,
+// so a Web API fetch that lacks them may tombstone them.
+func (d *DB) MarkLocalOnly(steamID string, appIDs []int) error {
+	return d.setLocalOnly(steamID, appIDs, true)
+}
+
+// ClearLocalOnly records that a Web API fetch returned games.
+func (d *DB) ClearLocalOnly(steamID string, appIDs []int) error {
+	return d.setLocalOnly(steamID, appIDs, false)
+}
+
+func (d *DB) setLocalOnly(steamID string, appIDs []int, localOnly bool) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`
+		UPDATE owned_games SET local_only = ?
+		WHERE steamid = ? AND appid = ? AND local_only != ?
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, id := range appIDs {
+		if _, err := stmt.Exec(localOnly, steamID, id, localOnly); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetLocalOnlyApps returns the games marked by MarkLocalOnly.
+func (d *DB) GetLocalOnlyApps(steamID string) (map[int]bool, error) {
+	rows, err := d.Query("SELECT appid FROM owned_games WHERE steamid = ? AND local_only", steamID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	apps := make(map[int]bool)
+	for rows.Next() {
+		var appID int
+		if err := rows.Scan(&appID); err != nil {
+			return nil, err
+		}
+		apps[appID] = true
+	}
+	return apps, rows.Err()
+}
+
+func (d *DB) GetLastUpdate(steamID string) (time.Time, error) {
 	var t time.Time
-	err := d.QueryRow("SELECT MAX(updated_at) FROM owned_games").Scan(&t)
+	err := d.QueryRow("SELECT MAX(updated_at) FROM owned_games WHERE steamid = ?", steamID).Scan(&t)
 	if err != nil {
 		return time.Time{}, err
 	}
 	return t, nil
 }
 
-func (d *DB) GetGamesMissingDetails() ([]model.Game, error) {
+func (d *DB) GetGamesMissingDetails(steamID string) ([]model.Game, error) {
 	rows, err := d.Query(`
 		SELECT g.appid, g.name, g.playtime_forever, g.rtime_last_played
 		FROM owned_games g
 		LEFT JOIN app_details ad ON g.appid = ad.appid
-		WHERE ad.appid IS NULL
-	`)
+		WHERE g.steamid = ? AND g.removed_at IS NULL AND ad.appid IS NULL
+	`, steamID)
 	if err != nil {
 		return nil, err
 	}
@@ -143,14 +344,19 @@ func (d *DB) GetGamesMissingDetails() ([]model.Game, error) {
 	return games, nil
 }
 
-func (d *DB) GetGamesWithDetails() ([]model.GameDetails, error) {
+func (d *DB) GetGamesWithDetails(steamID string) ([]model.GameDetails, error) {
 	rows, err := d.Query(`
 		SELECT
 			g.appid, g.name, g.playtime_forever, g.rtime_last_played,
-			COALESCE(ad.genres, '[]'), COALESCE(ad.categories, '[]')
+			COALESCE(ad.genres, '[]'), COALESCE(ad.categories, '[]'), COALESCE(ad.platforms, '{}'), ad.appid IS NOT NULL,
+			COALESCE(ap.unlocked, 0), COALESCE(ap.total, 0), COALESCE(s.status, ''),
+			COALESCE(ad.classification, '')
 		FROM owned_games g
 		LEFT JOIN app_details ad ON g.appid = ad.appid
-	`)
+		LEFT JOIN achievement_progress ap ON ap.steamid = g.steamid AND ap.appid = g.appid
+		LEFT JOIN game_status s ON s.steamid = g.steamid AND s.appid = g.appid
+		WHERE g.steamid = ? AND g.removed_at IS NULL
+	`, steamID)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +365,7 @@ func (d *DB) GetGamesWithDetails() ([]model.GameDetails, error) {
 	var games []model.GameDetails
 	for rows.Next() {
 		var g model.GameDetails
-		if err := rows.Scan(&g.AppID, &g.Name, &g.PlaytimeForever, &g.RTimeLastPlayed, &g.Genres, &g.Categories); err != nil {
+		if err := rows.Scan(&g.AppID, &g.Name, &g.PlaytimeForever, &g.RTimeLastPlayed, &g.Genres, &g.Categories, &g.Platforms, &g.HasDetails, &g.AchievementsUnlocked, &g.AchievementsTotal, &g.Status, &g.Classification); err != nil {
 			return nil, err
 		}
 		games = append(games, g)
@@ -167,81 +373,288 @@ func (d *DB) GetGamesWithDetails() ([]model.GameDetails, error) {
 	return games, nil
 }
 
+// UpsertAppDetails stores a Steam Store appdetails response. It does not
+// record field sources; enrich uses UpsertMergedAppDetails.
 func (d *DB) UpsertAppDetails(appID int, details model.AppDetailsResponse) error {
 	key := fmt.Sprintf("%d", appID)
 	data, ok := details[key]
 
-	var name, shortDesc, detailedDesc, about, header, website, categories, genres string
+	var a *model.AppDetails
+	if ok && data.Success {
+		a = &data.Data
+	}
 
-	toJSON := func(v interface{}) string {
-		b, _ := json.Marshal(v)
-		return string(b)
+	tx, err := d.Begin()
+	if err != nil {
+		return err
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	if ok && data.Success {
-		dDetails := data.Data
-		name = dDetails.Name
-		shortDesc = dDetails.ShortDescription
-		detailedDesc = dDetails.DetailedDescription
-		about = dDetails.AboutTheGame
-		header = dDetails.HeaderImage
-		website = dDetails.Website
-		categories = toJSON(dDetails.Categories)
-		genres = toJSON(dDetails.Genres)
-	} else {
-		// If success is false, it means the game is delisted or unavailable.
-		// We insert a stub record so we don't keep trying to fetch it.
-		name = "Unavailable"
-		categories = "[]"
-		genres = "[]"
+	if err := upsertAppDetails(tx, appID, a); err != nil {
+		return err
 	}
+	return tx.Commit()
+}
 
+// UpsertMergedAppDetails stores details merged from several sources and
+// which source supplied each field, replacing the previous sources. Nil
+// details store the unavailable stub, but never over stored details.
+func (d *DB) UpsertMergedAppDetails(appID int, details *model.AppDetails, sources map[string]string) error {
 	tx, err := d.Begin()
 	if err != nil {
 		return err
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	_, err = tx.Exec(`
+	if details == nil {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM app_details WHERE appid = ?)", appID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+	}
+
+	if err := upsertAppDetails(tx, appID, details); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM app_detail_sources WHERE appid = ?", appID); err != nil {
+		return err
+	}
+	for field, source := range sources {
+		if _, err := tx.Exec("INSERT INTO app_detail_sources (appid, field, source) VALUES (?, ?, ?)", appID, field, source); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetAppDetailSources returns which source supplied each stored field of an
+// app's details. Details stored without sources give an empty map.
+func (d *DB) GetAppDetailSources(appID int) (map[string]string, error) {
+	rows, err := d.Query("SELECT field, source FROM app_detail_sources WHERE appid = ?", appID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	sources := map[string]string{}
+	for rows.Next() {
+		var field, source string
+		if err := rows.Scan(&field, &source); err != nil {
+			return nil, err
+		}
+		sources[field] = source
+	}
+	return sources, rows.Err()
+}
+
+func upsertAppDetails(tx *sql.Tx, appID int, details *model.AppDetails) error {
+	var a model.AppDetails
+	if details != nil {
+		a = *details
+	} else {
+		// The game is delisted or unavailable. We insert a stub record so
+		// we don't keep trying to fetch it.
+		a = model.AppDetails{Name: "Unavailable"}
+	}
+	class := model.ClassifyApp(a)
+
+	// Absent objects are stored as NULL rather than the JSON literal null.
+	toJSON := func(v interface{}) interface{} {
+		b, _ := json.Marshal(v)
+		if string(b) == "null" {
+			return nil
+		}
+		return string(b)
+	}
+	raw := func(m json.RawMessage) interface{} {
+		if len(m) == 0 {
+			return nil
+		}
+		return string(m)
+	}
+	text := func(s string) interface{} {
+		if s == "" {
+			return nil
+		}
+		return s
+	}
+	emptyList := func(v interface{}) interface{} {
+		if s := toJSON(v); s != nil {
+			return s
+		}
+		return "[]"
+	}
+
+	_, err := tx.Exec(`
 		INSERT INTO app_details (
-			appid, name, short_description, detailed_description, about_the_game,
-			header_image, website, categories, genres, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			appid, type, name, required_age, is_free, dlc, controller_support,
+			short_description, detailed_description, about_the_game, supported_languages,
+			header_image, website, background,
+			pc_requirements, mac_requirements, linux_requirements,
+			developers, publishers, fullgame, packages, price_overview, platforms, metacritic,
+			categories, genres, screenshots, movies, recommendations,
+			achievements, release_date, support_info, content_descriptors,
+			classification, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 		ON CONFLICT(appid) DO UPDATE SET
+			type=excluded.type,
 			name=excluded.name,
+			required_age=excluded.required_age,
+			is_free=excluded.is_free,
+			dlc=excluded.dlc,
+			controller_support=excluded.controller_support,
 			short_description=excluded.short_description,
 			detailed_description=excluded.detailed_description,
 			about_the_game=excluded.about_the_game,
+			supported_languages=excluded.supported_languages,
 			header_image=excluded.header_image,
 			website=excluded.website,
+			background=excluded.background,
+			pc_requirements=excluded.pc_requirements,
+			mac_requirements=excluded.mac_requirements,
+			linux_requirements=excluded.linux_requirements,
+			developers=excluded.developers,
+			publishers=excluded.publishers,
+			fullgame=excluded.fullgame,
+			packages=excluded.packages,
+			price_overview=excluded.price_overview,
+			platforms=excluded.platforms,
+			metacritic=excluded.metacritic,
 			categories=excluded.categories,
 			genres=excluded.genres,
+			screenshots=excluded.screenshots,
+			movies=excluded.movies,
+			recommendations=excluded.recommendations,
+			achievements=excluded.achievements,
+			release_date=excluded.release_date,
+			support_info=excluded.support_info,
+			content_descriptors=excluded.content_descriptors,
+			classification=excluded.classification,
 			updated_at=CURRENT_TIMESTAMP
 	`,
-		appID, name, shortDesc, detailedDesc, about,
-		header, website, categories, genres,
+		appID, a.Type, a.Name, text(a.RequiredAge.String()), a.IsFree, toJSON(a.DLC), a.ControllerSupport,
+		a.ShortDescription, a.DetailedDescription, a.AboutTheGame, text(a.SupportedLanguages),
+		a.HeaderImage, a.Website, a.Background,
+		raw(a.PCRequirements), raw(a.MacRequirements), raw(a.LinuxRequirements),
+		emptyList(a.Developers), emptyList(a.Publishers), toJSON(a.Fullgame), toJSON(a.Packages), toJSON(a.PriceOverview), toJSON(a.Platforms), toJSON(a.Metacritic),
+		emptyList(a.Categories), emptyList(a.Genres), emptyList(a.Screenshots), raw(a.Movies), toJSON(a.Recommendations),
+		toJSON(a.Achievements), toJSON(a.ReleaseDate), toJSON(a.SupportInfo), toJSON(a.ContentDescriptors),
+		string(class),
 	)
 	if err != nil {
 		return err
 	}
+	return indexAppDetails(tx, appID, a)
+}
 
-	return tx.Commit()
+// GetAppDetails returns the stored store details of an app, or nil when
+// enrich has not fetched it yet.
+func (d *DB) GetAppDetails(appID int) (*model.AppDetails, error) {
+	var a model.AppDetails
+	var typ, requiredAge, dlc, controller, short, detailed, about, languages, header, website, background sql.NullString
+	var isFree sql.NullBool
+	var pcReq, macReq, linuxReq, developers, publishers, fullgame, packages, price, platforms, metacritic sql.NullString
+	var categories, genres, screenshots, movies, recommendations, achievements sql.NullString
+	var releaseDate, supportInfo, contentDescriptors sql.NullString
+
+	err := d.QueryRow(`
+		SELECT type, name, required_age, is_free, dlc, controller_support,
+			short_description, detailed_description, about_the_game, supported_languages,
+			header_image, website, background,
+			pc_requirements, mac_requirements, linux_requirements,
+			developers, publishers, fullgame, packages, price_overview, platforms, metacritic,
+			categories, genres, screenshots, movies, recommendations,
+			achievements, release_date, support_info, content_descriptors
+		FROM app_details WHERE appid = ?
+	`, appID).Scan(
+		&typ, &a.Name, &requiredAge, &isFree, &dlc, &controller,
+		&short, &detailed, &about, &languages,
+		&header, &website, &background,
+		&pcReq, &macReq, &linuxReq,
+		&developers, &publishers, &fullgame, &packages, &price, &platforms, &metacritic,
+		&categories, &genres, &screenshots, &movies, &recommendations,
+		&achievements, &releaseDate, &supportInfo, &contentDescriptors,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.Type = typ.String
+	a.RequiredAge = json.Number(requiredAge.String)
+	a.IsFree = isFree.Bool
+	a.ControllerSupport = controller.String
+	a.ShortDescription = short.String
+	a.DetailedDescription = detailed.String
+	a.AboutTheGame = about.String
+	a.SupportedLanguages = languages.String
+	a.HeaderImage = header.String
+	a.Website = website.String
+	a.Background = background.String
+
+	raw := func(s sql.NullString) json.RawMessage {
+		if !s.Valid {
+			return nil
+		}
+		return json.RawMessage(s.String)
+	}
+	a.PCRequirements = raw(pcReq)
+	a.MacRequirements = raw(macReq)
+	a.LinuxRequirements = raw(linuxReq)
+	a.Movies = raw(movies)
+
+	fields := []struct {
+		src sql.NullString
+		dst interface{}
+	}{
+		{dlc, &a.DLC},
+		{developers, &a.Developers},
+		{publishers, &a.Publishers},
+		{fullgame, &a.Fullgame},
+		{packages, &a.Packages},
+		{price, &a.PriceOverview},
+		{platforms, &a.Platforms},
+		{metacritic, &a.Metacritic},
+		{categories, &a.Categories},
+		{genres, &a.Genres},
+		{screenshots, &a.Screenshots},
+		{recommendations, &a.Recommendations},
+		{achievements, &a.Achievements},
+		{releaseDate, &a.ReleaseDate},
+		{supportInfo, &a.SupportInfo},
+		{contentDescriptors, &a.ContentDescriptors},
+	}
+	for _, f := range fields {
+		if !f.src.Valid || f.src.String == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(f.src.String), f.dst); err != nil {
+			return nil, fmt.Errorf("decoding app %d details: %w", appID, err)
+		}
+	}
+
+	return &a, nil
 }
 
-func (d *DB) UpsertTasteProfile(key string, value string) error {
+func (d *DB) UpsertTasteProfile(steamID, key, value string) error {
 	_, err := d.Exec(`
-		INSERT INTO taste_profile (key, value, updated_at)
-		VALUES (?, ?, CURRENT_TIMESTAMP)
-		ON CONFLICT(key) DO UPDATE SET
+		INSERT INTO taste_profile (steamid, key, value, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(steamid, key) DO UPDATE SET
 			value=excluded.value,
 			updated_at=CURRENT_TIMESTAMP
-	`, key, value)
+	`, steamID, key, value)
 	return err
 }
 
-func (d *DB) GetTasteProfile(key string) (string, error) {
+func (d *DB) GetTasteProfile(steamID, key string) (string, error) {
 	var value string
-	err := d.QueryRow("SELECT value FROM taste_profile WHERE key = ?", key).Scan(&value)
+	err := d.QueryRow("SELECT value FROM taste_profile WHERE steamid = ? AND key = ?", steamID, key).Scan(&value)
 	if err != nil {
 		return "", err
 	}
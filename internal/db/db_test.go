@@ -2,8 +2,10 @@ package db_test
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/dajoen/steam-pick/internal/db"
@@ -11,6 +13,8 @@ import (
 	"github.com/mattn/go-sqlite3"
 )
 
+const testSteamID = "76561198000000000"
+
 func TestNewWithDSN(t *testing.T) {
 	// Test creating a new DB with an in-memory DSN
 	d, err := db.NewWithDSN(":memory:")
@@ -52,13 +56,13 @@ func TestUpsertGames(t *testing.T) {
 		},
 	}
 
-	err = d.UpsertGames(games)
+	err = d.UpsertGames(testSteamID, games)
 	if err != nil {
 		t.Fatalf("UpsertGames failed: %v", err)
 	}
 
 	// Verify games were inserted
-	storedGames, err := d.GetGamesWithDetails()
+	storedGames, err := d.GetGamesWithDetails(testSteamID)
 	if err != nil {
 		t.Fatalf("GetGamesWithDetails failed: %v", err)
 	}
@@ -131,6 +135,49 @@ func TestUpsertAppDetails(t *testing.T) {
 	}
 }
 
+func TestUpsertMergedAppDetails(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	details := &model.AppDetails{Type: "game", Name: "Portal 2", Genres: []model.Genre{{Description: "Puzzle"}}}
+	sources := map[string]string{"type": "steam", "name": "steam", "genres": "pcgw"}
+	if err := d.UpsertMergedAppDetails(620, details, sources); err != nil {
+		t.Fatalf("UpsertMergedAppDetails failed: %v", err)
+	}
+	got, err := d.GetAppDetailSources(620)
+	if err != nil {
+		t.Fatalf("GetAppDetailSources failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, sources) {
+		t.Errorf("sources: got %v, want %v", got, sources)
+	}
+	stored, err := d.GetAppDetails(620)
+	if err != nil || stored == nil || stored.Genres[0].Description != "Puzzle" {
+		t.Fatalf("GetAppDetails: got %+v, %v", stored, err)
+	}
+
+	// The stub never replaces stored details.
+	if err := d.UpsertMergedAppDetails(620, nil, nil); err != nil {
+		t.Fatalf("UpsertMergedAppDetails failed: %v", err)
+	}
+	if got, _ := d.GetAppDetailSources(620); !reflect.DeepEqual(got, sources) {
+		t.Errorf("sources after stub: got %v", got)
+	}
+	if stored, _ := d.GetAppDetails(620); stored == nil || stored.Name != "Portal 2" {
+		t.Errorf("details after stub: got %+v", stored)
+	}
+
+	if err := d.UpsertMergedAppDetails(400, nil, nil); err != nil {
+		t.Fatalf("UpsertMergedAppDetails failed: %v", err)
+	}
+	if stored, _ := d.GetAppDetails(400); stored == nil || stored.Name != "Unavailable" {
+		t.Errorf("stub: got %+v", stored)
+	}
+}
+
 func TestUpsertTasteProfile(t *testing.T) {
 	d, err := db.NewWithDSN(":memory:")
 	if err != nil {
@@ -141,13 +188,13 @@ func TestUpsertTasteProfile(t *testing.T) {
 	key := "user_123"
 	value := `{"likes": ["Action"], "dislikes": ["Strategy"]}`
 
-	err = d.UpsertTasteProfile(key, value)
+	err = d.UpsertTasteProfile(testSteamID, key, value)
 	if err != nil {
 		t.Fatalf("UpsertTasteProfile failed: %v", err)
 	}
 
 	// Verify retrieval
-	retrievedValue, err := d.GetTasteProfile(key)
+	retrievedValue, err := d.GetTasteProfile(testSteamID, key)
 	if err != nil {
 		t.Fatalf("GetTasteProfile failed: %v", err)
 	}
@@ -157,7 +204,7 @@ func TestUpsertTasteProfile(t *testing.T) {
 	}
 
 	// Test non-existent key
-	_, err = d.GetTasteProfile("non_existent")
+	_, err = d.GetTasteProfile(testSteamID, "non_existent")
 	if !errors.Is(err, sql.ErrNoRows) {
 		t.Errorf("Expected sql.ErrNoRows, got %v", err)
 	}
@@ -175,17 +222,17 @@ func TestUniqueConstraint(t *testing.T) {
 	}
 
 	// Insert once
-	if err := d.UpsertGames(games); err != nil {
+	if err := d.UpsertGames(testSteamID, games); err != nil {
 		t.Fatalf("First insert failed: %v", err)
 	}
 
 	// Insert again (should update, not fail)
 	games[0].Name = "Game 1 Updated"
-	if err := d.UpsertGames(games); err != nil {
+	if err := d.UpsertGames(testSteamID, games); err != nil {
 		t.Fatalf("Second insert failed: %v", err)
 	}
 
-	storedGames, _ := d.GetGamesWithDetails()
+	storedGames, _ := d.GetGamesWithDetails(testSteamID)
 	if len(storedGames) != 1 {
 		t.Errorf("Expected 1 game, got %d", len(storedGames))
 	}
@@ -206,13 +253,13 @@ func TestConcurrentAccess(t *testing.T) {
 	done := make(chan bool)
 	go func() {
 		for i := 0; i < 100; i++ {
-			_ = d.UpsertTasteProfile("key", fmt.Sprintf("value-%d", i))
+			_ = d.UpsertTasteProfile(testSteamID, "key", fmt.Sprintf("value-%d", i))
 		}
 		done <- true
 	}()
 	go func() {
 		for i := 0; i < 100; i++ {
-			_, _ = d.GetTasteProfile("key")
+			_, _ = d.GetTasteProfile(testSteamID, "key")
 		}
 		done <- true
 	}()
@@ -246,7 +293,7 @@ func TestGetGamesMissingDetails(t *testing.T) {
 		{AppID: 1, Name: "Game 1"},
 		{AppID: 2, Name: "Game 2"},
 	}
-	if err := d.UpsertGames(games); err != nil {
+	if err := d.UpsertGames(testSteamID, games); err != nil {
 		t.Fatalf("UpsertGames failed: %v", err)
 	}
 
@@ -262,7 +309,7 @@ func TestGetGamesMissingDetails(t *testing.T) {
 	}
 
 	// Should return Game 2
-	missing, err := d.GetGamesMissingDetails()
+	missing, err := d.GetGamesMissingDetails(testSteamID)
 	if err != nil {
 		t.Fatalf("GetGamesMissingDetails failed: %v", err)
 	}
@@ -274,3 +321,398 @@ func TestGetGamesMissingDetails(t *testing.T) {
 		t.Errorf("Expected Game 2 to be missing details, got Game %d", missing[0].AppID)
 	}
 }
+
+func TestAccountsAreIsolated(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	partner := "76561198000000001"
+	if err := d.UpsertGames(testSteamID, []model.Game{{AppID: 1, Name: "Game 1", PlaytimeForever: 100}}); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+	if err := d.UpsertGames(partner, []model.Game{{AppID: 1, Name: "Game 1", PlaytimeForever: 5}, {AppID: 2, Name: "Game 2"}}); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+
+	mine, err := d.GetOwnedGames(testSteamID)
+	if err != nil {
+		t.Fatalf("GetOwnedGames failed: %v", err)
+	}
+	if len(mine) != 1 || mine[0].PlaytimeForever != 100 {
+		t.Errorf("Expected own playtime to be untouched, got %+v", mine)
+	}
+
+	theirs, err := d.GetOwnedGames(partner)
+	if err != nil {
+		t.Fatalf("GetOwnedGames failed: %v", err)
+	}
+	if len(theirs) != 2 {
+		t.Errorf("Expected 2 games for partner, got %d", len(theirs))
+	}
+}
+
+func TestMigrationAssignsLegacyRows(t *testing.T) {
+	dsn := "file:" + t.TempDir() + "/legacy.db"
+
+	// Build a database as it looked after migration 1.
+	raw, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatalf("Failed to open DB: %v", err)
+	}
+	_, err = raw.Exec(`
+		CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, applied_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO schema_migrations (version) VALUES (1);
+		CREATE TABLE owned_games (appid INTEGER PRIMARY KEY, name TEXT, playtime_forever INTEGER, rtime_last_played INTEGER,
+			img_icon_url TEXT, has_community_visible_stats BOOLEAN, playtime_windows_forever INTEGER,
+			playtime_mac_forever INTEGER, playtime_linux_forever INTEGER, playtime_deck_forever INTEGER,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		CREATE TABLE app_details (appid INTEGER PRIMARY KEY, name TEXT, short_description TEXT, header_image TEXT, categories TEXT, genres TEXT);
+		CREATE TABLE taste_profile (key TEXT PRIMARY KEY, value TEXT, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		CREATE TABLE recommendations (appid INTEGER PRIMARY KEY, score REAL, reason TEXT, type TEXT, created_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO owned_games (appid, name, playtime_forever, rtime_last_played) VALUES (10, 'Counter-Strike', 1000, 0);
+		INSERT INTO taste_profile (key, value) VALUES ('genres', '[]');
+	`)
+	_ = raw.Close()
+	if err != nil {
+		t.Fatalf("Failed to build legacy schema: %v", err)
+	}
+
+	d, err := db.NewWithDSN(dsn, db.WithDefaultSteamID(testSteamID))
+	if err != nil {
+		t.Fatalf("Failed to migrate DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	games, err := d.GetOwnedGames(testSteamID)
+	if err != nil {
+		t.Fatalf("GetOwnedGames failed: %v", err)
+	}
+	if len(games) != 1 || games[0].PlaytimeForever != 1000 {
+		t.Errorf("Expected legacy game to be assigned to %s, got %+v", testSteamID, games)
+	}
+	if _, err := d.GetTasteProfile(testSteamID, "genres"); err != nil {
+		t.Errorf("Expected legacy taste profile to be assigned: %v", err)
+	}
+}
+
+func TestLegacyRowsClaimedOnceAccountIsKnown(t *testing.T) {
+	dsn := "file:" + t.TempDir() + "/legacy.db"
+
+	raw, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatalf("Failed to open DB: %v", err)
+	}
+	_, err = raw.Exec(`
+		CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, applied_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO schema_migrations (version) VALUES (1);
+		CREATE TABLE owned_games (appid INTEGER PRIMARY KEY, name TEXT, playtime_forever INTEGER, rtime_last_played INTEGER,
+			img_icon_url TEXT, has_community_visible_stats BOOLEAN, playtime_windows_forever INTEGER,
+			playtime_mac_forever INTEGER, playtime_linux_forever INTEGER, playtime_deck_forever INTEGER,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		CREATE TABLE app_details (appid INTEGER PRIMARY KEY, name TEXT, short_description TEXT, header_image TEXT, categories TEXT, genres TEXT);
+		CREATE TABLE taste_profile (key TEXT PRIMARY KEY, value TEXT, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		CREATE TABLE recommendations (appid INTEGER PRIMARY KEY, score REAL, reason TEXT, type TEXT, created_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO owned_games (appid, name, playtime_forever, rtime_last_played) VALUES (10, 'Counter-Strike', 1000, 0);
+	`)
+	_ = raw.Close()
+	if err != nil {
+		t.Fatalf("Failed to build legacy schema: %v", err)
+	}
+
+	// Migrating without an account leaves the rows unassigned.
+	d, err := db.NewWithDSN(dsn)
+	if err != nil {
+		t.Fatalf("Failed to migrate DB: %v", err)
+	}
+	_ = d.Close()
+
+	d, err = db.NewWithDSN(dsn, db.WithDefaultSteamID(testSteamID))
+	if err != nil {
+		t.Fatalf("Failed to open DB: %v", err)
+	}
+	games, err := d.GetOwnedGames(testSteamID)
+	if err != nil || len(games) != 1 {
+		t.Errorf("Expected the legacy game to be claimed, got %+v, %v", games, err)
+	}
+	// Rows stored without an account later are not claimed by the next open.
+	if _, err := d.Exec("UPDATE owned_games SET steamid = '' WHERE appid = 10"); err != nil {
+		t.Fatalf("Failed to unassign row: %v", err)
+	}
+	_ = d.Close()
+
+	d, err = db.NewWithDSN(dsn, db.WithDefaultSteamID("76561198000000001"))
+	if err != nil {
+		t.Fatalf("Failed to open DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+	if games, _ := d.GetOwnedGames("76561198000000001"); len(games) != 0 {
+		t.Errorf("Rows were claimed again on open: %+v", games)
+	}
+}
+
+func TestMarkGamesRemoved(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	games := []model.Game{
+		{AppID: 1, Name: "Kept"},
+		{AppID: 2, Name: "Refunded"},
+	}
+	if err := d.UpsertGames(testSteamID, games); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+	if err := d.MarkGamesRemoved(testSteamID, []int{2}); err != nil {
+		t.Fatalf("MarkGamesRemoved failed: %v", err)
+	}
+
+	owned, _ := d.GetOwnedGames(testSteamID)
+	if len(owned) != 1 || owned[0].AppID != 1 {
+		t.Errorf("Expected only game 1 to be owned, got %+v", owned)
+	}
+	detailed, _ := d.GetGamesWithDetails(testSteamID)
+	if len(detailed) != 1 {
+		t.Errorf("Expected removed game to be excluded from details, got %d games", len(detailed))
+	}
+	missing, _ := d.GetGamesMissingDetails(testSteamID)
+	if len(missing) != 1 {
+		t.Errorf("Expected removed game to be excluded from enrichment, got %d games", len(missing))
+	}
+
+	// Re-adding the game clears the tombstone
+	if err := d.UpsertGames(testSteamID, games[1:]); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+	owned, _ = d.GetOwnedGames(testSteamID)
+	if len(owned) != 2 {
+		t.Errorf("Expected re-added game to be owned again, got %d games", len(owned))
+	}
+}
+
+func TestAppDetailsRoundTrip(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	payload := `{"620": {"success": true, "data": {
+		"type": "game",
+		"name": "Portal 2",
+		"required_age": "18",
+		"is_free": false,
+		"dlc": [630, 631],
+		"controller_support": "full",
+		"short_description": "Puzzle game",
+		"supported_languages": "English<strong>*</strong>",
+		"pc_requirements": {"minimum": "<strong>Minimum:</strong>"},
+		"mac_requirements": [],
+		"developers": ["Valve"],
+		"publishers": ["Valve"],
+		"fullgame": {"appid": "600", "name": "Portal"},
+		"packages": [7877],
+		"price_overview": {"currency": "EUR", "initial": 819, "final": 163, "discount_percent": 80, "final_formatted": "1,63€"},
+		"platforms": {"windows": true, "mac": false, "linux": true},
+		"metacritic": {"score": 95, "url": "https://www.metacritic.com/game/pc/portal-2"},
+		"categories": [{"id": 2, "description": "Single-player"}],
+		"genres": [{"id": "1", "description": "Action"}],
+		"recommendations": {"total": 300000},
+		"achievements": {"total": 51},
+		"release_date": {"coming_soon": false, "date": "18 Apr, 2011"},
+		"content_descriptors": {"ids": [], "notes": null}
+	}}}`
+
+	var response model.AppDetailsResponse
+	if err := json.Unmarshal([]byte(payload), &response); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+	if err := d.UpsertAppDetails(620, response); err != nil {
+		t.Fatalf("UpsertAppDetails failed: %v", err)
+	}
+
+	a, err := d.GetAppDetails(620)
+	if err != nil {
+		t.Fatalf("GetAppDetails failed: %v", err)
+	}
+	if a == nil {
+		t.Fatal("expected stored details")
+	}
+	if a.Type != "game" || a.IsFree || a.ControllerSupport != "full" {
+		t.Errorf("unexpected basics: %+v", a)
+	}
+	if a.Metacritic == nil || a.Metacritic.Score != 95 {
+		t.Errorf("expected metacritic score 95, got %+v", a.Metacritic)
+	}
+	if a.PriceOverview == nil || a.PriceOverview.Final != 163 || a.PriceOverview.DiscountPercent != 80 {
+		t.Errorf("unexpected price: %+v", a.PriceOverview)
+	}
+	if !a.Platforms.Windows || a.Platforms.Mac || !a.Platforms.Linux {
+		t.Errorf("unexpected platforms: %+v", a.Platforms)
+	}
+	if len(a.Developers) != 1 || a.Developers[0] != "Valve" {
+		t.Errorf("unexpected developers: %v", a.Developers)
+	}
+	if string(a.MacRequirements) != "[]" || len(a.PCRequirements) == 0 {
+		t.Errorf("unexpected requirements: pc=%s mac=%s", a.PCRequirements, a.MacRequirements)
+	}
+	if a.Recommendations == nil || a.Recommendations.Total != 300000 || a.Achievements.Total != 51 {
+		t.Errorf("unexpected counts: %+v %+v", a.Recommendations, a.Achievements)
+	}
+	if a.ReleaseDate.Date != "18 Apr, 2011" {
+		t.Errorf("unexpected release date: %+v", a.ReleaseDate)
+	}
+	if a.RequiredAge != "18" || a.SupportedLanguages != "English<strong>*</strong>" {
+		t.Errorf("unexpected age or languages: %q %q", a.RequiredAge, a.SupportedLanguages)
+	}
+	if !reflect.DeepEqual(a.DLC, []int{630, 631}) || !reflect.DeepEqual(a.Packages, []int{7877}) {
+		t.Errorf("unexpected dlc or packages: %v %v", a.DLC, a.Packages)
+	}
+	if a.Fullgame == nil || a.Fullgame.AppID != "600" || a.Fullgame.Name != "Portal" {
+		t.Errorf("unexpected fullgame: %+v", a.Fullgame)
+	}
+
+	missing, err := d.GetAppDetails(1)
+	if err != nil || missing != nil {
+		t.Errorf("expected nil for unknown app, got %+v, %v", missing, err)
+	}
+}
+
+func TestAppClassification(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	if err := d.UpsertGames(testSteamID, []model.Game{
+		{AppID: 1, Name: "Game"},
+		{AppID: 2, Name: "Expansion"},
+		{AppID: 3, Name: "Level Editor"},
+		{AppID: 4, Name: "Not Enriched"},
+	}); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+	for appID, data := range map[int]model.AppDetails{
+		1: {Type: "game", Genres: []model.Genre{{Description: "Action"}}},
+		2: {Type: "dlc"},
+		3: {Type: "game", Genres: []model.Genre{{Description: "Utilities"}, {Description: "Game Development"}}},
+	} {
+		response := model.AppDetailsResponse{fmt.Sprintf("%d", appID): {Success: true, Data: data}}
+		if err := d.UpsertAppDetails(appID, response); err != nil {
+			t.Fatalf("UpsertAppDetails failed: %v", err)
+		}
+	}
+
+	games, err := d.GetOwnedGames(testSteamID)
+	if err != nil {
+		t.Fatalf("GetOwnedGames failed: %v", err)
+	}
+	want := map[int]model.AppClass{1: model.ClassGame, 2: model.ClassDLC, 3: model.ClassTool, 4: ""}
+	for _, g := range games {
+		if g.Classification != want[g.AppID] {
+			t.Errorf("app %d: got class %q, want %q", g.AppID, g.Classification, want[g.AppID])
+		}
+	}
+
+	classes, err := d.GetClassifications()
+	if err != nil {
+		t.Fatalf("GetClassifications failed: %v", err)
+	}
+	if len(classes) != 3 || classes[2] != model.ClassDLC {
+		t.Errorf("unexpected classifications: %v", classes)
+	}
+}
+
+func TestReclassifyAppsReadsOnlyClassificationColumns(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	for appID, data := range map[int]model.AppDetails{
+		1: {Type: "game", Genres: []model.Genre{{Description: "Action"}}},
+		2: {Type: "game", Categories: []model.Category{{Description: "Downloadable Content"}}},
+	} {
+		response := model.AppDetailsResponse{fmt.Sprintf("%d", appID): {Success: true, Data: data}}
+		if err := d.UpsertAppDetails(appID, response); err != nil {
+			t.Fatalf("UpsertAppDetails failed: %v", err)
+		}
+	}
+	// Migration 9 runs the reclassification against an older schema, so it
+	// must not read columns beyond the ones it classifies by.
+	if _, err := d.Exec("UPDATE app_details SET classification = NULL"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Exec("ALTER TABLE app_details DROP COLUMN screenshots"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.ReclassifyApps(); err != nil {
+		t.Fatalf("ReclassifyApps failed: %v", err)
+	}
+	classes, err := d.GetClassifications()
+	if err != nil {
+		t.Fatalf("GetClassifications failed: %v", err)
+	}
+	if classes[1] != model.ClassGame || classes[2] != model.ClassDLC {
+		t.Errorf("unexpected classifications: %v", classes)
+	}
+}
+
+func TestPCGWPlaceholdersCleared(t *testing.T) {
+	dsn := "file:" + t.TempDir() + "/placeholders.db"
+	d, err := db.NewWithDSN(dsn)
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	if err := d.UpsertGames(testSteamID, []model.Game{{AppID: 10, Name: "Counter-Strike"}}); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+	response := model.AppDetailsResponse{"10": {Success: true, Data: model.AppDetails{
+		Name:                "Fetched from PCGamingWiki",
+		ShortDescription:    "Data fetched from PCGamingWiki.",
+		DetailedDescription: "Data fetched from PCGamingWiki because Steam Store page is unavailable.",
+		Genres:              []model.Genre{{Description: "Action"}},
+	}}}
+	if err := d.UpsertAppDetails(10, response); err != nil {
+		t.Fatalf("UpsertAppDetails failed: %v", err)
+	}
+	if err := d.UpsertEmbedding(10, "nomic", "h1", []float32{1}); err != nil {
+		t.Fatalf("UpsertEmbedding failed: %v", err)
+	}
+	// Run the migration again, as on a database from before it, undoing
+	// the later ones.
+	if _, err := d.Exec("DELETE FROM schema_migrations WHERE version >= 22; ALTER TABLE owned_games DROP COLUMN local_only"); err != nil {
+		t.Fatal(err)
+	}
+	_ = d.Close()
+
+	d, err = db.NewWithDSN(dsn)
+	if err != nil {
+		t.Fatalf("Failed to migrate DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	a, err := d.GetAppDetails(10)
+	if err != nil || a == nil {
+		t.Fatalf("GetAppDetails: got %+v, %v", a, err)
+	}
+	if a.Name != "Counter-Strike" || a.ShortDescription != "" || a.DetailedDescription != "" || len(a.Genres) != 1 {
+		t.Errorf("placeholders not cleared: %+v", a)
+	}
+	if embeddings, _ := d.GetEmbeddings("nomic"); len(embeddings) != 0 {
+		t.Errorf("expected the placeholder embedding to be dropped, got %v", embeddings)
+	}
+	if hits, _ := d.SearchKeywords(testSteamID, "PCGamingWiki", 10); len(hits) != 0 {
+		t.Errorf("placeholder text still indexed: %+v", hits)
+	}
+	if hits, _ := d.SearchKeywords(testSteamID, "counter", 10); len(hits) != 1 {
+		t.Errorf("expected the library name to be indexed, got %+v", hits)
+	}
+}
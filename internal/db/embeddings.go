@@ -0,0 +1,85 @@
+package db
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// UpsertEmbedding stores the embedding of an app for an embedding model.
+// textHash identifies the text that was embedded so unchanged apps can be
+// skipped.
+func (d *DB) UpsertEmbedding(appID int, model, textHash string, vector []float32) error {
+	_, err := d.Exec(`
+		INSERT INTO game_embeddings (appid, model, text_hash, vector, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(appid, model) DO UPDATE SET
+			text_hash=excluded.text_hash,
+			vector=excluded.vector,
+			updated_at=CURRENT_TIMESTAMP
+	`, appID, model, textHash, encodeVector(vector))
+	return err
+}
+
+// GetEmbeddingHashes returns the text hash of every app embedded with model.
+func (d *DB) GetEmbeddingHashes(model string) (map[int]string, error) {
+	rows, err := d.Query("SELECT appid, text_hash FROM game_embeddings WHERE model = ?", model)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	hashes := make(map[int]string)
+	for rows.Next() {
+		var appID int
+		var hash string
+		if err := rows.Scan(&appID, &hash); err != nil {
+			return nil, err
+		}
+		hashes[appID] = hash
+	}
+	return hashes, rows.Err()
+}
+
+// GetEmbeddings returns every embedding stored for model.
+func (d *DB) GetEmbeddings(model string) (map[int][]float32, error) {
+	rows, err := d.Query("SELECT appid, vector FROM game_embeddings WHERE model = ?", model)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	vectors := make(map[int][]float32)
+	for rows.Next() {
+		var appID int
+		var blob []byte
+		if err := rows.Scan(&appID, &blob); err != nil {
+			return nil, err
+		}
+		v, err := decodeVector(blob)
+		if err != nil {
+			return nil, fmt.Errorf("embedding for %d: %w", appID, err)
+		}
+		vectors[appID] = v
+	}
+	return vectors, rows.Err()
+}
+
+func encodeVector(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(f))
+	}
+	return b
+}
+
+func decodeVector(b []byte) ([]float32, error) {
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("invalid vector length %d", len(b))
+	}
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v, nil
+}
@@ -0,0 +1,39 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/dajoen/steam-pick/internal/db"
+)
+
+func TestEmbeddings(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	if err := d.UpsertEmbedding(10, "nomic", "h1", []float32{0.5, -1.25, 3}); err != nil {
+		t.Fatalf("UpsertEmbedding failed: %v", err)
+	}
+	if err := d.UpsertEmbedding(10, "other", "h2", []float32{1}); err != nil {
+		t.Fatalf("UpsertEmbedding failed: %v", err)
+	}
+
+	vectors, err := d.GetEmbeddings("nomic")
+	if err != nil {
+		t.Fatalf("GetEmbeddings failed: %v", err)
+	}
+	v := vectors[10]
+	if len(vectors) != 1 || len(v) != 3 || v[0] != 0.5 || v[1] != -1.25 || v[2] != 3 {
+		t.Errorf("unexpected vectors: %v", vectors)
+	}
+
+	hashes, err := d.GetEmbeddingHashes("other")
+	if err != nil {
+		t.Fatalf("GetEmbeddingHashes failed: %v", err)
+	}
+	if hashes[10] != "h2" {
+		t.Errorf("got hash %q, want h2", hashes[10])
+	}
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// ReplaceInstalledGames stores the result of a local scan in place of the
+// previous one.
+func (d *DB) ReplaceInstalledGames(games []model.InstalledGame, at time.Time) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM installed_games"); err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO installed_games (
+			appid, name, library_path, install_dir, size_on_disk, last_updated, scanned_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, g := range games {
+		var lastUpdated interface{}
+		if !g.LastUpdated.IsZero() {
+			lastUpdated = g.LastUpdated.UTC()
+		}
+		if _, err := stmt.Exec(g.AppID, g.Name, g.LibraryPath, g.InstallDir, g.SizeOnDisk, lastUpdated, at.UTC()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetInstalledGames returns the games found by the last scan, by name.
+func (d *DB) GetInstalledGames() ([]model.InstalledGame, error) {
+	rows, err := d.Query(`
+		SELECT appid, name, library_path, install_dir, size_on_disk, last_updated
+		FROM installed_games
+		ORDER BY name COLLATE NOCASE, appid
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var games []model.InstalledGame
+	for rows.Next() {
+		var g model.InstalledGame
+		var lastUpdated sql.NullTime
+		if err := rows.Scan(&g.AppID, &g.Name, &g.LibraryPath, &g.InstallDir, &g.SizeOnDisk, &lastUpdated); err != nil {
+			return nil, err
+		}
+		if lastUpdated.Valid {
+			g.LastUpdated = lastUpdated.Time
+		}
+		games = append(games, g)
+	}
+	return games, rows.Err()
+}
+
+// GetInstalledApps returns the AppIDs found by the last scan.
+func (d *DB) GetInstalledApps() (map[int]bool, error) {
+	rows, err := d.Query("SELECT appid FROM installed_games")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	installed := map[int]bool{}
+	for rows.Next() {
+		var appID int
+		if err := rows.Scan(&appID); err != nil {
+			return nil, err
+		}
+		installed[appID] = true
+	}
+	return installed, rows.Err()
+}
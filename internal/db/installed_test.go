@@ -0,0 +1,49 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func TestInstalledGames(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	now := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	updated := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	if err := d.ReplaceInstalledGames([]model.InstalledGame{
+		{AppID: 620, Name: "Portal 2", LibraryPath: "/lib", InstallDir: "/lib/steamapps/common/Portal 2", SizeOnDisk: 100, LastUpdated: updated},
+		{AppID: 70, Name: "Half-Life", LibraryPath: "/lib", InstallDir: "/lib/steamapps/common/Half-Life"},
+	}, now); err != nil {
+		t.Fatalf("ReplaceInstalledGames failed: %v", err)
+	}
+
+	games, err := d.GetInstalledGames()
+	if err != nil {
+		t.Fatalf("GetInstalledGames failed: %v", err)
+	}
+	if len(games) != 2 || games[0].AppID != 70 || !games[0].LastUpdated.IsZero() {
+		t.Fatalf("Expected 2 games by name, got %+v", games)
+	}
+	if games[1].SizeOnDisk != 100 || !games[1].LastUpdated.Equal(updated) || games[1].InstallDir != "/lib/steamapps/common/Portal 2" {
+		t.Errorf("Unexpected Portal 2 row: %+v", games[1])
+	}
+
+	// A new scan replaces the old one.
+	if err := d.ReplaceInstalledGames([]model.InstalledGame{{AppID: 400, Name: "Portal"}}, now); err != nil {
+		t.Fatalf("ReplaceInstalledGames failed: %v", err)
+	}
+	installed, err := d.GetInstalledApps()
+	if err != nil {
+		t.Fatalf("GetInstalledApps failed: %v", err)
+	}
+	if len(installed) != 1 || !installed[400] {
+		t.Errorf("Expected only 400 installed, got %v", installed)
+	}
+}
@@ -1,10 +1,16 @@
 package db
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
 type migration struct {
 	version int
 	up      string
+	// after runs once the SQL in up has been applied, for data changes that
+	// depend on runtime configuration.
+	after func(d *DB) error
 }
 
 var migrations = []migration{
@@ -65,6 +71,439 @@ var migrations = []migration{
 		);
 		`,
 	},
+	{
+		// Per-user tables are keyed by SteamID64 so several accounts can
+		// share one database. Existing rows are copied with an empty steamid
+		// and assigned to the configured account afterwards.
+		version: 2,
+		up: `
+		CREATE TABLE owned_games_v2 (
+			steamid TEXT NOT NULL DEFAULT '',
+			appid INTEGER NOT NULL,
+			name TEXT,
+			playtime_forever INTEGER,
+			rtime_last_played INTEGER,
+			img_icon_url TEXT,
+			has_community_visible_stats BOOLEAN,
+			playtime_windows_forever INTEGER,
+			playtime_mac_forever INTEGER,
+			playtime_linux_forever INTEGER,
+			playtime_deck_forever INTEGER,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (steamid, appid)
+		);
+		INSERT INTO owned_games_v2 (
+			steamid, appid, name, playtime_forever, rtime_last_played, img_icon_url,
+			has_community_visible_stats, playtime_windows_forever, playtime_mac_forever,
+			playtime_linux_forever, playtime_deck_forever, updated_at
+		)
+		SELECT
+			'', appid, name, playtime_forever, rtime_last_played, img_icon_url,
+			has_community_visible_stats, playtime_windows_forever, playtime_mac_forever,
+			playtime_linux_forever, playtime_deck_forever, updated_at
+		FROM owned_games;
+		DROP TABLE owned_games;
+		ALTER TABLE owned_games_v2 RENAME TO owned_games;
+
+		CREATE TABLE taste_profile_v2 (
+			steamid TEXT NOT NULL DEFAULT '',
+			key TEXT NOT NULL,
+			value TEXT, -- JSON
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (steamid, key)
+		);
+		INSERT INTO taste_profile_v2 (steamid, key, value, updated_at)
+		SELECT '', key, value, updated_at FROM taste_profile;
+		DROP TABLE taste_profile;
+		ALTER TABLE taste_profile_v2 RENAME TO taste_profile;
+
+		CREATE TABLE recommendations_v2 (
+			steamid TEXT NOT NULL DEFAULT '',
+			appid INTEGER NOT NULL,
+			score REAL,
+			reason TEXT,
+			type TEXT, -- 'backlog' or 'discovery'
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (steamid, appid)
+		);
+		INSERT INTO recommendations_v2 (steamid, appid, score, reason, type, created_at)
+		SELECT '', appid, score, reason, type, created_at FROM recommendations;
+		DROP TABLE recommendations;
+		ALTER TABLE recommendations_v2 RENAME TO recommendations;
+		`,
+		after: func(d *DB) error {
+			return d.ClaimUnassignedRows(d.defaultSteamID)
+		},
+	},
+	{
+		version: 3,
+		up: `
+		CREATE TABLE IF NOT EXISTS playtime_snapshots (
+			steamid TEXT NOT NULL,
+			appid INTEGER NOT NULL,
+			taken_at DATETIME NOT NULL,
+			playtime_forever INTEGER,
+			previous_playtime_forever INTEGER, -- NULL when the game was new to this sync
+			playtime_windows_forever INTEGER,
+			playtime_mac_forever INTEGER,
+			playtime_linux_forever INTEGER,
+			playtime_deck_forever INTEGER
+		);
+		CREATE INDEX IF NOT EXISTS idx_playtime_snapshots_account
+			ON playtime_snapshots (steamid, appid, taken_at);
+		`,
+	},
+	{
+		// Games that disappear from GetOwnedGames (refunds, revoked licenses,
+		// family sharing) are tombstoned rather than deleted.
+		version: 4,
+		up: `
+		ALTER TABLE owned_games ADD COLUMN removed_at DATETIME;
+		`,
+	},
+	{
+		version: 5,
+		up: `
+		CREATE TABLE IF NOT EXISTS achievement_progress (
+			steamid TEXT NOT NULL,
+			appid INTEGER NOT NULL,
+			unlocked INTEGER NOT NULL,
+			total INTEGER NOT NULL,
+			last_unlocked_at DATETIME,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (steamid, appid)
+		);
+		CREATE TABLE IF NOT EXISTS achievement_unlocks (
+			steamid TEXT NOT NULL,
+			appid INTEGER NOT NULL,
+			apiname TEXT NOT NULL,
+			display_name TEXT,
+			unlocked_at DATETIME,
+			PRIMARY KEY (steamid, appid, apiname)
+		);
+		`,
+	},
+	{
+		version: 6,
+		up: `
+		CREATE TABLE IF NOT EXISTS game_status (
+			steamid TEXT NOT NULL,
+			appid INTEGER NOT NULL,
+			status TEXT NOT NULL, -- 'backlog', 'playing', 'beaten' or 'dropped'
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (steamid, appid)
+		);
+		CREATE TABLE IF NOT EXISTS game_status_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			steamid TEXT NOT NULL,
+			appid INTEGER NOT NULL,
+			from_status TEXT,
+			to_status TEXT NOT NULL,
+			changed_at DATETIME NOT NULL
+		);
+		`,
+	},
+	{
+		version: 7,
+		up: `
+		CREATE TABLE IF NOT EXISTS pick_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			steamid TEXT NOT NULL,
+			appid INTEGER NOT NULL,
+			picked_at DATETIME NOT NULL,
+			seed INTEGER,
+			flags TEXT -- JSON object of the flags set on the command line
+		);
+		CREATE INDEX IF NOT EXISTS idx_pick_history_account
+			ON pick_history (steamid, picked_at);
+		CREATE TABLE IF NOT EXISTS pick_exclusions (
+			steamid TEXT NOT NULL,
+			appid INTEGER NOT NULL,
+			kind TEXT NOT NULL, -- 'snooze' or 'reject'
+			until DATETIME, -- NULL for rejections
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (steamid, appid)
+		);
+		`,
+	},
+	{
+		version: 8,
+		up: `
+		ALTER TABLE app_details ADD COLUMN type TEXT;
+		ALTER TABLE app_details ADD COLUMN is_free BOOLEAN;
+		ALTER TABLE app_details ADD COLUMN metacritic TEXT; -- JSON
+		ALTER TABLE app_details ADD COLUMN controller_support TEXT;
+		`,
+	},
+	{
+		version: 9,
+		up: `
+		ALTER TABLE app_details ADD COLUMN classification TEXT;
+		`,
+		after: func(d *DB) error {
+			return d.ReclassifyApps()
+		},
+	},
+	{
+		version: 10,
+		up: `
+		CREATE TABLE IF NOT EXISTS game_embeddings (
+			appid INTEGER NOT NULL,
+			model TEXT NOT NULL,
+			text_hash TEXT NOT NULL, -- SHA-256 of the embedded text
+			vector BLOB NOT NULL, -- little-endian float32s
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (appid, model)
+		);
+		`,
+	},
+	{
+		version: 11,
+		// The keyword index depends on whether SQLite was built with FTS5,
+		// so ensureSearchIndex creates it.
+		after: func(d *DB) error {
+			return d.ensureSearchIndex()
+		},
+	},
+	{
+		// recommendations was never written, so it is rebuilt to hold the
+		// results of every recommend run.
+		version: 12,
+		up: `
+		CREATE TABLE IF NOT EXISTS recommendation_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			steamid TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			strategy TEXT NOT NULL,
+			mode TEXT NOT NULL,
+			model TEXT, -- LLM model, empty when none was used
+			profile_version TEXT -- hash of the taste profile the run used
+		);
+		CREATE INDEX IF NOT EXISTS idx_recommendation_runs_account
+			ON recommendation_runs (steamid, created_at);
+		DROP TABLE recommendations;
+		CREATE TABLE recommendations (
+			run_id INTEGER NOT NULL,
+			steamid TEXT NOT NULL DEFAULT '',
+			rank INTEGER NOT NULL,
+			appid INTEGER NOT NULL,
+			name TEXT,
+			score REAL,
+			reason TEXT,
+			PRIMARY KEY (run_id, appid)
+		);
+		CREATE TABLE IF NOT EXISTS explanation_cache (
+			steamid TEXT NOT NULL,
+			appid INTEGER NOT NULL,
+			model TEXT NOT NULL,
+			profile_version TEXT NOT NULL,
+			game_version TEXT NOT NULL, -- hash of the game details in the prompt
+			explanation TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (steamid, appid, model, profile_version, game_version)
+		);
+		`,
+	},
+	{
+		version: 13,
+		up: `
+		CREATE TABLE IF NOT EXISTS chat_sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			steamid TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL,
+			title TEXT -- first user message
+		);
+		CREATE TABLE IF NOT EXISTS chat_messages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id INTEGER NOT NULL,
+			role TEXT NOT NULL,
+			content TEXT,
+			tool_calls TEXT, -- JSON array
+			tool_call_id TEXT,
+			name TEXT,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_chat_messages_session
+			ON chat_messages (session_id, id);
+		`,
+	},
+	{
+		version: 14,
+		up: `
+		CREATE TABLE IF NOT EXISTS game_notes (
+			steamid TEXT NOT NULL,
+			appid INTEGER NOT NULL,
+			note TEXT NOT NULL,
+			updated_at DATETIME NOT NULL,
+			PRIMARY KEY (steamid, appid)
+		);
+		`,
+	},
+	{
+		// Installs belong to the machine, not to an account.
+		version: 15,
+		up: `
+		CREATE TABLE IF NOT EXISTS installed_games (
+			appid INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			library_path TEXT NOT NULL,
+			install_dir TEXT NOT NULL,
+			size_on_disk INTEGER NOT NULL,
+			last_updated DATETIME,
+			scanned_at DATETIME NOT NULL
+		);
+		`,
+	},
+	{
+		version: 16,
+		up: `
+		CREATE TABLE IF NOT EXISTS tags (
+			steamid TEXT NOT NULL,
+			name TEXT NOT NULL COLLATE NOCASE,
+			source TEXT NOT NULL,
+			collection_id TEXT,
+			created_at DATETIME NOT NULL,
+			PRIMARY KEY (steamid, name)
+		);
+		CREATE TABLE IF NOT EXISTS game_tags (
+			steamid TEXT NOT NULL,
+			tag TEXT NOT NULL COLLATE NOCASE,
+			appid INTEGER NOT NULL,
+			PRIMARY KEY (steamid, tag, appid)
+		);
+		`,
+	},
+	{
+		version: 17,
+		up: `
+		CREATE TABLE IF NOT EXISTS app_detail_sources (
+			appid INTEGER NOT NULL,
+			field TEXT NOT NULL,
+			source TEXT NOT NULL,
+			PRIMARY KEY (appid, field)
+		);
+		`,
+	},
+	{
+		// Rows migration 2 could not assign, because no account was
+		// configured yet, are marked so the first open with an account
+		// claims them once.
+		version: 18,
+		up: `
+		CREATE TABLE IF NOT EXISTS meta (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		);
+		INSERT OR IGNORE INTO meta (key, value)
+		SELECT 'unclaimed_rows', '1'
+		WHERE EXISTS (SELECT 1 FROM owned_games WHERE steamid = '')
+			OR EXISTS (SELECT 1 FROM taste_profile WHERE steamid = '')
+			OR EXISTS (SELECT 1 FROM recommendations WHERE steamid = '');
+		`,
+	},
+	{
+		// Set once a fetch without free-to-play games returned the game.
+		version: 19,
+		up: `
+		ALTER TABLE owned_games ADD COLUMN not_free_to_play BOOLEAN NOT NULL DEFAULT 0;
+		`,
+	},
+	{
+		version: 20,
+		up: `
+		ALTER TABLE app_details ADD COLUMN required_age TEXT;
+		ALTER TABLE app_details ADD COLUMN dlc TEXT; -- JSON array
+		ALTER TABLE app_details ADD COLUMN supported_languages TEXT;
+		ALTER TABLE app_details ADD COLUMN fullgame TEXT; -- JSON
+		ALTER TABLE app_details ADD COLUMN packages TEXT; -- JSON array
+		`,
+	},
+	{
+		// Collections of deleted tags, until export deletes them in Steam.
+		version: 21,
+		up: `
+		CREATE TABLE IF NOT EXISTS deleted_collections (
+			steamid TEXT NOT NULL,
+			collection_id TEXT NOT NULL,
+			deleted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (steamid, collection_id)
+		);
+		`,
+	},
+	{
+		// The PCGamingWiki fallback used to store placeholder text. Clear
+		// it, name the apps after the library, drop their embeddings and
+		// drop the keyword index, which is rebuilt on open.
+		version: 22,
+		after:   clearPCGWPlaceholders,
+	},
+	{
+		// Set while a game is only known from the local client, until a
+		// Web API fetch returns it.
+		version: 23,
+		up: `
+		ALTER TABLE owned_games ADD COLUMN local_only BOOLEAN NOT NULL DEFAULT 0;
+		`,
+	},
+}
+
+// pcgwPlaceholders are the texts the PCGamingWiki fallback stored before
+// migration 22, with what replaces them.
+var pcgwPlaceholders = []struct {
+	column, text, replacement string
+}{
+	{"name", "Fetched from PCGamingWiki", "COALESCE((SELECT g.name FROM owned_games g WHERE g.appid = app_details.appid LIMIT 1), '')"},
+	{"short_description", "Data fetched from PCGamingWiki.", "''"},
+	{"detailed_description", "Data fetched from PCGamingWiki because Steam Store page is unavailable.", "''"},
+}
+
+func clearPCGWPlaceholders(d *DB) error {
+	columns, err := d.columns("app_details")
+	if err != nil {
+		return err
+	}
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, p := range pcgwPlaceholders {
+		// Databases from before the full payload may lack a column.
+		if !columns[p.column] {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM game_embeddings WHERE appid IN (SELECT appid FROM app_details WHERE "+p.column+" = ?)", p.text); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE app_details SET "+p.column+" = "+p.replacement+" WHERE "+p.column+" = ?", p.text); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("DROP TABLE IF EXISTS app_search"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// columns returns the column names of a table.
+func (d *DB) columns(table string) (map[string]bool, error) {
+	rows, err := d.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
 }
 
 func (d *DB) migrate() error {
@@ -85,9 +524,17 @@ func (d *DB) migrate() error {
 
 	for _, m := range migrations {
 		if m.version > currentVersion {
-			fmt.Printf("Applying migration %d...\n", m.version)
-			if _, err := d.Exec(m.up); err != nil {
-				return fmt.Errorf("migration %d failed: %w", m.version, err)
+			// Progress goes to stderr so it never mixes with JSON output.
+			fmt.Fprintf(os.Stderr, "Applying migration %d...\n", m.version)
+			if m.up != "" {
+				if _, err := d.Exec(m.up); err != nil {
+					return fmt.Errorf("migration %d failed: %w", m.version, err)
+				}
+			}
+			if m.after != nil {
+				if err := m.after(d); err != nil {
+					return fmt.Errorf("migration %d failed: %w", m.version, err)
+				}
 			}
 			if _, err := d.Exec("INSERT INTO schema_migrations (version) VALUES (?)", m.version); err != nil {
 				return fmt.Errorf("failed to record migration %d: %w", m.version, err)
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// GetGameNote returns the note of a game, or "" when there is none.
+func (d *DB) GetGameNote(steamID string, appID int) (string, error) {
+	var note string
+	err := d.QueryRow("SELECT note FROM game_notes WHERE steamid = ? AND appid = ?", steamID, appID).Scan(&note)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return note, err
+}
+
+// SetGameNote stores the note of a game. An empty note deletes it.
+func (d *DB) SetGameNote(steamID string, appID int, note string, at time.Time) error {
+	if strings.TrimSpace(note) == "" {
+		_, err := d.Exec("DELETE FROM game_notes WHERE steamid = ? AND appid = ?", steamID, appID)
+		return err
+	}
+	_, err := d.Exec(`
+		INSERT INTO game_notes (steamid, appid, note, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(steamid, appid) DO UPDATE SET
+			note=excluded.note,
+			updated_at=excluded.updated_at
+	`, steamID, appID, note, at)
+	return err
+}
@@ -0,0 +1,46 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func TestGameNotes(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	if err := d.UpsertGames(testSteamID, []model.Game{{AppID: 1, Name: "Game 1"}}); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+	now := time.Now()
+	if err := d.SetGameNote(testSteamID, 1, "Co-op with Sam", now); err != nil {
+		t.Fatalf("SetGameNote failed: %v", err)
+	}
+	if err := d.SetGameNote(testSteamID, 1, "Co-op with Alex", now); err != nil {
+		t.Fatalf("SetGameNote failed: %v", err)
+	}
+
+	games, err := d.GetOwnedGames(testSteamID)
+	if err != nil {
+		t.Fatalf("GetOwnedGames failed: %v", err)
+	}
+	if len(games) != 1 || games[0].Note != "Co-op with Alex" {
+		t.Errorf("unexpected games: %+v", games)
+	}
+	if note, err := d.GetGameNote("76561198000000001", 1); err != nil || note != "" {
+		t.Errorf("note of another account: got %q, %v", note, err)
+	}
+
+	if err := d.SetGameNote(testSteamID, 1, "  ", now); err != nil {
+		t.Fatalf("SetGameNote failed: %v", err)
+	}
+	if note, err := d.GetGameNote(testSteamID, 1); err != nil || note != "" {
+		t.Errorf("blank note should delete: got %q, %v", note, err)
+	}
+}
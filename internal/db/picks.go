@@ -0,0 +1,96 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// RecordPick stores a pick together with the seed and flags that produced it.
+func (d *DB) RecordPick(steamID string, appID int, seed int64, flags map[string]string, at time.Time) error {
+	flagsJSON, err := json.Marshal(flags)
+	if err != nil {
+		return err
+	}
+	_, err = d.Exec(`
+		INSERT INTO pick_history (steamid, appid, picked_at, seed, flags)
+		VALUES (?, ?, ?, ?, ?)
+	`, steamID, appID, at.UTC(), seed, string(flagsJSON))
+	return err
+}
+
+// GetPicksSince returns the picks made at or after since, newest first.
+func (d *DB) GetPicksSince(steamID string, since time.Time) ([]model.PickRecord, error) {
+	rows, err := d.Query(`
+		SELECT p.appid, COALESCE(g.name, ''), p.picked_at, COALESCE(p.seed, 0), COALESCE(p.flags, '{}')
+		FROM pick_history p
+		LEFT JOIN owned_games g ON g.steamid = p.steamid AND g.appid = p.appid
+		WHERE p.steamid = ? AND p.picked_at >= ?
+		ORDER BY p.picked_at DESC, p.id DESC
+	`, steamID, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var picks []model.PickRecord
+	for rows.Next() {
+		var p model.PickRecord
+		var flags string
+		if err := rows.Scan(&p.AppID, &p.Name, &p.PickedAt, &p.Seed, &flags); err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal([]byte(flags), &p.Flags)
+		picks = append(picks, p)
+	}
+	return picks, rows.Err()
+}
+
+// SnoozeGame keeps a game out of picks until the given time.
+func (d *DB) SnoozeGame(steamID string, appID int, until time.Time) error {
+	_, err := d.Exec(`
+		INSERT INTO pick_exclusions (steamid, appid, kind, until, created_at)
+		VALUES (?, ?, 'snooze', ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(steamid, appid) DO UPDATE SET
+			kind=excluded.kind,
+			until=excluded.until,
+			created_at=CURRENT_TIMESTAMP
+	`, steamID, appID, until.UTC())
+	return err
+}
+
+// RejectGame keeps a game out of picks for good.
+func (d *DB) RejectGame(steamID string, appID int) error {
+	_, err := d.Exec(`
+		INSERT INTO pick_exclusions (steamid, appid, kind, until, created_at)
+		VALUES (?, ?, 'reject', NULL, CURRENT_TIMESTAMP)
+		ON CONFLICT(steamid, appid) DO UPDATE SET
+			kind=excluded.kind,
+			until=NULL,
+			created_at=CURRENT_TIMESTAMP
+	`, steamID, appID)
+	return err
+}
+
+// GetExcludedApps returns the games that are rejected or snoozed past now.
+func (d *DB) GetExcludedApps(steamID string, now time.Time) (map[int]bool, error) {
+	rows, err := d.Query(`
+		SELECT appid FROM pick_exclusions
+		WHERE steamid = ? AND (kind = 'reject' OR until > ?)
+	`, steamID, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	excluded := make(map[int]bool)
+	for rows.Next() {
+		var appID int
+		if err := rows.Scan(&appID); err != nil {
+			return nil, err
+		}
+		excluded[appID] = true
+	}
+	return excluded, rows.Err()
+}
@@ -0,0 +1,63 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func TestPickHistoryAndExclusions(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	if err := d.UpsertGames(testSteamID, []model.Game{{AppID: 1, Name: "Game 1"}}); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+
+	now := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	if err := d.RecordPick(testSteamID, 1, 42, map[string]string{"turn-based-only": "true"}, now.Add(-48*time.Hour)); err != nil {
+		t.Fatalf("RecordPick failed: %v", err)
+	}
+	if err := d.RecordPick(testSteamID, 2, 43, nil, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("RecordPick failed: %v", err)
+	}
+
+	picks, err := d.GetPicksSince(testSteamID, now.Add(-72*time.Hour))
+	if err != nil {
+		t.Fatalf("GetPicksSince failed: %v", err)
+	}
+	if len(picks) != 2 || picks[0].AppID != 2 {
+		t.Fatalf("Expected 2 picks newest first, got %+v", picks)
+	}
+	if picks[1].Seed != 42 || picks[1].Flags["turn-based-only"] != "true" || picks[1].Name != "Game 1" {
+		t.Errorf("Unexpected pick record: %+v", picks[1])
+	}
+
+	recent, _ := d.GetPicksSince(testSteamID, now.Add(-24*time.Hour))
+	if len(recent) != 1 {
+		t.Errorf("Expected 1 pick in the last day, got %d", len(recent))
+	}
+
+	if err := d.SnoozeGame(testSteamID, 3, now.Add(24*time.Hour)); err != nil {
+		t.Fatalf("SnoozeGame failed: %v", err)
+	}
+	if err := d.SnoozeGame(testSteamID, 4, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("SnoozeGame failed: %v", err)
+	}
+	if err := d.RejectGame(testSteamID, 5); err != nil {
+		t.Fatalf("RejectGame failed: %v", err)
+	}
+
+	excluded, err := d.GetExcludedApps(testSteamID, now)
+	if err != nil {
+		t.Fatalf("GetExcludedApps failed: %v", err)
+	}
+	if !excluded[3] || excluded[4] || !excluded[5] || len(excluded) != 2 {
+		t.Errorf("Expected games 3 and 5 to be excluded, got %v", excluded)
+	}
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// SaveRecommendationRun stores a recommend run and its results and returns
+// the run ID.
+func (d *DB) SaveRecommendationRun(steamID string, run model.RecommendationRun) (int64, error) {
+	tx, err := d.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`
+		INSERT INTO recommendation_runs (steamid, created_at, strategy, mode, model, profile_version)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, steamID, run.CreatedAt, run.Strategy, run.Mode, run.Model, run.ProfileVersion)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO recommendations (run_id, steamid, rank, appid, name, score, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for i, r := range run.Results {
+		if _, err := stmt.Exec(id, steamID, i+1, r.AppID, r.Name, r.Score, r.Reason); err != nil {
+			return 0, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+// GetRecommendationRuns returns the latest runs of an account, newest
+// first, without their results.
+func (d *DB) GetRecommendationRuns(steamID string, limit int) ([]model.RecommendationRun, error) {
+	rows, err := d.Query(`
+		SELECT id, created_at, strategy, mode, COALESCE(model, ''), COALESCE(profile_version, '')
+		FROM recommendation_runs
+		WHERE steamid = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, steamID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var runs []model.RecommendationRun
+	for rows.Next() {
+		var r model.RecommendationRun
+		if err := rows.Scan(&r.ID, &r.CreatedAt, &r.Strategy, &r.Mode, &r.Model, &r.ProfileVersion); err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// GetRecommendationRun returns a run with its results, or nil when the
+// account has no run with that ID.
+func (d *DB) GetRecommendationRun(steamID string, id int64) (*model.RecommendationRun, error) {
+	var r model.RecommendationRun
+	err := d.QueryRow(`
+		SELECT id, created_at, strategy, mode, COALESCE(model, ''), COALESCE(profile_version, '')
+		FROM recommendation_runs
+		WHERE steamid = ? AND id = ?
+	`, steamID, id).Scan(&r.ID, &r.CreatedAt, &r.Strategy, &r.Mode, &r.Model, &r.ProfileVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.Query(`
+		SELECT appid, COALESCE(name, ''), score, COALESCE(reason, '')
+		FROM recommendations
+		WHERE run_id = ?
+		ORDER BY rank
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var res model.RecommendationResult
+		if err := rows.Scan(&res.AppID, &res.Name, &res.Score, &res.Reason); err != nil {
+			return nil, err
+		}
+		r.Results = append(r.Results, res)
+	}
+	return &r, rows.Err()
+}
+
+// ExplanationKey identifies an explanation: it can be reused while the
+// model, the taste profile and the game's details are unchanged.
+type ExplanationKey struct {
+	AppID          int
+	Model          string
+	ProfileVersion string
+	GameVersion    string
+}
+
+// GetCachedExplanation returns the explanation generated earlier for key.
+func (d *DB) GetCachedExplanation(steamID string, key ExplanationKey) (string, bool, error) {
+	var explanation string
+	err := d.QueryRow(`
+		SELECT explanation FROM explanation_cache
+		WHERE steamid = ? AND appid = ? AND model = ? AND profile_version = ? AND game_version = ?
+	`, steamID, key.AppID, key.Model, key.ProfileVersion, key.GameVersion).Scan(&explanation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return explanation, true, nil
+}
+
+// CacheExplanation stores an explanation for reuse by later runs.
+func (d *DB) CacheExplanation(steamID string, key ExplanationKey, explanation string) error {
+	_, err := d.Exec(`
+		INSERT INTO explanation_cache (steamid, appid, model, profile_version, game_version, explanation, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(steamid, appid, model, profile_version, game_version) DO UPDATE SET
+			explanation=excluded.explanation,
+			created_at=CURRENT_TIMESTAMP
+	`, steamID, key.AppID, key.Model, key.ProfileVersion, key.GameVersion, explanation)
+	return err
+}
@@ -0,0 +1,76 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func TestRecommendationRuns(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first, err := d.SaveRecommendationRun(testSteamID, model.RecommendationRun{
+		CreatedAt: base, Strategy: "genre", Mode: "backlog", ProfileVersion: "v1",
+		Results: []model.RecommendationResult{{AppID: 2, Name: "B", Score: 9}, {AppID: 1, Name: "A", Score: 5, Reason: "Because"}},
+	})
+	if err != nil {
+		t.Fatalf("SaveRecommendationRun failed: %v", err)
+	}
+	if _, err := d.SaveRecommendationRun(testSteamID, model.RecommendationRun{
+		CreatedAt: base.Add(time.Hour), Strategy: "llm-rerank", Mode: "backlog", Model: "llama3",
+	}); err != nil {
+		t.Fatalf("SaveRecommendationRun failed: %v", err)
+	}
+
+	runs, err := d.GetRecommendationRuns(testSteamID, 10)
+	if err != nil {
+		t.Fatalf("GetRecommendationRuns failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Strategy != "llm-rerank" || runs[1].ID != first {
+		t.Errorf("unexpected runs: %+v", runs)
+	}
+
+	run, err := d.GetRecommendationRun(testSteamID, first)
+	if err != nil {
+		t.Fatalf("GetRecommendationRun failed: %v", err)
+	}
+	if run == nil || len(run.Results) != 2 || run.Results[0].AppID != 2 || run.Results[1].Reason != "Because" {
+		t.Errorf("unexpected run: %+v", run)
+	}
+
+	other, err := d.GetRecommendationRun("76561198000000001", first)
+	if err != nil || other != nil {
+		t.Errorf("runs must not leak across accounts, got %+v, %v", other, err)
+	}
+}
+
+func TestExplanationCache(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	key := db.ExplanationKey{AppID: 10, Model: "llama3", ProfileVersion: "p1", GameVersion: "g1"}
+	if _, ok, err := d.GetCachedExplanation(testSteamID, key); err != nil || ok {
+		t.Fatalf("expected a cache miss, got ok=%v err=%v", ok, err)
+	}
+	if err := d.CacheExplanation(testSteamID, key, "You like tactics."); err != nil {
+		t.Fatalf("CacheExplanation failed: %v", err)
+	}
+	if got, ok, _ := d.GetCachedExplanation(testSteamID, key); !ok || got != "You like tactics." {
+		t.Errorf("got %q, %v; want the cached explanation", got, ok)
+	}
+
+	key.ProfileVersion = "p2"
+	if _, ok, _ := d.GetCachedExplanation(testSteamID, key); ok {
+		t.Error("a changed profile must not reuse the explanation")
+	}
+}
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// SearchHit is a game matched by a keyword search. Higher scores are
+// better matches.
+type SearchHit struct {
+	AppID int
+	Name  string
+	Score float64
+}
+
+// indexAppDetails replaces the keyword index entry of an app. The index
+// rowid is the appid.
+func indexAppDetails(tx *sql.Tx, appID int, a model.AppDetails) error {
+	if _, err := tx.Exec("DELETE FROM app_search WHERE rowid = ?", appID); err != nil {
+		return err
+	}
+	var genres, categories []string
+	for _, g := range a.Genres {
+		genres = append(genres, g.Description)
+	}
+	for _, c := range a.Categories {
+		categories = append(categories, c.Description)
+	}
+	_, err := tx.Exec(
+		"INSERT INTO app_search (rowid, name, description, genres, categories) VALUES (?, ?, ?, ?, ?)",
+		appID, a.Name, a.ShortDescription, strings.Join(genres, " "), strings.Join(categories, " "),
+	)
+	return err
+}
+
+// ensureSearchIndex creates the keyword index as an FTS5 table when SQLite
+// supports it (build with -tags sqlite_fts5) and as a plain table otherwise,
+// rebuilding it when the kind changes.
+func (d *DB) ensureSearchIndex() error {
+	var fts bool
+	if err := d.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts); err != nil {
+		return err
+	}
+
+	var schema string
+	err := d.QueryRow("SELECT sql FROM sqlite_master WHERE name = 'app_search'").Scan(&schema)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	d.fts = fts
+	if err == nil && strings.Contains(strings.ToLower(schema), "fts5") == fts {
+		return nil
+	}
+
+	if _, err := d.Exec("DROP TABLE IF EXISTS app_search"); err != nil {
+		return err
+	}
+	create := `CREATE TABLE app_search (
+		appid INTEGER PRIMARY KEY, name TEXT, description TEXT, genres TEXT, categories TEXT
+	)`
+	if fts {
+		create = `CREATE VIRTUAL TABLE app_search USING fts5(
+			name, description, genres, categories,
+			tokenize = 'porter unicode61'
+		)`
+	}
+	if _, err := d.Exec(create); err != nil {
+		return err
+	}
+	return d.RebuildSearchIndex()
+}
+
+// RebuildSearchIndex rebuilds the keyword index from the stored app details.
+func (d *DB) RebuildSearchIndex() error {
+	rows, err := d.Query(`
+		SELECT appid, COALESCE(name, ''), COALESCE(short_description, ''),
+			COALESCE(genres, '[]'), COALESCE(categories, '[]')
+		FROM app_details
+	`)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		appID int
+		a     model.AppDetails
+	}
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		var genres, categories string
+		if err := rows.Scan(&e.appID, &e.a.Name, &e.a.ShortDescription, &genres, &categories); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		_ = json.Unmarshal([]byte(genres), &e.a.Genres)
+		_ = json.Unmarshal([]byte(categories), &e.a.Categories)
+		entries = append(entries, e)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec("DELETE FROM app_search"); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := indexAppDetails(tx, e.appID, e.a); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchKeywords ranks the account's games against the words of text with
+// the keyword index. Any word may match; games matching more and rarer words
+// rank higher.
+func (d *DB) SearchKeywords(steamID, text string, limit int) ([]SearchHit, error) {
+	if !d.fts {
+		return d.searchPlain(steamID, text, limit)
+	}
+	match := ftsQuery(text)
+	if match == "" {
+		return nil, nil
+	}
+	rows, err := d.Query(`
+		SELECT g.appid, g.name, -bm25(app_search, 2.0, 1.0, 1.5, 1.5) AS score
+		FROM app_search
+		JOIN owned_games g ON g.appid = app_search.rowid
+		WHERE app_search MATCH ? AND g.steamid = ? AND g.removed_at IS NULL
+		ORDER BY score DESC
+		LIMIT ?
+	`, match, steamID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var hits []SearchHit
+	for rows.Next() {
+		var h SearchHit
+		if err := rows.Scan(&h.AppID, &h.Name, &h.Score); err != nil {
+			return nil, err
+		}
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+// searchPlain scores the plain keyword index in Go for SQLite builds without
+// FTS5: each query word found in the name, genres, categories or
+// description adds that column's weight.
+func (d *DB) searchPlain(steamID, text string, limit int) ([]SearchHit, error) {
+	words := queryWords(text)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	rows, err := d.Query(`
+		SELECT g.appid, g.name, s.name, s.description, s.genres, s.categories
+		FROM app_search s
+		JOIN owned_games g ON g.appid = s.appid
+		WHERE g.steamid = ? AND g.removed_at IS NULL
+	`, steamID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var hits []SearchHit
+	for rows.Next() {
+		var h SearchHit
+		var name, desc, genres, categories sql.NullString
+		if err := rows.Scan(&h.AppID, &h.Name, &name, &desc, &genres, &categories); err != nil {
+			return nil, err
+		}
+		columns := []struct {
+			text   string
+			weight float64
+		}{
+			{name.String, 2}, {genres.String, 1.5}, {categories.String, 1.5}, {desc.String, 1},
+		}
+		for _, w := range words {
+			for _, c := range columns {
+				if strings.Contains(strings.ToLower(c.text), w) {
+					h.Score += c.weight
+				}
+			}
+		}
+		if h.Score > 0 {
+			hits = append(hits, h)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func queryWords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return words
+}
+
+// ftsQuery turns free text into an FTS5 query that ORs its quoted words, so
+// punctuation in the text cannot break the query syntax.
+func ftsQuery(text string) string {
+	words := queryWords(text)
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, `"`+w+`"`)
+	}
+	return strings.Join(terms, " OR ")
+}
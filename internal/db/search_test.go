@@ -0,0 +1,48 @@
+package db_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func TestSearchKeywords(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	if err := d.UpsertGames(testSteamID, []model.Game{
+		{AppID: 1, Name: "Stardew Valley"},
+		{AppID: 2, Name: "Doom"},
+		{AppID: 3, Name: "Farming Simulator"},
+	}); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+	for appID, a := range map[int]model.AppDetails{
+		1: {Name: "Stardew Valley", ShortDescription: "A cozy farming game with crafting.", Categories: []model.Category{{Description: "Online Co-op"}}},
+		2: {Name: "Doom", ShortDescription: "Rip and tear.", Genres: []model.Genre{{Description: "Action"}}},
+		3: {Name: "Farming Simulator", ShortDescription: "Drive tractors."},
+		4: {Name: "Not Owned", ShortDescription: "A farming game with crafting."},
+	} {
+		if err := d.UpsertAppDetails(appID, model.AppDetailsResponse{fmt.Sprintf("%d", appID): {Success: true, Data: a}}); err != nil {
+			t.Fatalf("UpsertAppDetails failed: %v", err)
+		}
+	}
+
+	hits, err := d.SearchKeywords(testSteamID, `cozy "farming" game, with crafting & co-op`, 10)
+	if err != nil {
+		t.Fatalf("SearchKeywords failed: %v", err)
+	}
+	if len(hits) != 2 || hits[0].AppID != 1 || hits[1].AppID != 3 {
+		t.Errorf("got %+v, want Stardew Valley then Farming Simulator", hits)
+	}
+
+	hits, err = d.SearchKeywords(testSteamID, "!!!", 10)
+	if err != nil || len(hits) != 0 {
+		t.Errorf("expected no hits for a query without words, got %+v, %v", hits, err)
+	}
+}
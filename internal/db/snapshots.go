@@ -0,0 +1,86 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// RecordPlaytimeSnapshots appends one snapshot row per game. previous maps
+// appids to the playtime stored before this sync; games missing from it are
+// recorded as new.
+func (d *DB) RecordPlaytimeSnapshots(steamID string, takenAt time.Time, games []model.Game, previous map[int]int) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO playtime_snapshots (
+			steamid, appid, taken_at, playtime_forever, previous_playtime_forever,
+			playtime_windows_forever, playtime_mac_forever,
+			playtime_linux_forever, playtime_deck_forever
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, g := range games {
+		var prev sql.NullInt64
+		if p, ok := previous[g.AppID]; ok {
+			prev = sql.NullInt64{Int64: int64(p), Valid: true}
+		}
+		_, err := stmt.Exec(
+			steamID, g.AppID, takenAt.UTC(), g.PlaytimeForever, prev,
+			g.PlaytimeWindowsForever, g.PlaytimeMacForever,
+			g.PlaytimeLinuxForever, g.PlaytimeDeckForever,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPlaytimeSnapshots returns the snapshots for an account ordered by time.
+// An appID of 0 returns snapshots for every game.
+func (d *DB) GetPlaytimeSnapshots(steamID string, appID int) ([]model.PlaytimeSnapshot, error) {
+	rows, err := d.Query(`
+		SELECT
+			s.appid, COALESCE(g.name, ''), s.taken_at, s.playtime_forever, s.previous_playtime_forever,
+			s.playtime_windows_forever, s.playtime_mac_forever,
+			s.playtime_linux_forever, s.playtime_deck_forever
+		FROM playtime_snapshots s
+		LEFT JOIN owned_games g ON g.steamid = s.steamid AND g.appid = s.appid
+		WHERE s.steamid = ? AND (? = 0 OR s.appid = ?)
+		ORDER BY s.taken_at, s.appid
+	`, steamID, appID, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var snapshots []model.PlaytimeSnapshot
+	for rows.Next() {
+		var s model.PlaytimeSnapshot
+		var prev sql.NullInt64
+		if err := rows.Scan(
+			&s.AppID, &s.Name, &s.TakenAt, &s.PlaytimeForever, &prev,
+			&s.PlaytimeWindowsForever, &s.PlaytimeMacForever,
+			&s.PlaytimeLinuxForever, &s.PlaytimeDeckForever,
+		); err != nil {
+			return nil, err
+		}
+		if prev.Valid {
+			p := int(prev.Int64)
+			s.PreviousPlaytime = &p
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
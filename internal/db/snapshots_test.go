@@ -0,0 +1,60 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func TestPlaytimeSnapshots(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	games := []model.Game{
+		{AppID: 1, Name: "Game 1", PlaytimeForever: 30, PlaytimeLinuxForever: 30},
+		{AppID: 2, Name: "Game 2", PlaytimeForever: 10},
+	}
+	if err := d.UpsertGames(testSteamID, games); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+
+	takenAt := time.Date(2025, 1, 2, 20, 0, 0, 0, time.UTC)
+	if err := d.RecordPlaytimeSnapshots(testSteamID, takenAt, games, map[int]int{1: 0}); err != nil {
+		t.Fatalf("RecordPlaytimeSnapshots failed: %v", err)
+	}
+
+	all, err := d.GetPlaytimeSnapshots(testSteamID, 0)
+	if err != nil {
+		t.Fatalf("GetPlaytimeSnapshots failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(all))
+	}
+	if all[0].Name != "Game 1" || all[0].Delta() != 30 || all[0].PlaytimeLinuxForever != 30 {
+		t.Errorf("Unexpected snapshot for Game 1: %+v", all[0])
+	}
+	if all[1].PreviousPlaytime != nil || all[1].Delta() != 0 {
+		t.Errorf("Expected Game 2 to be recorded as new, got %+v", all[1])
+	}
+	if !all[0].TakenAt.Equal(takenAt) {
+		t.Errorf("Expected taken_at %v, got %v", takenAt, all[0].TakenAt)
+	}
+
+	one, err := d.GetPlaytimeSnapshots(testSteamID, 2)
+	if err != nil {
+		t.Fatalf("GetPlaytimeSnapshots failed: %v", err)
+	}
+	if len(one) != 1 || one[0].AppID != 2 {
+		t.Errorf("Expected only Game 2, got %+v", one)
+	}
+
+	other, _ := d.GetPlaytimeSnapshots("76561198000000001", 0)
+	if len(other) != 0 {
+		t.Errorf("Expected no snapshots for another account, got %d", len(other))
+	}
+}
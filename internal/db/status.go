@@ -0,0 +1,100 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// GetGameStatus returns the status of a game, or "" when none was set.
+func (d *DB) GetGameStatus(steamID string, appID int) (model.Status, error) {
+	var status model.Status
+	err := d.QueryRow("SELECT status FROM game_status WHERE steamid = ? AND appid = ?", steamID, appID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return status, err
+}
+
+// GetGameStatuses returns every explicitly set status for an account.
+func (d *DB) GetGameStatuses(steamID string) (map[int]model.Status, error) {
+	rows, err := d.Query("SELECT appid, status FROM game_status WHERE steamid = ?", steamID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	statuses := make(map[int]model.Status)
+	for rows.Next() {
+		var appID int
+		var status model.Status
+		if err := rows.Scan(&appID, &status); err != nil {
+			return nil, err
+		}
+		statuses[appID] = status
+	}
+	return statuses, rows.Err()
+}
+
+// SetGameStatus stores a new status and records the transition. Transition
+// rules are up to the caller.
+func (d *DB) SetGameStatus(steamID string, appID int, status model.Status, at time.Time) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var from sql.NullString
+	err = tx.QueryRow("SELECT status FROM game_status WHERE steamid = ? AND appid = ?", steamID, appID).Scan(&from)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO game_status (steamid, appid, status, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(steamid, appid) DO UPDATE SET
+			status=excluded.status,
+			updated_at=excluded.updated_at
+	`, steamID, appID, status, at.UTC())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO game_status_history (steamid, appid, from_status, to_status, changed_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, steamID, appID, from, status, at.UTC())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetStatusHistory returns the status transitions of a game, oldest first.
+func (d *DB) GetStatusHistory(steamID string, appID int) ([]model.StatusChange, error) {
+	rows, err := d.Query(`
+		SELECT appid, COALESCE(from_status, ''), to_status, changed_at
+		FROM game_status_history
+		WHERE steamid = ? AND appid = ?
+		ORDER BY changed_at, id
+	`, steamID, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var changes []model.StatusChange
+	for rows.Next() {
+		var c model.StatusChange
+		if err := rows.Scan(&c.AppID, &c.From, &c.To, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
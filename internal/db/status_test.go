@@ -0,0 +1,58 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func TestGameStatus(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	if err := d.UpsertGames(testSteamID, []model.Game{{AppID: 1, Name: "Game 1", PlaytimeForever: 20}}); err != nil {
+		t.Fatalf("UpsertGames failed: %v", err)
+	}
+
+	status, err := d.GetGameStatus(testSteamID, 1)
+	if err != nil || status != "" {
+		t.Fatalf("Expected no status, got %q (%v)", status, err)
+	}
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := d.SetGameStatus(testSteamID, 1, model.StatusPlaying, start); err != nil {
+		t.Fatalf("SetGameStatus failed: %v", err)
+	}
+	if err := d.SetGameStatus(testSteamID, 1, model.StatusDropped, start.Add(time.Hour)); err != nil {
+		t.Fatalf("SetGameStatus failed: %v", err)
+	}
+
+	games, _ := d.GetOwnedGames(testSteamID)
+	if len(games) != 1 || games[0].Status != model.StatusDropped {
+		t.Errorf("Expected owned game to be dropped, got %+v", games)
+	}
+
+	history, err := d.GetStatusHistory(testSteamID, 1)
+	if err != nil {
+		t.Fatalf("GetStatusHistory failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 transitions, got %d", len(history))
+	}
+	if history[0].From != "" || history[0].To != model.StatusPlaying {
+		t.Errorf("Unexpected first transition: %+v", history[0])
+	}
+	if history[1].From != model.StatusPlaying || history[1].To != model.StatusDropped || !history[1].ChangedAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("Unexpected second transition: %+v", history[1])
+	}
+
+	statuses, _ := d.GetGameStatuses(testSteamID)
+	if statuses[1] != model.StatusDropped {
+		t.Errorf("Expected status map to contain dropped, got %v", statuses)
+	}
+}
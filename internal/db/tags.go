@@ -0,0 +1,214 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// ErrSteamTag is returned when the games of a tag imported from a Steam
+// collection are changed; the next import would undo the change.
+var ErrSteamTag = errors.New("tag is imported from a Steam collection")
+
+// AddTag adds games to a tag, creating it as a steam-pick tag when needed.
+func (d *DB) AddTag(steamID, tag string, appIDs []int, at time.Time) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkNotSteamTag(tx, steamID, tag); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO tags (steamid, name, source, created_at) VALUES (?, ?, ?, ?)
+	`, steamID, tag, model.TagSourceUser, at.UTC()); err != nil {
+		return err
+	}
+	if err := insertTagged(tx, steamID, tag, appIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveTag removes games from a tag. Without games it deletes the tag; the
+// collection of an exported tag is remembered for the next export to
+// delete.
+func (d *DB) RemoveTag(steamID, tag string, appIDs []int) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if len(appIDs) == 0 {
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO deleted_collections (steamid, collection_id)
+			SELECT steamid, collection_id FROM tags
+			WHERE steamid = ? AND name = ? AND source = ? AND collection_id IS NOT NULL AND collection_id != ''
+		`, steamID, tag, model.TagSourceUser); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM game_tags WHERE steamid = ? AND tag = ?", steamID, tag); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM tags WHERE steamid = ? AND name = ?", steamID, tag); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	if err := checkNotSteamTag(tx, steamID, tag); err != nil {
+		return err
+	}
+	for _, id := range appIDs {
+		if _, err := tx.Exec("DELETE FROM game_tags WHERE steamid = ? AND tag = ? AND appid = ?", steamID, tag, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ImportCollection makes a tag hold exactly the games of a Steam
+// collection. An existing tag keeps its name and source.
+func (d *DB) ImportCollection(steamID, name, collectionID string, appIDs []int, at time.Time) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`
+		INSERT INTO tags (steamid, name, source, collection_id, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(steamid, name) DO UPDATE SET collection_id = excluded.collection_id
+	`, steamID, name, model.TagSourceSteam, collectionID, at.UTC()); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM game_tags WHERE steamid = ? AND tag = ?", steamID, name); err != nil {
+		return err
+	}
+	if err := insertTagged(tx, steamID, name, appIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetDeletedCollections returns the collections of deleted tags that were
+// not exported as deleted yet.
+func (d *DB) GetDeletedCollections(steamID string) ([]string, error) {
+	rows, err := d.Query("SELECT collection_id FROM deleted_collections WHERE steamid = ? ORDER BY deleted_at, collection_id", steamID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ClearDeletedCollections forgets collections once export deleted them.
+func (d *DB) ClearDeletedCollections(steamID string, collectionIDs []string) error {
+	for _, id := range collectionIDs {
+		if _, err := d.Exec("DELETE FROM deleted_collections WHERE steamid = ? AND collection_id = ?", steamID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetTagCollectionID links a tag to the Steam collection it was exported as.
+func (d *DB) SetTagCollectionID(steamID, tag, collectionID string) error {
+	_, err := d.Exec("UPDATE tags SET collection_id = ? WHERE steamid = ? AND name = ?", collectionID, steamID, tag)
+	return err
+}
+
+// checkNotSteamTag returns ErrSteamTag for a tag imported from Steam.
+func checkNotSteamTag(tx *sql.Tx, steamID, tag string) error {
+	var source string
+	err := tx.QueryRow("SELECT source FROM tags WHERE steamid = ? AND name = ?", steamID, tag).Scan(&source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if source == model.TagSourceSteam {
+		return ErrSteamTag
+	}
+	return nil
+}
+
+func insertTagged(tx *sql.Tx, steamID, tag string, appIDs []int) error {
+	stmt, err := tx.Prepare("INSERT OR IGNORE INTO game_tags (steamid, tag, appid) VALUES (?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+	for _, id := range appIDs {
+		if _, err := stmt.Exec(steamID, tag, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTags returns the account's tags by name, with their games by AppID.
+func (d *DB) GetTags(steamID string) ([]model.Tag, error) {
+	rows, err := d.Query(`
+		SELECT t.name, t.source, COALESCE(t.collection_id, ''), gt.appid
+		FROM tags t
+		LEFT JOIN game_tags gt ON gt.steamid = t.steamid AND gt.tag = t.name
+		WHERE t.steamid = ?
+		ORDER BY t.name COLLATE NOCASE, gt.appid
+	`, steamID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tags []model.Tag
+	for rows.Next() {
+		var t model.Tag
+		var appID sql.NullInt64
+		if err := rows.Scan(&t.Name, &t.Source, &t.CollectionID, &appID); err != nil {
+			return nil, err
+		}
+		if n := len(tags); n == 0 || tags[n-1].Name != t.Name {
+			t.AppIDs = []int{}
+			tags = append(tags, t)
+		}
+		if appID.Valid {
+			last := &tags[len(tags)-1]
+			last.AppIDs = append(last.AppIDs, int(appID.Int64))
+		}
+	}
+	return tags, rows.Err()
+}
+
+// GetTaggedApps returns the AppIDs with a tag, matched case-insensitively.
+func (d *DB) GetTaggedApps(steamID, tag string) (map[int]bool, error) {
+	rows, err := d.Query("SELECT appid FROM game_tags WHERE steamid = ? AND tag = ?", steamID, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	tagged := map[int]bool{}
+	for rows.Next() {
+		var appID int
+		if err := rows.Scan(&appID); err != nil {
+			return nil, err
+		}
+		tagged[appID] = true
+	}
+	return tagged, rows.Err()
+}
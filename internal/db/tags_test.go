@@ -0,0 +1,101 @@
+package db_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func TestTags(t *testing.T) {
+	d, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create DB: %v", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	now := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	if err := d.AddTag(testSteamID, "Couch", []int{620, 400}, now); err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+	// Tags match case-insensitively and keep their first spelling.
+	if err := d.AddTag(testSteamID, "couch", []int{400, 70}, now); err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+	if err := d.ImportCollection(testSteamID, "Favorites", "favorite", []int{10}, now); err != nil {
+		t.Fatalf("ImportCollection failed: %v", err)
+	}
+
+	// The games of a tag imported from Steam change in Steam only.
+	if err := d.AddTag(testSteamID, "favorites", []int{20}, now); !errors.Is(err, db.ErrSteamTag) {
+		t.Errorf("AddTag to a Steam tag: got %v, want ErrSteamTag", err)
+	}
+	if err := d.RemoveTag(testSteamID, "Favorites", []int{10}); !errors.Is(err, db.ErrSteamTag) {
+		t.Errorf("RemoveTag from a Steam tag: got %v, want ErrSteamTag", err)
+	}
+
+	tags, err := d.GetTags(testSteamID)
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("Expected 2 tags, got %+v", tags)
+	}
+	couch := tags[0]
+	if couch.Name != "Couch" || couch.Source != model.TagSourceUser || len(couch.AppIDs) != 3 || couch.AppIDs[0] != 70 {
+		t.Errorf("Unexpected Couch tag: %+v", couch)
+	}
+	if fav := tags[1]; fav.Source != model.TagSourceSteam || fav.CollectionID != "favorite" || len(fav.AppIDs) != 1 {
+		t.Errorf("Unexpected Favorites tag: %+v", fav)
+	}
+
+	// Importing a collection with an existing tag's name replaces its games
+	// but keeps its source.
+	if err := d.ImportCollection(testSteamID, "COUCH", "uc-abc", []int{620}, now); err != nil {
+		t.Fatalf("ImportCollection failed: %v", err)
+	}
+	tagged, err := d.GetTaggedApps(testSteamID, "couch")
+	if err != nil {
+		t.Fatalf("GetTaggedApps failed: %v", err)
+	}
+	if len(tagged) != 1 || !tagged[620] {
+		t.Errorf("Expected only 620 tagged, got %v", tagged)
+	}
+	tags, _ = d.GetTags(testSteamID)
+	if tags[0].Source != model.TagSourceUser || tags[0].CollectionID != "uc-abc" {
+		t.Errorf("Unexpected tag after import: %+v", tags[0])
+	}
+
+	if err := d.RemoveTag(testSteamID, "Couch", []int{620}); err != nil {
+		t.Fatalf("RemoveTag failed: %v", err)
+	}
+	tags, _ = d.GetTags(testSteamID)
+	if len(tags) != 2 || len(tags[0].AppIDs) != 0 {
+		t.Errorf("Expected an empty Couch tag, got %+v", tags)
+	}
+	if err := d.RemoveTag(testSteamID, "couch", nil); err != nil {
+		t.Fatalf("RemoveTag failed: %v", err)
+	}
+	tags, _ = d.GetTags(testSteamID)
+	if len(tags) != 1 || tags[0].Name != "Favorites" {
+		t.Errorf("Expected only Favorites left, got %+v", tags)
+	}
+
+	// The deleted tag's collection waits for export to delete it; deleting
+	// a tag imported from Steam leaves Steam alone.
+	if err := d.RemoveTag(testSteamID, "Favorites", nil); err != nil {
+		t.Fatalf("RemoveTag failed: %v", err)
+	}
+	deleted, err := d.GetDeletedCollections(testSteamID)
+	if err != nil || len(deleted) != 1 || deleted[0] != "uc-abc" {
+		t.Errorf("Expected uc-abc to be deleted, got %v, %v", deleted, err)
+	}
+	if err := d.ClearDeletedCollections(testSteamID, deleted); err != nil {
+		t.Fatalf("ClearDeletedCollections failed: %v", err)
+	}
+	if deleted, _ := d.GetDeletedCollections(testSteamID); len(deleted) != 0 {
+		t.Errorf("Expected no deleted collections after clearing, got %v", deleted)
+	}
+}
@@ -0,0 +1,300 @@
+// Package enrich fills in app details from several sources. Each source
+// supplies the fields it knows; a registry ranks the sources per field and
+// merges their answers.
+package enrich
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// ErrNotFound is returned by an Enricher that has no entry for an app.
+var ErrNotFound = errors.New("app not found")
+
+// Enricher fetches app details from one source.
+type Enricher interface {
+	// Name identifies the source; it is stored with every field it supplies.
+	Name() string
+	// Fields lists the AppDetails fields, by JSON name, the source can fill.
+	Fields() []string
+	// Fetch returns what the source knows about an app. Fields the source
+	// does not know are left zero.
+	Fetch(ctx context.Context, appID int) (*model.AppDetails, error)
+}
+
+// detailFields maps the JSON names of the AppDetails fields to their index.
+var detailFields = func() map[string]int {
+	fields := map[string]int{}
+	t := reflect.TypeOf(model.AppDetails{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields[name] = i
+	}
+	return fields
+}()
+
+// AllFields returns the JSON names of every AppDetails field, sorted.
+func AllFields() []string {
+	names := make([]string, 0, len(detailFields))
+	for name := range detailFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type source struct {
+	enricher Enricher
+	priority int
+	fields   map[string]int
+}
+
+// rank returns the source's priority for a field, or false when the source
+// does not supply it.
+func (s *source) rank(field string) (int, bool) {
+	if p, ok := s.fields[field]; ok {
+		return p, true
+	}
+	for _, f := range s.enricher.Fields() {
+		if f == field {
+			return s.priority, true
+		}
+	}
+	return 0, false
+}
+
+// maxRank is the source's highest priority over the fields it supplies.
+func (s *source) maxRank() int {
+	best := s.priority
+	for _, p := range s.fields {
+		if p > best {
+			best = p
+		}
+	}
+	return best
+}
+
+// Registry merges app details from registered sources. It is not safe to
+// change a registry while it enriches.
+type Registry struct {
+	sources []*source
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a source with a priority for all of its fields. Where two
+// sources supply a field, the higher priority wins; on a tie, the source
+// registered first.
+func (r *Registry) Register(e Enricher, priority int) error {
+	for _, f := range e.Fields() {
+		if _, ok := detailFields[f]; !ok {
+			return fmt.Errorf("source %s: unknown field %q", e.Name(), f)
+		}
+	}
+	if r.lookup(e.Name()) != nil {
+		return fmt.Errorf("source %s is already registered", e.Name())
+	}
+	r.sources = append(r.sources, &source{enricher: e, priority: priority, fields: map[string]int{}})
+	return nil
+}
+
+// SetPriority changes a source's priority. With a field, only that field's
+// priority changes; the source must supply the field.
+func (r *Registry) SetPriority(name, field string, priority int) error {
+	s := r.lookup(name)
+	if s == nil {
+		return fmt.Errorf("unknown source %q (have %s)", name, strings.Join(r.Sources(), ", "))
+	}
+	if field == "" {
+		s.priority = priority
+		return nil
+	}
+	if _, ok := s.rank(field); !ok {
+		return fmt.Errorf("source %s does not supply %q", name, field)
+	}
+	s.fields[field] = priority
+	return nil
+}
+
+// Sources returns the names of the registered sources.
+func (r *Registry) Sources() []string {
+	names := make([]string, len(r.sources))
+	for i, s := range r.sources {
+		names[i] = s.enricher.Name()
+	}
+	return names
+}
+
+func (r *Registry) lookup(name string) *source {
+	for _, s := range r.sources {
+		if s.enricher.Name() == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Result is the merged details of an app.
+type Result struct {
+	Details model.AppDetails
+	// Sources maps each filled field to the source that supplied it.
+	Sources map[string]string
+	// Errors holds the sources that were asked and failed, including with
+	// ErrNotFound.
+	Errors map[string]error
+}
+
+// Found reports whether any source supplied a field.
+func (r *Result) Found() bool {
+	return len(r.Sources) > 0
+}
+
+// Enrich asks the sources for an app, from the highest ranked down, and
+// merges their fields. A source is only asked while it could still supply
+// a missing field or outrank the source of a filled one.
+func (r *Registry) Enrich(ctx context.Context, appID int) (*Result, error) {
+	ordered := make([]*source, len(r.sources))
+	copy(ordered, r.sources)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].maxRank() > ordered[j].maxRank() })
+
+	res := &Result{Sources: map[string]string{}, Errors: map[string]error{}}
+	ranks := map[string]int{}
+	merged := reflect.ValueOf(&res.Details).Elem()
+
+	for _, s := range ordered {
+		if !useful(s, res.Sources, ranks) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		name := s.enricher.Name()
+		details, err := s.enricher.Fetch(ctx, appID)
+		if err != nil {
+			res.Errors[name] = err
+			continue
+		}
+		fetched := reflect.ValueOf(details).Elem()
+		for _, field := range s.enricher.Fields() {
+			rank, _ := s.rank(field)
+			if _, filled := res.Sources[field]; filled && rank <= ranks[field] {
+				continue
+			}
+			v := fetched.Field(detailFields[field])
+			if !isSet(v) {
+				continue
+			}
+			merged.Field(detailFields[field]).Set(v)
+			res.Sources[field] = name
+			ranks[field] = rank
+		}
+	}
+	return res, nil
+}
+
+// Merge lays a fresh result over stored details and the sources of their
+// fields. A stored field is only replaced by a source ranked at least as
+// high as the one that supplied it, or when its source is no longer
+// registered or no longer supplies the field.
+func (r *Registry) Merge(stored *model.AppDetails, sources map[string]string, res *Result) *Result {
+	out := &Result{Details: *stored, Sources: map[string]string{}, Errors: res.Errors}
+	for field, name := range sources {
+		if _, ok := detailFields[field]; ok {
+			out.Sources[field] = name
+		}
+	}
+	merged := reflect.ValueOf(&out.Details).Elem()
+	fresh := reflect.ValueOf(&res.Details).Elem()
+	for field, name := range res.Sources {
+		if old, ok := out.Sources[field]; ok && r.outranks(old, name, field) {
+			continue
+		}
+		merged.Field(detailFields[field]).Set(fresh.Field(detailFields[field]))
+		out.Sources[field] = name
+	}
+	return out
+}
+
+// outranks reports whether source a ranks above source b for a field.
+func (r *Registry) outranks(a, b, field string) bool {
+	sa := r.lookup(a)
+	if sa == nil {
+		return false
+	}
+	ra, ok := sa.rank(field)
+	if !ok {
+		return false
+	}
+	sb := r.lookup(b)
+	if sb == nil {
+		return true
+	}
+	rb, ok := sb.rank(field)
+	return !ok || ra > rb
+}
+
+// SetFields returns the JSON names of the fields filled in details, sorted.
+func SetFields(details *model.AppDetails) []string {
+	v := reflect.ValueOf(details).Elem()
+	var names []string
+	for _, name := range AllFields() {
+		if isSet(v.Field(detailFields[name])) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// useful reports whether s could fill or improve a field.
+func useful(s *source, filled map[string]string, ranks map[string]int) bool {
+	for _, field := range s.enricher.Fields() {
+		if _, ok := filled[field]; !ok {
+			return true
+		}
+		if rank, _ := s.rank(field); rank > ranks[field] {
+			return true
+		}
+	}
+	return false
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// isSet reports whether a source filled a field. Scalars such as false or 0
+// count as set; empty strings, empty lists and JSON placeholders such as []
+// count as unset.
+func isSet(v reflect.Value) bool {
+	if v.Type() == rawMessageType {
+		switch string(bytes.TrimSpace(v.Bytes())) {
+		case "", "null", "[]", "{}":
+			return false
+		}
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() > 0
+	case reflect.Ptr:
+		return !v.IsNil()
+	case reflect.String:
+		return v.Len() > 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return !v.IsZero()
+	}
+}
@@ -0,0 +1,228 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+type fakeSource struct {
+	name    string
+	fields  []string
+	details *model.AppDetails
+	err     error
+	calls   int
+}
+
+func (f *fakeSource) Name() string     { return f.name }
+func (f *fakeSource) Fields() []string { return f.fields }
+
+func (f *fakeSource) Fetch(ctx context.Context, appID int) (*model.AppDetails, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	d := *f.details
+	return &d, nil
+}
+
+func TestEnrichMergesFieldsByPriority(t *testing.T) {
+	store := &fakeSource{name: "store", fields: AllFields(), details: &model.AppDetails{
+		Type:       "game",
+		Name:       "Portal 2",
+		Developers: []string{},
+		Genres:     []model.Genre{{Description: "Puzzle"}},
+	}}
+	wiki := &fakeSource{name: "wiki", fields: []string{"name", "developers", "genres"}, details: &model.AppDetails{
+		Name:       "Portal 2 (wiki)",
+		Developers: []string{"Valve"},
+		Genres:     []model.Genre{{Description: "Puzzle platformer"}},
+	}}
+
+	r := NewRegistry()
+	if err := r.Register(store, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(wiki, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetPriority("wiki", "genres", 200); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := r.Enrich(context.Background(), 620)
+	if err != nil {
+		t.Fatalf("Enrich: %v", err)
+	}
+	if res.Details.Name != "Portal 2" || res.Details.Type != "game" {
+		t.Errorf("store fields: got name %q type %q", res.Details.Name, res.Details.Type)
+	}
+	if !reflect.DeepEqual(res.Details.Developers, []string{"Valve"}) {
+		t.Errorf("empty store developers should be filled by the wiki, got %v", res.Details.Developers)
+	}
+	if len(res.Details.Genres) != 1 || res.Details.Genres[0].Description != "Puzzle platformer" {
+		t.Errorf("wiki genres should outrank the store, got %v", res.Details.Genres)
+	}
+	want := map[string]string{"type": "store", "name": "store", "is_free": "store", "developers": "wiki", "genres": "wiki"}
+	if !reflect.DeepEqual(res.Sources, want) {
+		t.Errorf("sources: got %v, want %v", res.Sources, want)
+	}
+}
+
+func TestEnrichSkipsSourcesThatCannotHelp(t *testing.T) {
+	store := &fakeSource{name: "store", fields: AllFields(), details: &model.AppDetails{
+		Name:       "Portal 2",
+		Developers: []string{"Valve"},
+	}}
+	wiki := &fakeSource{name: "wiki", fields: []string{"developers"}, details: &model.AppDetails{Developers: []string{"Valve"}}}
+
+	r := NewRegistry()
+	_ = r.Register(wiki, 10)
+	_ = r.Register(store, 100)
+
+	if _, err := r.Enrich(context.Background(), 620); err != nil {
+		t.Fatalf("Enrich: %v", err)
+	}
+	if store.calls != 1 || wiki.calls != 0 {
+		t.Errorf("calls: store %d, wiki %d; want 1 and 0", store.calls, wiki.calls)
+	}
+}
+
+func TestEnrichFallsBackWhenASourceFails(t *testing.T) {
+	failure := errors.New("503")
+	store := &fakeSource{name: "store", fields: AllFields(), err: failure}
+	wiki := &fakeSource{name: "wiki", fields: []string{"genres"}, err: ErrNotFound}
+
+	r := NewRegistry()
+	_ = r.Register(store, 100)
+	_ = r.Register(wiki, 10)
+
+	res, err := r.Enrich(context.Background(), 620)
+	if err != nil {
+		t.Fatalf("Enrich: %v", err)
+	}
+	if res.Found() {
+		t.Errorf("Found with every source failing: %v", res.Sources)
+	}
+	if !errors.Is(res.Errors["store"], failure) || !errors.Is(res.Errors["wiki"], ErrNotFound) {
+		t.Errorf("errors: got %v", res.Errors)
+	}
+
+	wiki.err = nil
+	wiki.details = &model.AppDetails{Genres: []model.Genre{{Description: "Puzzle"}}}
+	res, _ = r.Enrich(context.Background(), 620)
+	if !res.Found() || res.Sources["genres"] != "wiki" {
+		t.Errorf("fallback: got sources %v", res.Sources)
+	}
+}
+
+func TestEnrichKeepsZeroScalarsFromASource(t *testing.T) {
+	store := &fakeSource{name: "store", fields: AllFields(), details: &model.AppDetails{
+		Name:        "Portal 2",
+		RequiredAge: "0",
+	}}
+	other := &fakeSource{name: "other", fields: []string{"is_free", "required_age"}, details: &model.AppDetails{
+		IsFree:      true,
+		RequiredAge: "18",
+	}}
+
+	r := NewRegistry()
+	_ = r.Register(store, 100)
+	_ = r.Register(other, 10)
+
+	res, err := r.Enrich(context.Background(), 620)
+	if err != nil {
+		t.Fatalf("Enrich: %v", err)
+	}
+	if res.Details.IsFree || res.Details.RequiredAge != "0" {
+		t.Errorf("store values were overwritten: is_free %v, required_age %q", res.Details.IsFree, res.Details.RequiredAge)
+	}
+	if res.Sources["is_free"] != "store" || res.Sources["required_age"] != "store" {
+		t.Errorf("sources: got %v", res.Sources)
+	}
+	if other.calls != 0 {
+		t.Errorf("other was asked %d times; the store already supplied its fields", other.calls)
+	}
+}
+
+func TestMergeKeepsHigherRankedStoredFields(t *testing.T) {
+	store := &fakeSource{name: "store", fields: AllFields(), err: errors.New("timeout")}
+	wiki := &fakeSource{name: "wiki", fields: []string{"name", "genres"}, details: &model.AppDetails{
+		Name:   "Portal 2 (wiki)",
+		Genres: []model.Genre{{Description: "Puzzle platformer"}},
+	}}
+	r := NewRegistry()
+	_ = r.Register(store, 100)
+	_ = r.Register(wiki, 10)
+
+	stored := &model.AppDetails{Type: "game", Name: "Portal 2", ShortDescription: "Think with portals"}
+	sources := map[string]string{"type": "store", "name": "store", "short_description": "store", "genres": "gone"}
+
+	res, err := r.Enrich(context.Background(), 620)
+	if err != nil {
+		t.Fatalf("Enrich: %v", err)
+	}
+	merged := r.Merge(stored, sources, res)
+	if merged.Details.Type != "game" || merged.Details.Name != "Portal 2" || merged.Details.ShortDescription != "Think with portals" {
+		t.Errorf("stored store fields were replaced: %+v", merged.Details)
+	}
+	if len(merged.Details.Genres) != 1 || merged.Details.Genres[0].Description != "Puzzle platformer" {
+		t.Errorf("genres from an unregistered source should be replaced, got %v", merged.Details.Genres)
+	}
+	want := map[string]string{"type": "store", "name": "store", "short_description": "store", "genres": "wiki"}
+	if !reflect.DeepEqual(merged.Sources, want) {
+		t.Errorf("sources: got %v, want %v", merged.Sources, want)
+	}
+
+	// A source of equal rank replaces the stored field.
+	_ = r.SetPriority("wiki", "name", 100)
+	res, _ = r.Enrich(context.Background(), 620)
+	if merged = r.Merge(stored, sources, res); merged.Details.Name != "Portal 2 (wiki)" || merged.Sources["name"] != "wiki" {
+		t.Errorf("equal rank: got name %q from %q", merged.Details.Name, merged.Sources["name"])
+	}
+}
+
+func TestRegistryValidation(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(&fakeSource{name: "bad", fields: []string{"nope"}}, 1); err == nil {
+		t.Error("Register accepted an unknown field")
+	}
+	if err := r.Register(&fakeSource{name: "wiki", fields: []string{"genres"}}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(&fakeSource{name: "wiki", fields: []string{"name"}}, 1); err == nil {
+		t.Error("Register accepted a duplicate source")
+	}
+	if err := r.SetPriority("missing", "", 1); err == nil {
+		t.Error("SetPriority accepted an unknown source")
+	}
+	if err := r.SetPriority("wiki", "name", 1); err == nil {
+		t.Error("SetPriority accepted a field the source does not supply")
+	}
+}
+
+func TestIsSet(t *testing.T) {
+	a := model.AppDetails{
+		PCRequirements:  []byte(" [] "),
+		MacRequirements: []byte(`{"minimum":"x"}`),
+		Screenshots:     []model.Screenshot{},
+	}
+	v := reflect.ValueOf(a)
+	for field, want := range map[string]bool{
+		"pc_requirements":  false,
+		"mac_requirements": true,
+		"screenshots":      false,
+		"metacritic":       false,
+		"platforms":        false,
+		"name":             false,
+		"required_age":     false,
+		"is_free":          true,
+	} {
+		if got := isSet(v.Field(detailFields[field])); got != want {
+			t.Errorf("isSet(%s) = %v, want %v", field, got, want)
+		}
+	}
+}
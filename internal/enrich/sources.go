@@ -0,0 +1,62 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/pcgw"
+)
+
+// StoreDetailsClient fetches app details from the Steam Store API.
+type StoreDetailsClient interface {
+	GetAppDetails(ctx context.Context, appID int) (*model.AppDetailsResponse, error)
+}
+
+// Steam is the Steam Store. It supplies every field.
+type Steam struct {
+	Client StoreDetailsClient
+}
+
+func (s *Steam) Name() string { return "steam" }
+
+func (s *Steam) Fields() []string { return AllFields() }
+
+func (s *Steam) Fetch(ctx context.Context, appID int) (*model.AppDetails, error) {
+	resp, err := s.Client.GetAppDetails(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := (*resp)[strconv.Itoa(appID)]
+	if !ok || !entry.Success {
+		// Delisted and region-locked apps are reported as success=false.
+		return nil, ErrNotFound
+	}
+	return &entry.Data, nil
+}
+
+// WikiClient fetches app details from PCGamingWiki.
+type WikiClient interface {
+	GetAppDetails(ctx context.Context, appID int) (*model.AppDetails, error)
+}
+
+// PCGW is PCGamingWiki. It knows the names, developers, publishers and
+// genres of many apps the store no longer lists.
+type PCGW struct {
+	Client WikiClient
+}
+
+func (p *PCGW) Name() string { return "pcgw" }
+
+func (p *PCGW) Fields() []string {
+	return []string{"name", "developers", "publishers", "genres"}
+}
+
+func (p *PCGW) Fetch(ctx context.Context, appID int) (*model.AppDetails, error) {
+	details, err := p.Client.GetAppDetails(ctx, appID)
+	if errors.Is(err, pcgw.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return details, err
+}
@@ -0,0 +1,48 @@
+// Package launcher starts games through the Steam client's steam:// URLs.
+package launcher
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// Opener abstracts opening URLs for testing.
+type Opener interface {
+	Open(url string) error
+}
+
+// DefaultOpener opens URLs with the desktop's default handler: xdg-open,
+// open on macOS or rundll32 on Windows. It does not wait for the handler.
+type DefaultOpener struct{}
+
+func (o *DefaultOpener) Open(url string) error {
+	name, args := openCommand(runtime.GOOS, url)
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("opening %s: %w", url, err)
+	}
+	return cmd.Process.Release()
+}
+
+func openCommand(goos, url string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}
+	default:
+		return "xdg-open", []string{url}
+	}
+}
+
+// RunGameURL returns the URL that makes the Steam client launch an app,
+// installing it first when needed.
+func RunGameURL(appID int) string {
+	return fmt.Sprintf("steam://rungameid/%d", appID)
+}
+
+// Launch asks the Steam client to launch an app.
+func Launch(o Opener, appID int) error {
+	return o.Open(RunGameURL(appID))
+}
@@ -0,0 +1,51 @@
+package launcher
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type recordingOpener struct {
+	urls []string
+	err  error
+}
+
+func (o *recordingOpener) Open(url string) error {
+	o.urls = append(o.urls, url)
+	return o.err
+}
+
+func TestLaunch(t *testing.T) {
+	o := &recordingOpener{}
+	if err := Launch(o, 620); err != nil {
+		t.Fatalf("Launch: %v", err)
+	}
+	if want := []string{"steam://rungameid/620"}; !reflect.DeepEqual(o.urls, want) {
+		t.Errorf("opened %v, want %v", o.urls, want)
+	}
+
+	o.err = errors.New("no handler")
+	if err := Launch(o, 620); !errors.Is(err, o.err) {
+		t.Errorf("Launch error = %v, want %v", err, o.err)
+	}
+}
+
+func TestOpenCommand(t *testing.T) {
+	tests := []struct {
+		goos string
+		name string
+		args []string
+	}{
+		{"linux", "xdg-open", []string{"steam://rungameid/1"}},
+		{"freebsd", "xdg-open", []string{"steam://rungameid/1"}},
+		{"darwin", "open", []string{"steam://rungameid/1"}},
+		{"windows", "rundll32", []string{"url.dll,FileProtocolHandler", "steam://rungameid/1"}},
+	}
+	for _, tt := range tests {
+		name, args := openCommand(tt.goos, "steam://rungameid/1")
+		if name != tt.name || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: got %s %v, want %s %v", tt.goos, name, args, tt.name, tt.args)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Message is one entry of a chat conversation. Role is "system", "user",
+// "assistant" or "tool".
+type Message struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"` // For role "tool": the call answered
+	Name       string     `json:"name,omitempty"`         // For role "tool": the tool that ran
+}
+
+// ToolCall is a request from the model to run a tool.
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"` // JSON object
+}
+
+// Tool describes a tool the model may call. Parameters is a JSON schema.
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// ChatClient is a Client that supports multi-turn chat with tool calling.
+type ChatClient interface {
+	Client
+	Chat(ctx context.Context, messages []Message, tools []Tool) (Message, error)
+}
+
+// toolFunction is the function part of a tool definition in both the
+// Ollama and OpenAI APIs.
+type toolFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+type toolDefinition struct {
+	Type     string       `json:"type"`
+	Function toolFunction `json:"function"`
+}
+
+func toolDefinitions(tools []Tool) []toolDefinition {
+	if len(tools) == 0 {
+		return nil
+	}
+	defs := make([]toolDefinition, len(tools))
+	for i, t := range tools {
+		params := t.Parameters
+		if len(params) == 0 {
+			params = json.RawMessage(`{"type": "object", "properties": {}}`)
+		}
+		defs[i] = toolDefinition{Type: "function", Function: toolFunction{Name: t.Name, Description: t.Description, Parameters: params}}
+	}
+	return defs
+}
@@ -9,7 +9,9 @@ type Client interface {
 }
 
 type Config struct {
+	Provider   string // Registered provider name; empty means "ollama"
 	BaseURL    string
 	Model      string
 	EmbedModel string
+	APIKey     string // Optional bearer token
 }
@@ -129,3 +129,83 @@ func (c *OllamaClient) Embed(ctx context.Context, text string) ([]float32, error
 
 	return res.Embedding, nil
 }
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaChatRequest struct {
+	Model    string           `json:"model"`
+	Messages []ollamaMessage  `json:"messages"`
+	Tools    []toolDefinition `json:"tools,omitempty"`
+	Stream   bool             `json:"stream"`
+}
+
+type ollamaChatResponse struct {
+	Message ollamaMessage `json:"message"`
+}
+
+// Chat sends the conversation to /api/chat. Ollama does not assign tool
+// call IDs, so they are numbered here.
+func (c *OllamaClient) Chat(ctx context.Context, messages []Message, tools []Tool) (Message, error) {
+	reqBody := ollamaChatRequest{
+		Model: c.config.Model,
+		Tools: toolDefinitions(tools),
+	}
+	for _, m := range messages {
+		om := ollamaMessage{Role: m.Role, Content: m.Content, ToolName: m.Name}
+		for _, tc := range m.ToolCalls {
+			var call ollamaToolCall
+			call.Function.Name = tc.Name
+			call.Function.Arguments = tc.Arguments
+			om.ToolCalls = append(om.ToolCalls, call)
+		}
+		reqBody.Messages = append(reqBody.Messages, om)
+	}
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return Message{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.config.BaseURL+"/api/chat", bytes.NewBuffer(body))
+	if err != nil {
+		return Message{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Message{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return Message{}, fmt.Errorf("ollama chat failed: %s", string(b))
+	}
+
+	var res ollamaChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return Message{}, err
+	}
+
+	msg := Message{Role: "assistant", Content: res.Message.Content}
+	for i, tc := range res.Message.ToolCalls {
+		msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+			ID:        fmt.Sprintf("call_%d", i),
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
+	}
+	return msg, nil
+}
@@ -0,0 +1,171 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIClient talks to servers that implement the OpenAI API, such as the
+// llama.cpp server and vLLM.
+type OpenAIClient struct {
+	config Config
+	client *http.Client
+}
+
+func NewOpenAIClient(cfg Config) *OpenAIClient {
+	// Accept base URLs with or without the /v1 suffix.
+	cfg.BaseURL = strings.TrimSuffix(strings.TrimSuffix(cfg.BaseURL, "/"), "/v1")
+	return &OpenAIClient{
+		config: cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (c *OpenAIClient) do(ctx context.Context, method, path string, reqBody, res interface{}) error {
+	var body io.Reader
+	if reqBody != nil {
+		b, err := json.Marshal(reqBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewBuffer(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("openai %s failed: status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	if res == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+func (c *OpenAIClient) Check(ctx context.Context) error {
+	return c.do(ctx, "GET", "/v1/models", nil, nil)
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON encoded as a string
+	} `json:"function"`
+}
+
+type chatMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type chatRequest struct {
+	Model    string           `json:"model"`
+	Messages []chatMessage    `json:"messages"`
+	Tools    []toolDefinition `json:"tools,omitempty"`
+	Stream   bool             `json:"stream"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (c *OpenAIClient) Generate(ctx context.Context, prompt string) (string, error) {
+	msg, err := c.Chat(ctx, []Message{{Role: "user", Content: prompt}}, nil)
+	if err != nil {
+		return "", err
+	}
+	return msg.Content, nil
+}
+
+// Chat sends the conversation to /v1/chat/completions.
+func (c *OpenAIClient) Chat(ctx context.Context, messages []Message, tools []Tool) (Message, error) {
+	reqBody := chatRequest{
+		Model: c.config.Model,
+		Tools: toolDefinitions(tools),
+	}
+	for _, m := range messages {
+		cm := chatMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, tc := range m.ToolCalls {
+			call := openAIToolCall{ID: tc.ID, Type: "function"}
+			call.Function.Name = tc.Name
+			call.Function.Arguments = string(tc.Arguments)
+			cm.ToolCalls = append(cm.ToolCalls, call)
+		}
+		reqBody.Messages = append(reqBody.Messages, cm)
+	}
+
+	var res chatResponse
+	if err := c.do(ctx, "POST", "/v1/chat/completions", reqBody, &res); err != nil {
+		return Message{}, err
+	}
+	if len(res.Choices) == 0 {
+		return Message{}, fmt.Errorf("openai chat completion returned no choices")
+	}
+
+	choice := res.Choices[0].Message
+	msg := Message{Role: "assistant", Content: choice.Content}
+	for _, tc := range choice.ToolCalls {
+		args := json.RawMessage(tc.Function.Arguments)
+		if !json.Valid(args) {
+			args = json.RawMessage(`{}`)
+		}
+		msg.ToolCalls = append(msg.ToolCalls, ToolCall{ID: tc.ID, Name: tc.Function.Name, Arguments: args})
+	}
+	return msg, nil
+}
+
+type embeddingsRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (c *OpenAIClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	var res embeddingsResponse
+	err := c.do(ctx, "POST", "/v1/embeddings", embeddingsRequest{
+		Model: c.config.EmbedModel,
+		Input: text,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Data) == 0 {
+		return nil, fmt.Errorf("openai embeddings returned no data")
+	}
+	return res.Data[0].Embedding, nil
+}
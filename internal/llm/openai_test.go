@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAIClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("got Authorization %q, want Bearer secret", got)
+		}
+		switch r.URL.Path {
+		case "/v1/models":
+			_, _ = w.Write([]byte(`{"data": []}`))
+		case "/v1/chat/completions":
+			var req chatRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Model != "qwen" || len(req.Messages) != 1 || req.Messages[0].Content != "Hello" {
+				t.Errorf("unexpected chat request: %+v", req)
+			}
+			_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Hi there"}}]}`))
+		case "/v1/embeddings":
+			var req embeddingsRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Model != "bge" {
+				t.Errorf("got embedding model %q, want bge", req.Model)
+			}
+			_, _ = w.Write([]byte(`{"data": [{"embedding": [0.5, 0.25]}]}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := New(Config{Provider: "openai", BaseURL: ts.URL + "/v1/", Model: "qwen", EmbedModel: "bge", APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := client.Check(ctx); err != nil {
+		t.Errorf("Check error: %v", err)
+	}
+	res, err := client.Generate(ctx, "Hello")
+	if err != nil || res != "Hi there" {
+		t.Errorf("Generate: got %q, %v", res, err)
+	}
+	v, err := client.Embed(ctx, "Hello")
+	if err != nil || len(v) != 2 || v[0] != 0.5 {
+		t.Errorf("Embed: got %v, %v", v, err)
+	}
+}
+
+func TestOpenAIClientError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": "invalid api key"}`))
+	}))
+	defer ts.Close()
+
+	client := NewOpenAIClient(Config{BaseURL: ts.URL})
+	if _, err := client.Generate(context.Background(), "Hello"); err == nil {
+		t.Error("expected an error for status 401")
+	}
+}
+
+func TestNewUnknownProvider(t *testing.T) {
+	if _, err := New(Config{Provider: "nope"}); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+	if c, err := New(Config{}); err != nil {
+		t.Errorf("empty provider should default to ollama: %v", err)
+	} else if _, ok := c.(*OllamaClient); !ok {
+		t.Errorf("got %T, want *OllamaClient", c)
+	}
+}
+
+func TestNewDefaultBaseURL(t *testing.T) {
+	c, err := New(Config{Provider: "openai"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if got := c.(*OpenAIClient).config.BaseURL; got != "http://localhost:8080" {
+		t.Errorf("openai base URL = %q, want the llama.cpp default", got)
+	}
+	c, err = New(Config{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if got := c.(*OllamaClient).config.BaseURL; got != "http://localhost:11434" {
+		t.Errorf("ollama base URL = %q, want the Ollama default", got)
+	}
+	c, err = New(Config{Provider: "openai", BaseURL: "http://gpu:8000/v1"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if got := c.(*OpenAIClient).config.BaseURL; got != "http://gpu:8000" {
+		t.Errorf("explicit base URL = %q, want it kept", got)
+	}
+}
+
+func TestChatToolCalls(t *testing.T) {
+	tools := []Tool{{Name: "get_playtime", Description: "Playtime", Parameters: json.RawMessage(`{"type":"object"}`)}}
+	history := []Message{
+		{Role: "user", Content: "How long did I play 620?"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_0", Name: "get_playtime", Arguments: json.RawMessage(`{"appid":620}`)}}},
+		{Role: "tool", Content: `{"playtime_minutes":90}`, ToolCallID: "call_0", Name: "get_playtime"},
+	}
+
+	openai := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Tools) != 1 || req.Tools[0].Function.Name != "get_playtime" {
+			t.Errorf("unexpected tools: %+v", req.Tools)
+		}
+		if len(req.Messages) != 3 || req.Messages[1].ToolCalls[0].Function.Arguments != `{"appid":620}` || req.Messages[2].ToolCallID != "call_0" {
+			t.Errorf("unexpected messages: %+v", req.Messages)
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "tool_calls": [
+			{"id": "abc", "type": "function", "function": {"name": "get_description", "arguments": "{\"appid\": 400}"}}
+		]}}]}`))
+	}))
+	defer openai.Close()
+
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req ollamaChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Messages) != 3 || req.Messages[2].ToolName != "get_playtime" {
+			t.Errorf("unexpected messages: %+v", req.Messages)
+		}
+		_, _ = w.Write([]byte(`{"message": {"role": "assistant", "tool_calls": [
+			{"function": {"name": "get_description", "arguments": {"appid": 400}}}
+		]}}`))
+	}))
+	defer ollama.Close()
+
+	for provider, url := range map[string]string{"openai": openai.URL, "ollama": ollama.URL} {
+		client, err := New(Config{Provider: provider, BaseURL: url, Model: "m"})
+		if err != nil {
+			t.Fatalf("New(%s) error: %v", provider, err)
+		}
+		reply, err := client.(ChatClient).Chat(context.Background(), history, tools)
+		if err != nil {
+			t.Fatalf("%s Chat error: %v", provider, err)
+		}
+		if len(reply.ToolCalls) != 1 || reply.ToolCalls[0].Name != "get_description" || reply.ToolCalls[0].ID == "" {
+			t.Fatalf("%s: unexpected tool calls: %+v", provider, reply.ToolCalls)
+		}
+		var args struct {
+			AppID int `json:"appid"`
+		}
+		if err := json.Unmarshal(reply.ToolCalls[0].Arguments, &args); err != nil || args.AppID != 400 {
+			t.Errorf("%s: got arguments %s", provider, reply.ToolCalls[0].Arguments)
+		}
+	}
+}
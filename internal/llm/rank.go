@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RankCandidate is a game offered to the model for ranking.
+type RankCandidate struct {
+	AppID       int      `json:"appid"`
+	Name        string   `json:"name"`
+	Genres      []string `json:"genres,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// RankedGame is one entry of the model's ranking.
+type RankedGame struct {
+	AppID  int    `json:"appid"`
+	Reason string `json:"reason"`
+}
+
+// RankingSchema is the JSON schema the model is asked to follow.
+const RankingSchema = `{
+  "type": "object",
+  "required": ["ranking"],
+  "properties": {
+    "ranking": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["appid", "reason"],
+        "properties": {
+          "appid": {"type": "integer"},
+          "reason": {"type": "string"}
+        }
+      }
+    }
+  }
+}`
+
+// RankingPrompt asks the model to order candidates for someone whose
+// favourite genres are topGenres.
+func RankingPrompt(topGenres []string, candidates []RankCandidate) string {
+	list, _ := json.MarshalIndent(candidates, "", "  ")
+	return fmt.Sprintf(`You recommend games from a player's backlog.
+The player's favourite genres, most liked first: %s.
+
+Candidates:
+%s
+
+Rank every candidate from best to worst fit for this player and give a one-sentence reason for each.
+Answer with JSON only, no prose, matching this schema:
+%s`, strings.Join(topGenres, ", "), list, RankingSchema)
+}
+
+var (
+	codeFence     = regexp.MustCompile("(?s)```(?:json)?\\s*(.*?)```")
+	trailingComma = regexp.MustCompile(`,\s*([\]}])`)
+)
+
+// ParseRanking validates the model's answer against RankingSchema and the
+// candidates. It repairs common mistakes: code fences or prose around the
+// JSON, trailing commas, a bare array instead of an object, appids sent as
+// strings, unknown or duplicate appids and candidates left out (appended
+// in their original order). It fails when no usable ranking remains.
+func ParseRanking(output string, candidates []RankCandidate) ([]RankedGame, error) {
+	text := strings.TrimSpace(output)
+	if m := codeFence.FindStringSubmatch(text); m != nil {
+		text = strings.TrimSpace(m[1])
+	}
+	text = extractJSON(text)
+	if text == "" {
+		return nil, fmt.Errorf("no JSON in model output")
+	}
+	text = trailingComma.ReplaceAllString(text, "$1")
+
+	var entries []json.RawMessage
+	var wrapped struct {
+		Ranking []json.RawMessage `json:"ranking"`
+	}
+	if strings.HasPrefix(text, "[") {
+		if err := json.Unmarshal([]byte(text), &entries); err != nil {
+			return nil, fmt.Errorf("invalid ranking JSON: %w", err)
+		}
+	} else {
+		if err := json.Unmarshal([]byte(text), &wrapped); err != nil {
+			return nil, fmt.Errorf("invalid ranking JSON: %w", err)
+		}
+		entries = wrapped.Ranking
+	}
+
+	known := make(map[int]bool, len(candidates))
+	for _, c := range candidates {
+		known[c.AppID] = true
+	}
+
+	seen := make(map[int]bool)
+	var ranked []RankedGame
+	for _, raw := range entries {
+		var e struct {
+			AppID  json.Number `json:"appid"`
+			Reason string      `json:"reason"`
+		}
+		if err := json.Unmarshal(raw, &e); err != nil {
+			continue
+		}
+		id, err := e.AppID.Int64()
+		if err != nil || !known[int(id)] || seen[int(id)] {
+			continue
+		}
+		seen[int(id)] = true
+		ranked = append(ranked, RankedGame{AppID: int(id), Reason: strings.TrimSpace(e.Reason)})
+	}
+	if len(ranked) == 0 {
+		return nil, fmt.Errorf("ranking names none of the candidates")
+	}
+
+	for _, c := range candidates {
+		if !seen[c.AppID] {
+			ranked = append(ranked, RankedGame{AppID: c.AppID})
+		}
+	}
+	return ranked, nil
+}
+
+// extractJSON returns the outermost JSON object or array in text.
+func extractJSON(text string) string {
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return ""
+	}
+	closing := "}"
+	if text[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(text, closing)
+	if end < start {
+		return ""
+	}
+	return text[start : end+1]
+}
@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+var rankCandidates = []RankCandidate{
+	{AppID: 10, Name: "A"},
+	{AppID: 20, Name: "B"},
+	{AppID: 30, Name: "C"},
+}
+
+func rankedIDs(ranked []RankedGame) []int {
+	ids := make([]int, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.AppID
+	}
+	return ids
+}
+
+func TestParseRanking(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []int
+	}{
+		{"valid", `{"ranking": [{"appid": 30, "reason": "x"}, {"appid": 10, "reason": "y"}, {"appid": 20, "reason": "z"}]}`, []int{30, 10, 20}},
+		{"fenced with prose", "Sure! Here you go:\n```json\n{\"ranking\": [{\"appid\": 20, \"reason\": \"fun\"}]}\n```\nEnjoy.", []int{20, 10, 30}},
+		{"bare array with trailing comma", `[{"appid": 30, "reason": "a"}, {"appid": 20, "reason": "b"},]`, []int{30, 20, 10}},
+		{"string appids", `{"ranking": [{"appid": "20", "reason": "b"}]}`, []int{20, 10, 30}},
+		{"unknown and duplicate appids", `{"ranking": [{"appid": 99, "reason": "?"}, {"appid": 30, "reason": "a"}, {"appid": 30, "reason": "again"}]}`, []int{30, 10, 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked, err := ParseRanking(tt.output, rankCandidates)
+			if err != nil {
+				t.Fatalf("ParseRanking error: %v", err)
+			}
+			got := rankedIDs(ranked)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	ranked, _ := ParseRanking(`{"ranking": [{"appid": 20, "reason": " Great co-op. "}]}`, rankCandidates)
+	if ranked[0].Reason != "Great co-op." || ranked[1].Reason != "" {
+		t.Errorf("unexpected reasons: %+v", ranked)
+	}
+}
+
+func TestParseRankingMalformed(t *testing.T) {
+	for _, output := range []string{
+		"I think you should play B.",
+		`{"ranking": [{"appid": 10, "reason": "x"`,
+		`{"ranking": [{"appid": 99, "reason": "not a candidate"}]}`,
+		`{"ranking": []}`,
+	} {
+		if _, err := ParseRanking(output, rankCandidates); err == nil {
+			t.Errorf("expected an error for %q", output)
+		}
+	}
+}
+
+func TestRankingPrompt(t *testing.T) {
+	prompt := RankingPrompt([]string{"Strategy", "RPG"}, rankCandidates)
+	for _, want := range []string{"Strategy, RPG", `"appid": 20`, `"required": ["appid", "reason"]`} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q", want)
+		}
+	}
+}
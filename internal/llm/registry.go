@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Factory creates a Client for a provider.
+type Factory func(cfg Config) Client
+
+var providers = map[string]Factory{
+	"ollama": func(cfg Config) Client { return NewOllamaClient(cfg) },
+	"openai": func(cfg Config) Client { return NewOpenAIClient(cfg) },
+}
+
+// defaultBaseURLs holds the address each built-in provider's server listens
+// on out of the box.
+var defaultBaseURLs = map[string]string{
+	"ollama": "http://localhost:11434",
+	"openai": "http://localhost:8080", // llama.cpp server
+}
+
+// DefaultBaseURL returns the base URL used for a provider when none is set,
+// or "" when the provider has no default.
+func DefaultBaseURL(provider string) string {
+	if provider == "" {
+		provider = "ollama"
+	}
+	return defaultBaseURLs[provider]
+}
+
+// Register adds or replaces a provider.
+func Register(name string, f Factory) {
+	providers[name] = f
+}
+
+// Providers returns the registered provider names, sorted.
+func Providers() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a Client for cfg.Provider. An empty BaseURL is replaced by the
+// provider's default.
+func New(cfg Config) (Client, error) {
+	name := cfg.Provider
+	if name == "" {
+		name = "ollama"
+	}
+	f, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM provider %q (use %s)", name, strings.Join(Providers(), ", "))
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL(name)
+	}
+	return f(cfg), nil
+}
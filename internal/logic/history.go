@@ -0,0 +1,78 @@
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// PeriodPlaytime is the number of minutes played within one period.
+type PeriodPlaytime struct {
+	Period  string `json:"period"`
+	Minutes int    `json:"minutes"`
+}
+
+// PeriodKey formats t as the bucket it belongs to for period "day", "week"
+// (ISO week) or "month".
+func PeriodKey(t time.Time, period string) (string, error) {
+	t = t.UTC()
+	switch period {
+	case "day":
+		return t.Format("2006-01-02"), nil
+	case "week":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), nil
+	case "month":
+		return t.Format("2006-01"), nil
+	default:
+		return "", fmt.Errorf("unknown period %q (use day, week or month)", period)
+	}
+}
+
+// PlaytimeByPeriod sums snapshot deltas into periods, oldest first.
+// Playtime is attributed to the period of the sync that observed it.
+func PlaytimeByPeriod(snapshots []model.PlaytimeSnapshot, period string) ([]PeriodPlaytime, error) {
+	totals := make(map[string]int)
+	for _, s := range snapshots {
+		key, err := PeriodKey(s.TakenAt, period)
+		if err != nil {
+			return nil, err
+		}
+		if d := s.Delta(); d > 0 {
+			totals[key] += d
+		}
+	}
+
+	result := make([]PeriodPlaytime, 0, len(totals))
+	for k, v := range totals {
+		result = append(result, PeriodPlaytime{Period: k, Minutes: v})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Period < result[j].Period
+	})
+	return result, nil
+}
+
+// StartedSinceLastSync returns the games that went from 0 minutes to some
+// playtime in the most recent sync.
+func StartedSinceLastSync(snapshots []model.PlaytimeSnapshot) []model.PlaytimeSnapshot {
+	var last time.Time
+	for _, s := range snapshots {
+		if s.TakenAt.After(last) {
+			last = s.TakenAt
+		}
+	}
+
+	var started []model.PlaytimeSnapshot
+	for _, s := range snapshots {
+		if !s.TakenAt.Equal(last) || s.PreviousPlaytime == nil {
+			continue
+		}
+		if *s.PreviousPlaytime == 0 && s.PlaytimeForever > 0 {
+			started = append(started, s)
+		}
+	}
+	return started
+}
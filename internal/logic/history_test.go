@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestPlaytimeByPeriod(t *testing.T) {
+	day1 := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC) // Monday, ISO week 2
+	day2 := time.Date(2025, 1, 7, 10, 0, 0, 0, time.UTC)
+	snapshots := []model.PlaytimeSnapshot{
+		{AppID: 1, TakenAt: day1, PlaytimeForever: 60, PreviousPlaytime: intPtr(0)},
+		{AppID: 2, TakenAt: day1, PlaytimeForever: 500}, // new game, no delta
+		{AppID: 1, TakenAt: day2, PlaytimeForever: 90, PreviousPlaytime: intPtr(60)},
+	}
+
+	daily, err := PlaytimeByPeriod(snapshots, "day")
+	if err != nil {
+		t.Fatalf("PlaytimeByPeriod error: %v", err)
+	}
+	if len(daily) != 2 || daily[0] != (PeriodPlaytime{"2025-01-06", 60}) || daily[1] != (PeriodPlaytime{"2025-01-07", 30}) {
+		t.Errorf("unexpected daily totals: %+v", daily)
+	}
+
+	weekly, _ := PlaytimeByPeriod(snapshots, "week")
+	if len(weekly) != 1 || weekly[0] != (PeriodPlaytime{"2025-W02", 90}) {
+		t.Errorf("unexpected weekly totals: %+v", weekly)
+	}
+
+	if _, err := PlaytimeByPeriod(snapshots, "year"); err == nil {
+		t.Error("expected error for unknown period")
+	}
+}
+
+func TestStartedSinceLastSync(t *testing.T) {
+	older := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	latest := older.Add(24 * time.Hour)
+	snapshots := []model.PlaytimeSnapshot{
+		{AppID: 1, TakenAt: older, PlaytimeForever: 10, PreviousPlaytime: intPtr(0)},
+		{AppID: 2, TakenAt: latest, PlaytimeForever: 20, PreviousPlaytime: intPtr(0)},
+		{AppID: 3, TakenAt: latest, PlaytimeForever: 40, PreviousPlaytime: intPtr(5)},
+		{AppID: 4, TakenAt: latest, PlaytimeForever: 15},
+	}
+
+	started := StartedSinceLastSync(snapshots)
+	if len(started) != 1 || started[0].AppID != 2 {
+		t.Errorf("expected only game 2 to be started, got %+v", started)
+	}
+}
@@ -2,22 +2,123 @@ package logic
 
 import (
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/dajoen/steam-pick/internal/model"
 )
 
-// FilterUnplayed returns games with 0 playtime.
-func FilterUnplayed(games []model.Game) []model.Game {
+// FilterUnplayed returns the backlog: games whose status is backlog, or
+// games without a status and 0 playtime. DLC, soundtracks, tools and other
+// non-game apps are left out unless includeNonGames is set.
+func FilterUnplayed(games []model.Game, includeNonGames bool) []model.Game {
 	var unplayed []model.Game
 	for _, g := range games {
-		if g.PlaytimeForever == 0 {
+		if !includeNonGames && !Classify(g).IsGame() {
+			continue
+		}
+		if InBacklog(g) {
 			unplayed = append(unplayed, g)
 		}
 	}
 	return unplayed
 }
 
+// nonGameMarkers identify non-game apps by name when the store has no
+// details for them, e.g. delisted dedicated servers.
+var nonGameMarkers = []struct {
+	marker string
+	class  model.AppClass
+}{
+	{"soundtrack", model.ClassSoundtrack},
+	{" ost", model.ClassSoundtrack},
+	{"dedicated server", model.ClassTool},
+	{" sdk", model.ClassTool},
+	{"authoring tools", model.ClassTool},
+	{" demo", model.ClassDemo},
+}
+
+// Classify returns the stored classification of a game, falling back to
+// its name when enrich has not classified it.
+func Classify(g model.Game) model.AppClass {
+	if g.Classification != "" {
+		return g.Classification
+	}
+	name := " " + strings.ToLower(g.Name)
+	for _, m := range nonGameMarkers {
+		if strings.HasSuffix(name, m.marker) || strings.Contains(name, m.marker+" ") {
+			return m.class
+		}
+	}
+	return ""
+}
+
+// InBacklog reports whether a game is still waiting to be played. An explicit
+// status wins over playtime.
+func InBacklog(g model.Game) bool {
+	if g.Status != "" {
+		return g.Status == model.StatusBacklog
+	}
+	return g.PlaytimeForever == 0
+}
+
+// ApplyClassifications copies stored classifications onto games fetched
+// from the API.
+func ApplyClassifications(games []model.Game, classes map[int]model.AppClass) {
+	for i := range games {
+		if c, ok := classes[games[i].AppID]; ok {
+			games[i].Classification = c
+		}
+	}
+}
+
+// ApplyStatuses copies stored statuses onto games fetched from the API.
+func ApplyStatuses(games []model.Game, statuses map[int]model.Status) {
+	for i := range games {
+		if st, ok := statuses[games[i].AppID]; ok {
+			games[i].Status = st
+		}
+	}
+}
+
+// ExcludeApps drops the games whose appid is in excluded.
+func ExcludeApps(games []model.Game, excluded map[int]bool) []model.Game {
+	if len(excluded) == 0 {
+		return games
+	}
+	var kept []model.Game
+	for _, g := range games {
+		if !excluded[g.AppID] {
+			kept = append(kept, g)
+		}
+	}
+	return kept
+}
+
+// IsTurnBased reports whether any genre or category mentions turn-based play.
+func IsTurnBased(genres, categories []string) bool {
+	for _, list := range [][]string{genres, categories} {
+		for _, d := range list {
+			if strings.Contains(strings.ToLower(d), "turn") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsTurnBasedApp applies IsTurnBased to store details.
+func IsTurnBasedApp(a model.AppDetails) bool {
+	var genres, categories []string
+	for _, g := range a.Genres {
+		genres = append(genres, g.Description)
+	}
+	for _, c := range a.Categories {
+		categories = append(categories, c.Description)
+	}
+	return IsTurnBased(genres, categories)
+}
+
 // PickGame selects a random game from the list.
 // If seed is non-zero, it uses it for deterministic selection.
 func PickGame(games []model.Game, seed int64) *model.Game {
@@ -12,7 +12,7 @@ func TestFilterUnplayed(t *testing.T) {
 		{Name: "Unplayed", PlaytimeForever: 0},
 	}
 
-	unplayed := FilterUnplayed(games)
+	unplayed := FilterUnplayed(games, false)
 	if len(unplayed) != 1 {
 		t.Errorf("got %d games, want 1", len(unplayed))
 	}
@@ -21,6 +21,77 @@ func TestFilterUnplayed(t *testing.T) {
 	}
 }
 
+func TestFilterUnplayedStatus(t *testing.T) {
+	games := []model.Game{
+		{AppID: 1, Name: "Abandoned", PlaytimeForever: 20, Status: model.StatusDropped},
+		{AppID: 2, Name: "Barely started", PlaytimeForever: 20, Status: model.StatusBacklog},
+		{AppID: 3, Name: "Beaten offline", PlaytimeForever: 0},
+		{AppID: 4, Name: "Untouched", PlaytimeForever: 0},
+	}
+	ApplyStatuses(games, map[int]model.Status{3: model.StatusBeaten})
+
+	unplayed := FilterUnplayed(games, false)
+	if len(unplayed) != 2 {
+		t.Fatalf("got %d games, want 2", len(unplayed))
+	}
+	if unplayed[0].AppID != 2 || unplayed[1].AppID != 4 {
+		t.Errorf("got %+v, want games 2 and 4", unplayed)
+	}
+}
+
+func TestFilterUnplayedNonGames(t *testing.T) {
+	games := []model.Game{
+		{AppID: 1, Name: "Portal 2"},
+		{AppID: 2, Name: "Portal 2 Soundtrack"},
+		{AppID: 3, Name: "Season Pass"},
+		{AppID: 4, Name: "Counter-Strike: Source Dedicated Server"},
+		{AppID: 5, Name: "Unenriched Game"},
+	}
+	ApplyClassifications(games, map[int]model.AppClass{1: model.ClassGame, 3: model.ClassDLC})
+
+	unplayed := FilterUnplayed(games, false)
+	if len(unplayed) != 2 || unplayed[0].AppID != 1 || unplayed[1].AppID != 5 {
+		t.Errorf("got %+v, want games 1 and 5", unplayed)
+	}
+	if len(FilterUnplayed(games, true)) != 5 {
+		t.Error("includeNonGames should keep every app")
+	}
+}
+
+func TestExcludeApps(t *testing.T) {
+	games := []model.Game{{AppID: 1}, {AppID: 2}, {AppID: 3}}
+
+	kept := ExcludeApps(games, map[int]bool{2: true})
+	if len(kept) != 2 || kept[0].AppID != 1 || kept[1].AppID != 3 {
+		t.Errorf("got %+v, want games 1 and 3", kept)
+	}
+	if len(ExcludeApps(games, nil)) != 3 {
+		t.Error("nil exclusions should keep every game")
+	}
+}
+
+func TestIsTurnBasedApp(t *testing.T) {
+	a := model.AppDetails{Categories: []model.Category{{Description: "Turn-based combat"}}}
+	if !IsTurnBasedApp(a) {
+		t.Error("expected a turn-based category to match")
+	}
+	if IsTurnBasedApp(model.AppDetails{Genres: []model.Genre{{Description: "Action"}}}) {
+		t.Error("expected an action game not to match")
+	}
+}
+
+func TestIsTurnBased(t *testing.T) {
+	if !IsTurnBased([]string{"Strategy", "Turn-Based Tactics"}, nil) {
+		t.Error("expected a turn-based genre to match")
+	}
+	if !IsTurnBased(nil, []string{"Single-player", "Turn-based combat"}) {
+		t.Error("expected a turn-based category to match")
+	}
+	if IsTurnBased([]string{"Action"}, []string{"Multi-player"}) {
+		t.Error("expected a real-time game not to match")
+	}
+}
+
 func TestPickGame(t *testing.T) {
 	games := []model.Game{
 		{Name: "Game 1"},
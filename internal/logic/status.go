@@ -0,0 +1,41 @@
+package logic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// StatusStore is the part of the database status changes need.
+type StatusStore interface {
+	GetGameStatus(steamID string, appID int) (model.Status, error)
+	SetGameStatus(steamID string, appID int, status model.Status, at time.Time) error
+}
+
+// TransitionError is a status change the transition rules refuse.
+type TransitionError struct {
+	AppID    int
+	From, To model.Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot move %d from %s to %s", e.AppID, e.From, e.To)
+}
+
+// SetStatus moves a game to next and records the transition. A transition
+// the rules refuse returns a *TransitionError unless force is set; setting
+// the current status again changes nothing.
+func SetStatus(store StatusStore, steamID string, appID int, next model.Status, force bool, at time.Time) error {
+	current, err := store.GetGameStatus(steamID, appID)
+	if err != nil {
+		return err
+	}
+	if current == next {
+		return nil
+	}
+	if !force && !current.CanTransitionTo(next) {
+		return &TransitionError{AppID: appID, From: current, To: next}
+	}
+	return store.SetGameStatus(steamID, appID, next, at)
+}
@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+type fakeStatusStore struct {
+	statuses map[int]model.Status
+	writes   int
+}
+
+func (f *fakeStatusStore) GetGameStatus(steamID string, appID int) (model.Status, error) {
+	return f.statuses[appID], nil
+}
+
+func (f *fakeStatusStore) SetGameStatus(steamID string, appID int, status model.Status, at time.Time) error {
+	f.statuses[appID] = status
+	f.writes++
+	return nil
+}
+
+func TestSetStatus(t *testing.T) {
+	store := &fakeStatusStore{statuses: map[int]model.Status{}}
+	now := time.Now()
+
+	if err := SetStatus(store, "1", 620, model.StatusBeaten, false, now); err != nil {
+		t.Fatalf("first status should be allowed: %v", err)
+	}
+	err := SetStatus(store, "1", 620, model.StatusBacklog, false, now)
+	var te *TransitionError
+	if !errors.As(err, &te) || te.From != model.StatusBeaten || te.To != model.StatusBacklog {
+		t.Errorf("beaten -> backlog: got %v", err)
+	}
+	if err := SetStatus(store, "1", 620, model.StatusBeaten, false, now); err != nil || store.writes != 1 {
+		t.Errorf("setting the same status again: %v, %d writes", err, store.writes)
+	}
+	if err := SetStatus(store, "1", 620, model.StatusBacklog, true, now); err != nil || store.statuses[620] != model.StatusBacklog {
+		t.Errorf("force: got %v, status %s", err, store.statuses[620])
+	}
+}
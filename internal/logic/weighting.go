@@ -0,0 +1,109 @@
+package logic
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// Weighting controls how much a played game counts towards the taste
+// profile.
+type Weighting struct {
+	RecencyHalfLifeDays int     `json:"recency_half_life_days"`
+	MinHours            int     `json:"min_hours"`
+	CompletionWeight    float64 `json:"completion_weight"`
+}
+
+// DefaultWeighting matches the defaults of the profile command.
+var DefaultWeighting = Weighting{RecencyHalfLifeDays: 365, MinHours: 2, CompletionWeight: 2}
+
+// Weight returns how strongly g says something about taste: hours played,
+// scaled up by achievement completion and decayed by time since last
+// played. Games below MinHours weigh 0.
+func (w Weighting) Weight(g model.GameDetails, now time.Time) float64 {
+	playtimeHours := float64(g.PlaytimeForever) / 60.0
+	if playtimeHours < float64(w.MinHours) {
+		return 0
+	}
+
+	weight := playtimeHours
+	// Finishing a game is a stronger signal than hours alone, so
+	// achievement completion scales the weight up.
+	weight *= 1 + w.CompletionWeight*g.Completion()
+	if g.RTimeLastPlayed > 0 && w.RecencyHalfLifeDays > 0 {
+		halfLifeSecs := float64(w.RecencyHalfLifeDays * 24 * 3600)
+		ageSecs := float64(now.Unix() - int64(g.RTimeLastPlayed))
+		weight *= math.Pow(0.5, ageSecs/halfLifeSecs)
+	}
+	return weight
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0
+// when either is empty or their lengths differ.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// WeightedVector is the embedding of a played game with its taste weight.
+type WeightedVector struct {
+	AppID  int
+	Weight float64
+	Vector []float32
+}
+
+// TopWeighted returns the n heaviest vectors, heaviest first.
+func TopWeighted(vectors []WeightedVector, n int) []WeightedVector {
+	sorted := make([]WeightedVector, len(vectors))
+	copy(sorted, vectors)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Weight > sorted[j].Weight })
+	if n > 0 && len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// SemanticScore is the weighted mean cosine similarity of v to the liked
+// games.
+func SemanticScore(v []float32, liked []WeightedVector) float64 {
+	var sum, total float64
+	for _, l := range liked {
+		sum += l.Weight * CosineSimilarity(v, l.Vector)
+		total += l.Weight
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// EmbeddingText is the text embedded for a game: its name, genres,
+// categories and description.
+func EmbeddingText(name string, genres, categories []string, description string) string {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(genres) > 0 {
+		b.WriteString("\nGenres: " + strings.Join(genres, ", "))
+	}
+	if len(categories) > 0 {
+		b.WriteString("\nCategories: " + strings.Join(categories, ", "))
+	}
+	if description != "" {
+		b.WriteString("\n" + description)
+	}
+	return b.String()
+}
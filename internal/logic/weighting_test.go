@@ -0,0 +1,58 @@
+package logic
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+func TestWeight(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	w := Weighting{RecencyHalfLifeDays: 10, MinHours: 2, CompletionWeight: 1}
+
+	short := model.GameDetails{Game: model.Game{PlaytimeForever: 60}}
+	if got := w.Weight(short, now); got != 0 {
+		t.Errorf("games below MinHours should weigh 0, got %f", got)
+	}
+
+	g := model.GameDetails{Game: model.Game{PlaytimeForever: 600}, AchievementsUnlocked: 5, AchievementsTotal: 10}
+	if got := w.Weight(g, now); got != 15 {
+		t.Errorf("got %f, want 10h * 1.5 completion = 15", got)
+	}
+
+	g.RTimeLastPlayed = int(now.Add(-10 * 24 * time.Hour).Unix())
+	if got := w.Weight(g, now); math.Abs(got-7.5) > 1e-9 {
+		t.Errorf("got %f, want one half-life of decay = 7.5", got)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := CosineSimilarity([]float32{1, 0}, []float32{1, 0}); math.Abs(got-1) > 1e-9 {
+		t.Errorf("identical vectors: got %f, want 1", got)
+	}
+	if got := CosineSimilarity([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Errorf("orthogonal vectors: got %f, want 0", got)
+	}
+	if got := CosineSimilarity([]float32{1}, []float32{1, 0}); got != 0 {
+		t.Errorf("mismatched lengths: got %f, want 0", got)
+	}
+}
+
+func TestSemanticScore(t *testing.T) {
+	liked := TopWeighted([]WeightedVector{
+		{AppID: 1, Weight: 1, Vector: []float32{0, 1}},
+		{AppID: 2, Weight: 3, Vector: []float32{1, 0}},
+		{AppID: 3, Weight: 0.5, Vector: []float32{1, 1}},
+	}, 2)
+	if len(liked) != 2 || liked[0].AppID != 2 || liked[1].AppID != 1 {
+		t.Fatalf("unexpected top weighted: %+v", liked)
+	}
+
+	near := SemanticScore([]float32{1, 0}, liked)
+	far := SemanticScore([]float32{0, 1}, liked)
+	if math.Abs(near-0.75) > 1e-9 || math.Abs(far-0.25) > 1e-9 {
+		t.Errorf("got near=%f far=%f, want 0.75 and 0.25", near, far)
+	}
+}
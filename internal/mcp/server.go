@@ -0,0 +1,221 @@
+// Package mcp serves tools over the Model Context Protocol: JSON-RPC 2.0
+// messages, one per line, on stdin and stdout.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dajoen/steam-pick/internal/tools"
+)
+
+// ProtocolVersion is the newest protocol revision the server speaks.
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions are the revisions accepted from clients.
+var supportedVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Server answers MCP requests with the tools of a registry.
+type Server struct {
+	Name    string
+	Version string
+	Tools   *tools.Registry
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+type toolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+	Annotations toolAnnotations `json:"annotations"`
+}
+
+type toolAnnotations struct {
+	ReadOnlyHint bool `json:"readOnlyHint"`
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content []content `json:"content"`
+	IsError bool      `json:"isError"`
+}
+
+// Serve handles requests from in until it is closed or ctx is done.
+// Requests are answered in order; notifications get no answer. When ctx is
+// done Serve returns at once; a read in flight on in is abandoned.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	type read struct {
+		line []byte
+		err  error
+	}
+	reads := make(chan read)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		r := bufio.NewReader(in)
+		for {
+			line, err := r.ReadBytes('\n')
+			select {
+			case reads <- read{line, err}:
+			case <-stop:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	enc := json.NewEncoder(out)
+	for {
+		var rd read
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case rd = <-reads:
+		}
+		if len(rd.line) > 0 {
+			if res := s.handle(ctx, rd.line); res != nil {
+				if err := enc.Encode(res); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(rd.err, io.EOF) {
+			return nil
+		}
+		if rd.err != nil {
+			return rd.err
+		}
+	}
+}
+
+// handle answers one message. It returns nil for notifications and blank
+// lines.
+func (s *Server) handle(ctx context.Context, line []byte) *response {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "parse error"}}
+	}
+	if req.ID == nil {
+		return nil
+	}
+	res := &response{JSONRPC: "2.0", ID: req.ID}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		res.Error = &rpcError{Code: codeInvalidRequest, Message: "invalid request"}
+		return res
+	}
+
+	result, err := s.dispatch(ctx, req)
+	if err != nil {
+		var rerr *rpcError
+		if !errors.As(err, &rerr) {
+			rerr = &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		res.Error = rerr
+		return res
+	}
+	res.Result = result
+	return res
+}
+
+func (s *Server) dispatch(ctx context.Context, req request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		version := ProtocolVersion
+		if supportedVersions[params.ProtocolVersion] {
+			version = params.ProtocolVersion
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]bool{"listChanged": false}},
+			"serverInfo":      map[string]string{"name": s.Name, "version": s.Version},
+		}, nil
+
+	case "ping":
+		return struct{}{}, nil
+
+	case "tools/list":
+		list := []toolInfo{}
+		for _, t := range s.Tools.Tools() {
+			schema := t.Parameters
+			if len(schema) == 0 {
+				schema = json.RawMessage(`{"type": "object"}`)
+			}
+			list = append(list, toolInfo{
+				Name:        t.Name,
+				Description: t.Description,
+				InputSchema: schema,
+				Annotations: toolAnnotations{ReadOnlyHint: t.ReadOnly},
+			})
+		}
+		return map[string]interface{}{"tools": list}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid params: %w", err)
+		}
+		if _, ok := s.Tools.Get(params.Name); !ok {
+			return nil, fmt.Errorf("unknown tool %q", params.Name)
+		}
+		// Failures of the tool itself are results the model can read.
+		text, err := s.Tools.Call(ctx, params.Name, params.Arguments)
+		if err != nil {
+			return callResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+		}
+		return callResult{Content: []content{{Type: "text", Text: text}}}, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+}
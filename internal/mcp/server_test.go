@@ -0,0 +1,144 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/tools"
+)
+
+func testServer() *Server {
+	r := tools.NewRegistry()
+	r.Register(tools.Tool{
+		Name:        "greet",
+		Description: "Greets someone",
+		Parameters:  json.RawMessage(`{"type": "object", "properties": {"name": {"type": "string"}}}`),
+		ReadOnly:    true,
+		Run: func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+			var in struct {
+				Name string `json:"name"`
+			}
+			_ = json.Unmarshal(args, &in)
+			if in.Name == "" {
+				return nil, errors.New("name is required")
+			}
+			return map[string]string{"greeting": "Hello " + in.Name}, nil
+		},
+	})
+	return &Server{Name: "test", Version: "1.0", Tools: r}
+}
+
+type reply struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func serve(t *testing.T, input string) []reply {
+	t.Helper()
+	var out bytes.Buffer
+	if err := testServer().Serve(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve error: %v", err)
+	}
+	var replies []reply
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var r reply
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("invalid reply: %v", err)
+		}
+		replies = append(replies, r)
+	}
+	return replies
+}
+
+func TestServe(t *testing.T) {
+	replies := serve(t, strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2024-11-05", "capabilities": {}}}`,
+		`{"jsonrpc": "2.0", "method": "notifications/initialized"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "greet", "arguments": {"name": "Gabe"}}}`,
+		`{"jsonrpc": "2.0", "id": "four", "method": "tools/call", "params": {"name": "greet", "arguments": {}}}`,
+	}, "\n"))
+	if len(replies) != 4 {
+		t.Fatalf("got %d replies, want 4 (no reply to the notification)", len(replies))
+	}
+
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	_ = json.Unmarshal(replies[0].Result, &init)
+	if init.ProtocolVersion != "2024-11-05" || init.ServerInfo.Name != "test" {
+		t.Errorf("unexpected initialize result: %s", replies[0].Result)
+	}
+
+	var list struct {
+		Tools []toolInfo `json:"tools"`
+	}
+	_ = json.Unmarshal(replies[1].Result, &list)
+	if len(list.Tools) != 1 || list.Tools[0].Name != "greet" || !list.Tools[0].Annotations.ReadOnlyHint {
+		t.Errorf("unexpected tools/list result: %s", replies[1].Result)
+	}
+
+	var call callResult
+	_ = json.Unmarshal(replies[2].Result, &call)
+	if call.IsError || len(call.Content) != 1 || call.Content[0].Text != `{"greeting":"Hello Gabe"}` {
+		t.Errorf("unexpected tools/call result: %s", replies[2].Result)
+	}
+
+	_ = json.Unmarshal(replies[3].Result, &call)
+	if string(replies[3].ID) != `"four"` || !call.IsError || call.Content[0].Text != "name is required" {
+		t.Errorf("tool errors should be results with isError: %s", replies[3].Result)
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	replies := serve(t, strings.Join([]string{
+		`not json`,
+		`{"jsonrpc": "2.0", "id": 1, "method": "resources/list"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "missing"}}`,
+		``,
+		`{"jsonrpc": "2.0", "id": 3, "method": "ping"}`,
+	}, "\n"))
+	want := []int{codeParseError, codeMethodNotFound, codeInvalidParams, 0}
+	if len(replies) != len(want) {
+		t.Fatalf("got %d replies, want %d", len(replies), len(want))
+	}
+	for i, code := range want {
+		got := 0
+		if replies[i].Error != nil {
+			got = replies[i].Error.Code
+		}
+		if got != code {
+			t.Errorf("reply %d: got error code %d, want %d", i, got, code)
+		}
+	}
+}
+
+func TestServeReturnsOnCancelWhileReading(t *testing.T) {
+	in, w := io.Pipe()
+	defer func() { _ = w.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- testServer().Serve(ctx, in, io.Discard) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve kept waiting for input after the context was cancelled")
+	}
+}
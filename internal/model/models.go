@@ -1,5 +1,13 @@
 package model
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
 // Game represents a Steam game owned by a user.
 type Game struct {
 	AppID                    int    `json:"appid"`
@@ -15,6 +23,14 @@ type Game struct {
 	// Store details (populated later)
 	IsTurnBased bool   `json:"is_turn_based,omitempty"`
 	StoreURL    string `json:"store_url,omitempty"`
+	// Backlog status from the local database (empty when never set)
+	Status Status `json:"status,omitempty"`
+	// Kind of app from the store details (empty when not enriched)
+	Classification AppClass `json:"classification,omitempty"`
+	// Header image from the store details (empty when not enriched)
+	HeaderImage string `json:"header_image,omitempty"`
+	// Free-form note from the local database
+	Note string `json:"note,omitempty"`
 }
 
 // SteamResponse is the top-level response from GetOwnedGames.
@@ -43,14 +59,44 @@ type AppDetailsEntry struct {
 }
 
 type AppDetails struct {
-	Name                string     `json:"name"`
-	ShortDescription    string     `json:"short_description"`
-	DetailedDescription string     `json:"detailed_description"`
-	AboutTheGame        string     `json:"about_the_game"`
-	HeaderImage         string     `json:"header_image"`
-	Website             string     `json:"website"`
-	Categories          []Category `json:"categories"`
-	Genres              []Genre    `json:"genres"`
+	Type                string              `json:"type"`
+	Name                string              `json:"name"`
+	RequiredAge         json.Number         `json:"required_age,omitempty"` // A number, or a numeric string for some apps
+	IsFree              bool                `json:"is_free"`
+	DLC                 []int               `json:"dlc,omitempty"`
+	ControllerSupport   string              `json:"controller_support,omitempty"`
+	ShortDescription    string              `json:"short_description"`
+	DetailedDescription string              `json:"detailed_description"`
+	AboutTheGame        string              `json:"about_the_game"`
+	SupportedLanguages  string              `json:"supported_languages,omitempty"` // HTML
+	HeaderImage         string              `json:"header_image"`
+	Website             string              `json:"website"`
+	Background          string              `json:"background"`
+	PCRequirements      json.RawMessage     `json:"pc_requirements,omitempty"` // Object, or [] when empty
+	MacRequirements     json.RawMessage     `json:"mac_requirements,omitempty"`
+	LinuxRequirements   json.RawMessage     `json:"linux_requirements,omitempty"`
+	Developers          []string            `json:"developers"`
+	Publishers          []string            `json:"publishers"`
+	Fullgame            *Fullgame           `json:"fullgame,omitempty"` // Set on DLC and demos
+	Packages            []int               `json:"packages,omitempty"`
+	PriceOverview       *PriceOverview      `json:"price_overview,omitempty"`
+	Platforms           Platforms           `json:"platforms"`
+	Metacritic          *Metacritic         `json:"metacritic,omitempty"`
+	Categories          []Category          `json:"categories"`
+	Genres              []Genre             `json:"genres"`
+	Screenshots         []Screenshot        `json:"screenshots"`
+	Movies              json.RawMessage     `json:"movies,omitempty"`
+	Recommendations     *Recommendations    `json:"recommendations,omitempty"`
+	Achievements        *AchievementSummary `json:"achievements,omitempty"`
+	ReleaseDate         ReleaseDate         `json:"release_date"`
+	SupportInfo         SupportInfo         `json:"support_info"`
+	ContentDescriptors  ContentDescriptors  `json:"content_descriptors"`
+}
+
+// Fullgame is the game a DLC or demo belongs to.
+type Fullgame struct {
+	AppID string `json:"appid"`
+	Name  string `json:"name"`
 }
 
 type Category struct {
@@ -63,8 +109,415 @@ type Genre struct {
 	Description string `json:"description"`
 }
 
+// PriceOverview holds prices in the smallest currency unit, e.g. cents.
+type PriceOverview struct {
+	Currency         string `json:"currency"`
+	Initial          int    `json:"initial"`
+	Final            int    `json:"final"`
+	DiscountPercent  int    `json:"discount_percent"`
+	InitialFormatted string `json:"initial_formatted"`
+	FinalFormatted   string `json:"final_formatted"`
+}
+
+type Platforms struct {
+	Windows bool `json:"windows"`
+	Mac     bool `json:"mac"`
+	Linux   bool `json:"linux"`
+}
+
+type Metacritic struct {
+	Score int    `json:"score"`
+	URL   string `json:"url"`
+}
+
+type Screenshot struct {
+	ID            int    `json:"id"`
+	PathThumbnail string `json:"path_thumbnail"`
+	PathFull      string `json:"path_full"`
+}
+
+type Recommendations struct {
+	Total int `json:"total"`
+}
+
+type AchievementSummary struct {
+	Total int `json:"total"`
+}
+
+type ReleaseDate struct {
+	ComingSoon bool   `json:"coming_soon"`
+	Date       string `json:"date"`
+}
+
+type SupportInfo struct {
+	URL   string `json:"url"`
+	Email string `json:"email"`
+}
+
+type ContentDescriptors struct {
+	IDs   []int  `json:"ids"`
+	Notes string `json:"notes"`
+}
+
 type GameDetails struct {
 	Game
-	Genres     string `json:"genres"`     // Raw JSON string from DB
-	Categories string `json:"categories"` // Raw JSON string from DB
+	Genres               string `json:"genres"`      // Raw JSON string from DB
+	Categories           string `json:"categories"`  // Raw JSON string from DB
+	Platforms            string `json:"platforms"`   // Raw JSON object from DB
+	HasDetails           bool   `json:"has_details"` // Whether enrich stored store details
+	AchievementsUnlocked int    `json:"achievements_unlocked,omitempty"`
+	AchievementsTotal    int    `json:"achievements_total,omitempty"`
+}
+
+// GenreNames returns the genre descriptions of the game.
+func (g GameDetails) GenreNames() []string {
+	var genres []Genre
+	_ = json.Unmarshal([]byte(g.Genres), &genres)
+	names := make([]string, 0, len(genres))
+	for _, genre := range genres {
+		names = append(names, genre.Description)
+	}
+	return names
+}
+
+// CategoryNames returns the category descriptions of the game.
+func (g GameDetails) CategoryNames() []string {
+	var categories []Category
+	_ = json.Unmarshal([]byte(g.Categories), &categories)
+	names := make([]string, 0, len(categories))
+	for _, c := range categories {
+		names = append(names, c.Description)
+	}
+	return names
+}
+
+// PlatformNames returns the platforms the game supports natively, e.g.
+// "windows" and "linux".
+func (g GameDetails) PlatformNames() []string {
+	var platforms map[string]bool
+	_ = json.Unmarshal([]byte(g.Platforms), &platforms)
+	var names []string
+	for name, ok := range platforms {
+		if ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Completion returns the fraction of achievements unlocked, or 0 for games
+// without achievements.
+func (g GameDetails) Completion() float64 {
+	if g.AchievementsTotal == 0 {
+		return 0
+	}
+	return float64(g.AchievementsUnlocked) / float64(g.AchievementsTotal)
+}
+
+// PlaytimeSnapshot is a game's playtime as recorded by one sync.
+type PlaytimeSnapshot struct {
+	AppID                  int       `json:"appid"`
+	Name                   string    `json:"name"`
+	TakenAt                time.Time `json:"taken_at"`
+	PlaytimeForever        int       `json:"playtime_forever"`
+	PreviousPlaytime       *int      `json:"previous_playtime_forever,omitempty"` // nil when the game was new
+	PlaytimeWindowsForever int       `json:"playtime_windows_forever"`
+	PlaytimeMacForever     int       `json:"playtime_mac_forever"`
+	PlaytimeLinuxForever   int       `json:"playtime_linux_forever"`
+	PlaytimeDeckForever    int       `json:"playtime_deck_forever"`
+}
+
+// Delta returns the minutes played since the previous snapshot.
+func (s PlaytimeSnapshot) Delta() int {
+	if s.PreviousPlaytime == nil {
+		return 0
+	}
+	return s.PlaytimeForever - *s.PreviousPlaytime
+}
+
+// PlayerAchievementsResponse is the top-level response from GetPlayerAchievements.
+type PlayerAchievementsResponse struct {
+	PlayerStats struct {
+		SteamID      string              `json:"steamID"`
+		GameName     string              `json:"gameName"`
+		Achievements []PlayerAchievement `json:"achievements"`
+		Success      bool                `json:"success"`
+		Error        string              `json:"error"`
+	} `json:"playerstats"`
+}
+
+type PlayerAchievement struct {
+	APIName    string `json:"apiname"`
+	Achieved   int    `json:"achieved"`
+	UnlockTime int64  `json:"unlocktime"`
+}
+
+// SchemaResponse is the top-level response from GetSchemaForGame.
+type SchemaResponse struct {
+	Game struct {
+		GameName           string `json:"gameName"`
+		AvailableGameStats struct {
+			Achievements []SchemaAchievement `json:"achievements"`
+		} `json:"availableGameStats"`
+	} `json:"game"`
+}
+
+type SchemaAchievement struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	Hidden      int    `json:"hidden"`
+}
+
+// AchievementUnlock is a single unlocked achievement.
+type AchievementUnlock struct {
+	APIName     string    `json:"apiname"`
+	DisplayName string    `json:"display_name"`
+	UnlockedAt  time.Time `json:"unlocked_at"`
+}
+
+// AchievementProgress is the achievement completion of one game.
+type AchievementProgress struct {
+	AppID      int        `json:"appid"`
+	Name       string     `json:"name"`
+	Unlocked   int        `json:"unlocked"`
+	Total      int        `json:"total"`
+	LastUnlock *time.Time `json:"last_unlock,omitempty"`
+}
+
+// Completion returns the fraction of achievements unlocked.
+func (p AchievementProgress) Completion() float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return float64(p.Unlocked) / float64(p.Total)
+}
+
+// Status is where a game stands in the backlog lifecycle.
+type Status string
+
+const (
+	StatusBacklog Status = "backlog"
+	StatusPlaying Status = "playing"
+	StatusBeaten  Status = "beaten"
+	StatusDropped Status = "dropped"
+)
+
+// Statuses lists every valid status.
+var Statuses = []Status{StatusBacklog, StatusPlaying, StatusBeaten, StatusDropped}
+
+// statusTransitions lists the statuses each status may move to. Games
+// without a status may move to any status.
+var statusTransitions = map[Status][]Status{
+	StatusBacklog: {StatusPlaying, StatusBeaten, StatusDropped},
+	StatusPlaying: {StatusBacklog, StatusBeaten, StatusDropped},
+	StatusBeaten:  {StatusPlaying},
+	StatusDropped: {StatusBacklog, StatusPlaying},
+}
+
+// ParseStatus validates a status name.
+func ParseStatus(s string) (Status, error) {
+	for _, st := range Statuses {
+		if string(st) == s {
+			return st, nil
+		}
+	}
+	return "", fmt.Errorf("unknown status %q (use backlog, playing, beaten or dropped)", s)
+}
+
+// CanTransitionTo reports whether a game may move from s to next.
+func (s Status) CanTransitionTo(next Status) bool {
+	if s == "" {
+		return true
+	}
+	for _, st := range statusTransitions[s] {
+		if st == next {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange is one recorded status transition.
+type StatusChange struct {
+	AppID     int       `json:"appid"`
+	From      Status    `json:"from,omitempty"`
+	To        Status    `json:"to"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// PickRecord is one game chosen by pick.
+type PickRecord struct {
+	AppID    int               `json:"appid"`
+	Name     string            `json:"name"`
+	PickedAt time.Time         `json:"picked_at"`
+	Seed     int64             `json:"seed"`
+	Flags    map[string]string `json:"flags,omitempty"`
+}
+
+// AppClass says what kind of app an owned entry is. The empty class means
+// the store details are not known.
+type AppClass string
+
+const (
+	ClassGame       AppClass = "game"
+	ClassDLC        AppClass = "dlc"
+	ClassDemo       AppClass = "demo"
+	ClassSoundtrack AppClass = "soundtrack"
+	ClassVideo      AppClass = "video"
+	ClassTool       AppClass = "tool"
+	ClassOther      AppClass = "other"
+)
+
+// IsGame reports whether the class is a playable game. Unknown apps count
+// as games so unenriched libraries are not emptied.
+func (c AppClass) IsGame() bool {
+	return c == ClassGame || c == ""
+}
+
+// softwareGenres are the store genres used for non-game software.
+var softwareGenres = map[string]bool{
+	"Utilities":             true,
+	"Game Development":      true,
+	"Software Training":     true,
+	"Audio Production":      true,
+	"Video Production":      true,
+	"Design & Illustration": true,
+	"Animation & Modeling":  true,
+	"Photo Editing":         true,
+	"Web Publishing":        true,
+	"Accounting":            true,
+	"Education":             true,
+}
+
+// ClassifyApp derives the class of an app from its store type, categories
+// and genres.
+func ClassifyApp(a AppDetails) AppClass {
+	switch strings.ToLower(a.Type) {
+	case "dlc":
+		return ClassDLC
+	case "demo":
+		return ClassDemo
+	case "music":
+		return ClassSoundtrack
+	case "video", "series", "episode":
+		return ClassVideo
+	case "mod", "advertising", "hardware":
+		return ClassOther
+	}
+
+	for _, c := range a.Categories {
+		if c.Description == "Downloadable Content" {
+			return ClassDLC
+		}
+	}
+
+	// Software sold as "game" is only recognisable by its genres.
+	if len(a.Genres) > 0 {
+		software := true
+		for _, g := range a.Genres {
+			if !softwareGenres[g.Description] {
+				software = false
+				break
+			}
+		}
+		if software {
+			return ClassTool
+		}
+	}
+
+	if a.Type == "" {
+		return ""
+	}
+	return ClassGame
+}
+
+// RecommendationRun is one stored run of the recommend command.
+type RecommendationRun struct {
+	ID             int64                  `json:"id"`
+	CreatedAt      time.Time              `json:"created_at"`
+	Strategy       string                 `json:"strategy"`
+	Mode           string                 `json:"mode"`
+	Model          string                 `json:"model,omitempty"`
+	ProfileVersion string                 `json:"profile_version,omitempty"`
+	Results        []RecommendationResult `json:"results,omitempty"`
+}
+
+// RecommendationResult is one recommended game of a run, in rank order.
+type RecommendationResult struct {
+	AppID  int     `json:"appid"`
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+// Recommendation is a recommended game with its score and, when explained
+// or re-ranked, the reason.
+type Recommendation struct {
+	AppID       int     `json:"appid"`
+	Name        string  `json:"name"`
+	Score       float64 `json:"score"`
+	Explanation string  `json:"explanation,omitempty"`
+}
+
+// GenreScore is a genre's weight in the taste profile.
+type GenreScore struct {
+	Key   string
+	Value float64
+}
+
+// GameInfo is an owned game with its stored store details.
+type GameInfo struct {
+	AppID           int         `json:"appid"`
+	Name            string      `json:"name"`
+	PlaytimeMinutes int         `json:"playtime_minutes"`
+	Status          Status      `json:"status,omitempty"`
+	Classification  AppClass    `json:"classification,omitempty"`
+	Note            string      `json:"note,omitempty"`
+	Details         *AppDetails `json:"details,omitempty"`
+	// DetailSources maps detail fields to the enrich source that supplied them.
+	DetailSources map[string]string `json:"detail_sources,omitempty"`
+}
+
+// SyncResult summarises a library sync.
+type SyncResult struct {
+	New       int    `json:"new"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"`
+	Removed   []Game `json:"removed"`
+}
+
+// ChatSession is a stored conversation of the chat command.
+type ChatSession struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Title     string    `json:"title"`
+}
+
+// InstalledGame is an app installed in a local Steam library.
+type InstalledGame struct {
+	AppID       int       `json:"appid"`
+	Name        string    `json:"name"`
+	LibraryPath string    `json:"library_path"`
+	InstallDir  string    `json:"install_dir"`
+	SizeOnDisk  int64     `json:"size_on_disk"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// Tag sources: tags created with 'tag add', and collections imported from
+// the Steam client.
+const (
+	TagSourceUser  = "steam-pick"
+	TagSourceSteam = "steam"
+)
+
+// Tag is a user tag and the games it holds.
+type Tag struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	// CollectionID links the tag to a Steam collection, e.g. "uc-1a2b3c4d5e6f".
+	CollectionID string `json:"collection_id,omitempty"`
+	AppIDs       []int  `json:"appids"`
 }
@@ -3,6 +3,7 @@ package pcgw
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -28,9 +29,13 @@ func NewClient() *Client {
 	}
 }
 
+// ErrNotFound is returned when the wiki has no page for an app.
+var ErrNotFound = errors.New("app not found on PCGamingWiki")
+
 type CargoResponse struct {
 	CargoQuery []struct {
 		Title struct {
+			Page       string `json:"Page"`
 			SteamAppID string `json:"Steam_AppID"`
 			Developers string `json:"Developers"`
 			Publishers string `json:"Publishers"`
@@ -39,12 +44,14 @@ type CargoResponse struct {
 	} `json:"cargoquery"`
 }
 
-func (c *Client) GetAppDetails(ctx context.Context, appID int) (*model.AppDetailsResponse, error) {
+// GetAppDetails returns the name, developers, publishers and genres the
+// wiki lists for a Steam app. Other fields are left empty.
+func (c *Client) GetAppDetails(ctx context.Context, appID int) (*model.AppDetails, error) {
 	u, _ := url.Parse(baseURL)
 	q := u.Query()
 	q.Set("action", "cargoquery")
 	q.Set("tables", "Infobox_game")
-	q.Set("fields", "Steam_AppID,Developers,Publishers,Genres")
+	q.Set("fields", "Infobox_game._pageName=Page,Steam_AppID,Developers,Publishers,Genres")
 	q.Set("where", fmt.Sprintf("Steam_AppID HOLDS \"%d\"", appID))
 	q.Set("format", "json")
 	u.RawQuery = q.Encode()
@@ -73,42 +80,34 @@ func (c *Client) GetAppDetails(ctx context.Context, appID int) (*model.AppDetail
 	}
 
 	if len(result.CargoQuery) == 0 {
-		return nil, fmt.Errorf("no results found for appid %d", appID)
+		return nil, fmt.Errorf("appid %d: %w", appID, ErrNotFound)
 	}
 
 	data := result.CargoQuery[0].Title
 
-	// Convert to model.AppDetailsResponse format
-	// Note: PCGW genres are comma separated strings like "ARPG,"
-	// We need to clean them up.
-
-	genres := []model.Genre{}
-	rawGenres := strings.Split(data.Genres, ",")
-	for _, g := range rawGenres {
-		g = strings.TrimSpace(g)
-		if g != "" {
-			genres = append(genres, model.Genre{Description: g})
-		}
+	var genres []model.Genre
+	for _, g := range splitList(data.Genres) {
+		genres = append(genres, model.Genre{Description: g})
 	}
 
-	// Developers/Publishers in PCGW are often "Company:Name"
-	// We don't have a field for Dev/Pub in our AppDetails struct yet (it's in the JSON but not fully typed in some versions)
-	// But we do have Categories and Genres.
-
-	// We'll construct a minimal AppDetails
-	details := model.AppDetails{
-		Name:                "Fetched from PCGamingWiki", // We don't get the name from this query easily, but we have it in DB
-		DetailedDescription: "Data fetched from PCGamingWiki because Steam Store page is unavailable.",
-		ShortDescription:    "Data fetched from PCGamingWiki.",
-		Genres:              genres,
-		Categories:          []model.Category{}, // PCGW doesn't map 1:1 to Steam Categories easily
-	}
+	return &model.AppDetails{
+		Name:       data.Page,
+		Developers: splitList(data.Developers),
+		Publishers: splitList(data.Publishers),
+		Genres:     genres,
+	}, nil
+}
 
-	response := make(model.AppDetailsResponse)
-	response[fmt.Sprintf("%d", appID)] = model.AppDetailsEntry{
-		Success: true,
-		Data:    details,
+// splitList splits a comma separated Cargo list such as
+// "Company:Valve,Company:Hidden Path", dropping the namespace prefixes
+// of company pages.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(item), "Company:"))
+		if item != "" {
+			items = append(items, item)
+		}
 	}
-
-	return &response, nil
+	return items
 }
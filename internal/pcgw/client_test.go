@@ -0,0 +1,60 @@
+package pcgw
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// Transport to redirect requests to test server
+type TestTransport struct {
+	TargetURL string
+}
+
+func (t *TestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u, _ := req.URL.Parse(t.TargetURL)
+	req.URL.Scheme = u.Scheme
+	req.URL.Host = u.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClient_GetAppDetails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("where") == `Steam_AppID HOLDS "620"` {
+			_, _ = w.Write([]byte(`{"cargoquery":[{"title":{
+				"Page":"Portal 2",
+				"Steam_AppID":"620",
+				"Developers":"Company:Valve Corporation",
+				"Publishers":"Company:Valve Corporation,Company:Electronic Arts",
+				"Genres":"Puzzle, Platformer,"
+			}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"cargoquery":[]}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient()
+	c.httpClient.Transport = &TestTransport{TargetURL: ts.URL}
+
+	d, err := c.GetAppDetails(context.Background(), 620)
+	if err != nil {
+		t.Fatalf("GetAppDetails: %v", err)
+	}
+	if d.Name != "Portal 2" || d.ShortDescription != "" {
+		t.Errorf("got name %q, description %q", d.Name, d.ShortDescription)
+	}
+	if !reflect.DeepEqual(d.Publishers, []string{"Valve Corporation", "Electronic Arts"}) {
+		t.Errorf("publishers: got %v", d.Publishers)
+	}
+	if len(d.Genres) != 2 || d.Genres[1].Description != "Platformer" {
+		t.Errorf("genres: got %v", d.Genres)
+	}
+
+	if _, err := c.GetAppDetails(context.Background(), 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown app: got %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,107 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	listField
+	numberField
+)
+
+type field struct {
+	kind   fieldKind
+	text   func(g model.GameDetails) string
+	list   func(g model.GameDetails) []string
+	number func(g model.GameDetails) float64
+}
+
+var fields = map[string]field{
+	"name":     {kind: textField, text: func(g model.GameDetails) string { return g.Name }},
+	"status":   {kind: textField, text: effectiveStatus},
+	"genre":    {kind: listField, list: model.GameDetails.GenreNames},
+	"category": {kind: listField, list: model.GameDetails.CategoryNames},
+	"platform": {kind: listField, list: model.GameDetails.PlatformNames},
+	"appid":    {kind: numberField, number: func(g model.GameDetails) float64 { return float64(g.AppID) }},
+	"playtime": {kind: numberField, number: func(g model.GameDetails) float64 { return float64(g.PlaytimeForever) }},
+	"completion": {kind: numberField, number: func(g model.GameDetails) float64 {
+		return g.Completion() * 100
+	}},
+}
+
+// effectiveStatus treats unplayed games without a status as backlog, the
+// same way list and pick do.
+func effectiveStatus(g model.GameDetails) string {
+	if g.Status != "" {
+		return string(g.Status)
+	}
+	if logic.InBacklog(g.Game) {
+		return string(model.StatusBacklog)
+	}
+	return ""
+}
+
+func (f field) compile(op, value token) (Predicate, error) {
+	want := value.text
+	switch f.kind {
+	case textField:
+		switch op.text {
+		case ":":
+			return func(g model.GameDetails) bool { return containsFold(f.text(g), want) }, nil
+		case "=":
+			return func(g model.GameDetails) bool { return strings.EqualFold(f.text(g), want) }, nil
+		case "!=":
+			return func(g model.GameDetails) bool { return !strings.EqualFold(f.text(g), want) }, nil
+		}
+	case listField:
+		switch op.text {
+		case ":":
+			return func(g model.GameDetails) bool { return anyMatch(f.list(g), want, containsFold) }, nil
+		case "=":
+			return func(g model.GameDetails) bool { return anyMatch(f.list(g), want, strings.EqualFold) }, nil
+		case "!=":
+			return func(g model.GameDetails) bool { return !anyMatch(f.list(g), want, strings.EqualFold) }, nil
+		}
+	case numberField:
+		n, err := strconv.ParseFloat(want, 64)
+		if err != nil {
+			return nil, &Error{value.pos, fmt.Sprintf("expected a number, got %q", want)}
+		}
+		cmp, ok := numberOps[op.text]
+		if ok {
+			return func(g model.GameDetails) bool { return cmp(f.number(g), n) }, nil
+		}
+	}
+	return nil, &Error{op.pos, fmt.Sprintf("operator %q is not supported for this field", op.text)}
+}
+
+var numberOps = map[string]func(a, b float64) bool{
+	":":  func(a, b float64) bool { return a == b },
+	"=":  func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func anyMatch(values []string, want string, match func(a, b string) bool) bool {
+	for _, v := range values {
+		if match(v, want) {
+			return true
+		}
+	}
+	return false
+}
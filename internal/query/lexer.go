@@ -0,0 +1,119 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// Error is a syntax or type error with the byte offset it was found at.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("query: %s at position %d", e.Msg, e.Pos)
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == ':' || c == '=':
+			tokens = append(tokens, token{tokOp, string(c), i})
+			i++
+		case c == '!' || c == '<' || c == '>':
+			if i+1 < len(input) && input[i+1] == '=' {
+				tokens = append(tokens, token{tokOp, input[i : i+2], i})
+				i += 2
+				continue
+			}
+			if c == '!' {
+				return nil, &Error{i, "expected '=' after '!'"}
+			}
+			tokens = append(tokens, token{tokOp, string(c), i})
+			i++
+		case c == '"' || c == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(input) {
+					return nil, &Error{start, "unterminated string"}
+				}
+				r, n := utf8.DecodeRuneInString(input[i:])
+				if r == c {
+					i += n
+					break
+				}
+				if r == '\\' && i+n < len(input) {
+					i += n
+					r, n = utf8.DecodeRuneInString(input[i:])
+				}
+				sb.WriteRune(r)
+				i += n
+			}
+			tokens = append(tokens, token{tokString, sb.String(), start})
+		case isWordChar(c):
+			start := i
+			for i < len(input) {
+				r, n := utf8.DecodeRuneInString(input[i:])
+				if !isWordChar(r) {
+					break
+				}
+				i += n
+			}
+			word := input[start:i]
+			kind := tokWord
+			switch strings.ToLower(word) {
+			case "and":
+				kind = tokAnd
+			case "or":
+				kind = tokOr
+			case "not":
+				kind = tokNot
+			}
+			tokens = append(tokens, token{kind, word, start})
+		default:
+			return nil, &Error{i, fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	tokens = append(tokens, token{tokEOF, "", len(input)})
+	return tokens, nil
+}
+
+func isWordChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '-' || c == '.' || c == '+'
+}
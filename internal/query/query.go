@@ -0,0 +1,145 @@
+// Package query parses filter expressions such as
+//
+//	genre:RPG and category:"Full controller support" and platform:linux and playtime<120
+//
+// into predicates over enriched games.
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// Predicate reports whether a game matches an expression.
+type Predicate func(g model.GameDetails) bool
+
+// Parse compiles an expression. An empty expression matches every game.
+func Parse(input string) (Predicate, error) {
+	if strings.TrimSpace(input) == "" {
+		return func(model.GameDetails) bool { return true }, nil
+	}
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &Error{t.pos, fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return pred, nil
+}
+
+// Fields returns the names of the fields expressions can use.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(g model.GameDetails) bool { return l(g) || right(g) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Predicate, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(g model.GameDetails) bool { return l(g) && right(g) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Predicate, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(g model.GameDetails) bool { return !inner(g) }, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Predicate, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, &Error{closing.pos, "expected ')'"}
+		}
+		return inner, nil
+	case tokWord:
+		return p.parseTerm(t)
+	case tokEOF:
+		return nil, &Error{t.pos, "unexpected end of expression"}
+	default:
+		return nil, &Error{t.pos, fmt.Sprintf("unexpected %q", t.text)}
+	}
+}
+
+func (p *parser) parseTerm(name token) (Predicate, error) {
+	f, ok := fields[strings.ToLower(name.text)]
+	if !ok {
+		return nil, &Error{name.pos, fmt.Sprintf("unknown field %q", name.text)}
+	}
+	op := p.next()
+	if op.kind != tokOp {
+		return nil, &Error{op.pos, fmt.Sprintf("expected operator after %q", name.text)}
+	}
+	value := p.next()
+	if value.kind != tokWord && value.kind != tokString {
+		return nil, &Error{value.pos, fmt.Sprintf("expected value after %q", name.text+op.text)}
+	}
+	return f.compile(op, value)
+}
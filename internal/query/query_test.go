@@ -0,0 +1,127 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+var rpg = model.GameDetails{
+	Game:       model.Game{AppID: 10, Name: "Divinity: Original Sin 2", PlaytimeForever: 30},
+	Genres:     `[{"id":"3","description":"RPG"},{"id":"2","description":"Strategy"}]`,
+	Categories: `[{"id":28,"description":"Full controller support"}]`,
+	Platforms:  `{"windows":true,"mac":true,"linux":false}`,
+}
+
+var cafe = model.GameDetails{
+	Game:       model.Game{AppID: 30, Name: "Café Über Ünïcode"},
+	Genres:     `[{"id":"9","description":"Simulación"}]`,
+	Categories: `[]`,
+	Platforms:  `{"windows":true}`,
+}
+
+var shooter = model.GameDetails{
+	Game:       model.Game{AppID: 20, Name: "Half-Life", PlaytimeForever: 600, Status: model.StatusBeaten},
+	Genres:     `[{"id":"1","description":"Action"}]`,
+	Categories: `[]`,
+	Platforms:  `{"windows":true,"linux":true}`,
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		rpg     bool
+		shooter bool
+	}{
+		{``, true, true},
+		{`genre:RPG`, true, false},
+		{`genre:strat`, true, false},
+		{`genre=strat`, false, false},
+		{`category:"Full controller support"`, true, false},
+		{`platform:linux`, false, true},
+		{`playtime<120`, true, false},
+		{`playtime >= 600`, false, true},
+		{`genre:RPG and playtime<120`, true, false},
+		{`genre:RPG or platform:linux`, true, true},
+		{`not genre:RPG`, false, true},
+		{`(genre:Action or genre:RPG) and not status=beaten`, true, false},
+		{`status:backlog`, false, false},
+		{`name:"half-life" AND completion=0`, false, true},
+		{`genre!=Action`, true, false},
+		{`appid=10`, true, false},
+	}
+
+	for _, tt := range tests {
+		pred, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if got := pred(rpg); got != tt.rpg {
+			t.Errorf("Parse(%q)(rpg) = %v, want %v", tt.expr, got, tt.rpg)
+		}
+		if got := pred(shooter); got != tt.shooter {
+			t.Errorf("Parse(%q)(shooter) = %v, want %v", tt.expr, got, tt.shooter)
+		}
+	}
+}
+
+func TestParseNonASCII(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`name:Café`, true},
+		{`name:café and genre:simulación`, true},
+		{`name:"Über Ünïcode"`, true},
+		{`name:'Caf\é'`, true},
+		{`name:Über`, true},
+		{`name:Cafe`, false},
+	}
+	for _, tt := range tests {
+		pred, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if got := pred(cafe); got != tt.want {
+			t.Errorf("Parse(%q)(cafe) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	// Positions stay byte offsets after multi-byte runes.
+	_, err := Parse(`name:Café §`)
+	var qerr *Error
+	if !errors.As(err, &qerr) || qerr.Pos != 11 {
+		t.Errorf("Parse error: got %v, want position 11", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{`colour:red`, 0},
+		{`playtime<lots`, 9},
+		{`genre<RPG`, 5},
+		{`genre:RPG and`, 13},
+		{`(genre:RPG`, 10},
+		{`name:"unterminated`, 5},
+		{`genre:RPG genre:Action`, 10},
+		{`playtime ! 5`, 9},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		var qerr *Error
+		if !errors.As(err, &qerr) {
+			t.Errorf("Parse(%q) expected *Error, got %v", tt.expr, err)
+			continue
+		}
+		if qerr.Pos != tt.pos {
+			t.Errorf("Parse(%q) error at %d, want %d (%v)", tt.expr, qerr.Pos, tt.pos, err)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+// Package server exposes the library as a JSON REST API and a web dashboard.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// GamesQuery filters GET /games.
+type GamesQuery struct {
+	UnplayedOnly    bool
+	IncludeNonGames bool
+	Where           string
+	Limit           int
+}
+
+// PickRequest is the body of POST /pick.
+type PickRequest struct {
+	TurnBasedOnly   bool   `json:"turn_based_only"`
+	Where           string `json:"where"`
+	ExcludeRecent   string `json:"exclude_recent"`
+	IncludeNonGames bool   `json:"include_non_games"`
+	Seed            int64  `json:"seed"`
+	// Record stores the pick in the pick history.
+	Record bool `json:"-"`
+	// Source names the front end that made the pick, e.g. "serve"; it is
+	// stored with recorded picks.
+	Source string `json:"-"`
+}
+
+// RecommendRequest filters GET /recommendations.
+type RecommendRequest struct {
+	Top      int
+	Mode     string
+	Strategy string
+	Where    string
+	Explain  bool
+	// Record stores the run and caches explanations.
+	Record bool
+}
+
+// GenreWeight is a genre's weight in the taste profile, as returned by
+// GET /profile.
+type GenreWeight struct {
+	Key   string  `json:"key"`
+	Value float64 `json:"value"`
+}
+
+// Service is the library behind the API.
+type Service interface {
+	Games(ctx context.Context, q GamesQuery) ([]model.Game, error)
+	// Game returns an owned game, or an error wrapped by NotFound.
+	Game(ctx context.Context, appID int) (*model.GameInfo, error)
+	Pick(ctx context.Context, req PickRequest) (*model.Game, error)
+	Recommendations(ctx context.Context, req RecommendRequest) ([]model.Recommendation, error)
+	Profile(ctx context.Context) ([]GenreWeight, error)
+	Sync(ctx context.Context) (*model.SyncResult, error)
+	// SetStatus and SetNote change a game and return it.
+	SetStatus(ctx context.Context, appID int, status string) (*model.GameInfo, error)
+	SetNote(ctx context.Context, appID int, note string) (*model.GameInfo, error)
+}
+
+// Error gives a service error an HTTP status.
+type Error struct {
+	Status int
+	Err    error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+// NotFound marks err as a 404.
+func NotFound(err error) error { return &Error{Status: http.StatusNotFound, Err: err} }
+
+// BadRequest marks err as a 400.
+func BadRequest(err error) error { return &Error{Status: http.StatusBadRequest, Err: err} }
+
+// Server serves the API and the web dashboard. In read-only mode nothing
+// is written to the database: changes and POST /sync are refused and picks
+// are not recorded. Recommendation runs are never recorded, since GET
+// requests must not change anything.
+type Server struct {
+	Service  Service
+	ReadOnly bool
+}
+
+// Handler returns the API routes and the dashboard at /.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /info", s.info)
+	mux.HandleFunc("GET /games", s.games)
+	mux.HandleFunc("GET /games/{appid}", s.game)
+	mux.HandleFunc("PUT /games/{appid}/status", s.setStatus)
+	mux.HandleFunc("PUT /games/{appid}/note", s.setNote)
+	mux.HandleFunc("POST /pick", s.pick)
+	mux.HandleFunc("GET /recommendations", s.recommendations)
+	mux.HandleFunc("GET /profile", s.profile)
+	mux.HandleFunc("POST /sync", s.sync)
+	mux.Handle("GET /", dashboard())
+	return mux
+}
+
+// ListenAndServe serves on addr until ctx is done, then waits up to
+// shutdownTimeout for requests in flight.
+func (s *Server) ListenAndServe(ctx context.Context, addr string, shutdownTimeout time.Duration) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln, shutdownTimeout)
+}
+
+// Serve is ListenAndServe on an existing listener.
+func (s *Server) Serve(ctx context.Context, ln net.Listener, shutdownTimeout time.Duration) error {
+	srv := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) info(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]bool{"read_only": s.ReadOnly})
+}
+
+func (s *Server) games(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := GamesQuery{Where: q.Get("where")}
+	var err error
+	if query.UnplayedOnly, err = boolParam(q.Get("unplayed")); err != nil {
+		writeError(w, BadRequest(fmt.Errorf("unplayed: %w", err)))
+		return
+	}
+	if query.IncludeNonGames, err = boolParam(q.Get("include_non_games")); err != nil {
+		writeError(w, BadRequest(fmt.Errorf("include_non_games: %w", err)))
+		return
+	}
+	if query.Limit, err = intParam(q.Get("limit"), 0); err != nil {
+		writeError(w, BadRequest(fmt.Errorf("limit: %w", err)))
+		return
+	}
+
+	games, err := s.Service.Games(r.Context(), query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if games == nil {
+		games = []model.Game{}
+	}
+	writeJSON(w, http.StatusOK, games)
+}
+
+func (s *Server) game(w http.ResponseWriter, r *http.Request) {
+	appID, err := appIDParam(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	game, err := s.Service.Game(r.Context(), appID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, game)
+}
+
+func (s *Server) setStatus(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Status string `json:"status"`
+	}
+	appID, ok := s.change(w, r, &body)
+	if !ok {
+		return
+	}
+	game, err := s.Service.SetStatus(r.Context(), appID, body.Status)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, game)
+}
+
+func (s *Server) setNote(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Note string `json:"note"`
+	}
+	appID, ok := s.change(w, r, &body)
+	if !ok {
+		return
+	}
+	game, err := s.Service.SetNote(r.Context(), appID, body.Note)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, game)
+}
+
+// change checks that a game may be changed and decodes the request body
+// into v. It answers the request itself when it returns false.
+func (s *Server) change(w http.ResponseWriter, r *http.Request, v interface{}) (int, bool) {
+	if s.ReadOnly {
+		writeError(w, errReadOnly)
+		return 0, false
+	}
+	appID, err := appIDParam(r)
+	if err != nil {
+		writeError(w, err)
+		return 0, false
+	}
+	if err := requireJSON(r); err != nil {
+		writeError(w, err)
+		return 0, false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, BadRequest(fmt.Errorf("invalid body: %w", err)))
+		return 0, false
+	}
+	return appID, true
+}
+
+func (s *Server) pick(w http.ResponseWriter, r *http.Request) {
+	if err := requireJSON(r); err != nil {
+		writeError(w, err)
+		return
+	}
+	var req PickRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, BadRequest(fmt.Errorf("invalid body: %w", err)))
+			return
+		}
+	}
+	req.Record = !s.ReadOnly
+	req.Source = "serve"
+
+	game, err := s.Service.Pick(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, game)
+}
+
+func (s *Server) recommendations(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := RecommendRequest{
+		Mode:     q.Get("mode"),
+		Strategy: q.Get("strategy"),
+		Where:    q.Get("where"),
+	}
+	if req.Mode == "" {
+		req.Mode = "backlog"
+	}
+	if req.Strategy == "" {
+		req.Strategy = "genre"
+	}
+	var err error
+	if req.Top, err = intParam(q.Get("top"), 10); err != nil {
+		writeError(w, BadRequest(fmt.Errorf("top: %w", err)))
+		return
+	}
+	if req.Explain, err = boolParam(q.Get("explain")); err != nil {
+		writeError(w, BadRequest(fmt.Errorf("explain: %w", err)))
+		return
+	}
+
+	recommendations, err := s.Service.Recommendations(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if recommendations == nil {
+		recommendations = []model.Recommendation{}
+	}
+	writeJSON(w, http.StatusOK, recommendations)
+}
+
+func (s *Server) profile(w http.ResponseWriter, r *http.Request) {
+	profile, err := s.Service.Profile(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+func (s *Server) sync(w http.ResponseWriter, r *http.Request) {
+	if s.ReadOnly {
+		writeError(w, errReadOnly)
+		return
+	}
+	if err := requireJSON(r); err != nil {
+		writeError(w, err)
+		return
+	}
+	res, err := s.Service.Sync(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+var errReadOnly = &Error{Status: http.StatusForbidden, Err: errors.New("server is read-only")}
+
+// requireJSON refuses requests that are not sent as application/json. Other
+// web pages can only send such requests cross-origin after a CORS
+// preflight, which the server never answers.
+func requireJSON(r *http.Request) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return &Error{Status: http.StatusUnsupportedMediaType, Err: errors.New("requests must be sent with Content-Type: application/json")}
+	}
+	return nil
+}
+
+func appIDParam(r *http.Request) (int, error) {
+	appID, err := strconv.Atoi(r.PathValue("appid"))
+	if err != nil {
+		return 0, BadRequest(fmt.Errorf("invalid appid %q", r.PathValue("appid")))
+	}
+	return appID, nil
+}
+
+func boolParam(v string) (bool, error) {
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
+
+func intParam(v string, def int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err == nil && n < 0 {
+		err = errors.New("must be >= 0")
+	}
+	return n, err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with {"error": "..."} and the status of a wrapped
+// Error, or 500.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var serr *Error
+	if errors.As(err, &serr) {
+		status = serr.Status
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
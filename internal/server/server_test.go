@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+type fakeService struct {
+	games       []model.Game
+	picked      *PickRequest
+	recommended *RecommendRequest
+	synced      bool
+	note        string
+}
+
+func (f *fakeService) Games(ctx context.Context, q GamesQuery) ([]model.Game, error) {
+	if q.Where == "bad" {
+		return nil, BadRequest(errors.New("bad filter"))
+	}
+	if q.Limit > 0 && len(f.games) > q.Limit {
+		return f.games[:q.Limit], nil
+	}
+	return f.games, nil
+}
+
+func (f *fakeService) Game(ctx context.Context, appID int) (*model.GameInfo, error) {
+	for _, g := range f.games {
+		if g.AppID == appID {
+			return &model.GameInfo{AppID: g.AppID, Name: g.Name}, nil
+		}
+	}
+	return nil, NotFound(errors.New("not in the library"))
+}
+
+func (f *fakeService) Pick(ctx context.Context, req PickRequest) (*model.Game, error) {
+	f.picked = &req
+	return &f.games[0], nil
+}
+
+func (f *fakeService) Recommendations(ctx context.Context, req RecommendRequest) ([]model.Recommendation, error) {
+	f.recommended = &req
+	return nil, nil
+}
+
+func (f *fakeService) Profile(ctx context.Context) ([]GenreWeight, error) {
+	return nil, errors.New("database is locked")
+}
+
+func (f *fakeService) Sync(ctx context.Context) (*model.SyncResult, error) {
+	f.synced = true
+	return &model.SyncResult{New: 1}, nil
+}
+
+func (f *fakeService) SetStatus(ctx context.Context, appID int, status string) (*model.GameInfo, error) {
+	if status != "playing" {
+		return nil, BadRequest(errors.New("unknown status"))
+	}
+	return &model.GameInfo{AppID: appID, Status: model.StatusPlaying}, nil
+}
+
+func (f *fakeService) SetNote(ctx context.Context, appID int, note string) (*model.GameInfo, error) {
+	f.note = note
+	return &model.GameInfo{AppID: appID, Note: note}, nil
+}
+
+func TestHandler(t *testing.T) {
+	svc := &fakeService{games: []model.Game{{AppID: 620, Name: "Portal 2"}, {AppID: 400, Name: "Portal"}}}
+	ts := httptest.NewServer((&Server{Service: svc, ReadOnly: true}).Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		contains           string
+	}{
+		{"GET", "/games?limit=1", "", http.StatusOK, `"Portal 2"`},
+		{"GET", "/games?where=bad", "", http.StatusBadRequest, `"error":"bad filter"`},
+		{"GET", "/games?limit=-1", "", http.StatusBadRequest, "limit"},
+		{"GET", "/games/400", "", http.StatusOK, `"appid":400`},
+		{"GET", "/games/1", "", http.StatusNotFound, "not in the library"},
+		{"GET", "/games/abc", "", http.StatusBadRequest, "invalid appid"},
+		{"POST", "/pick", `{"where": "genre:RPG"}`, http.StatusOK, `"appid":620`},
+		{"POST", "/pick", `{`, http.StatusBadRequest, "invalid body"},
+		{"GET", "/recommendations", "", http.StatusOK, "[]"},
+		{"GET", "/profile", "", http.StatusInternalServerError, "database is locked"},
+		{"POST", "/sync", "", http.StatusForbidden, "read-only"},
+		{"PUT", "/games/400/note", `{"note": "x"}`, http.StatusForbidden, "read-only"},
+		{"GET", "/info", "", http.StatusOK, `"read_only":true`},
+		{"GET", "/", "", http.StatusOK, "<title>steam-pick</title>"},
+		{"GET", "/app.js", "", http.StatusOK, "/games"},
+		{"DELETE", "/games", "", http.StatusMethodNotAllowed, ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader(tt.body))
+		if tt.method != "GET" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.method, tt.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s %s: got status %d, want %d (%s)", tt.method, tt.path, resp.StatusCode, tt.status, body)
+		}
+		if !strings.Contains(string(body), tt.contains) {
+			t.Errorf("%s %s: body %q does not contain %q", tt.method, tt.path, body, tt.contains)
+		}
+	}
+
+	if svc.picked == nil || svc.picked.Where != "genre:RPG" || svc.picked.Record || svc.picked.Source != "serve" {
+		t.Errorf("read-only pick should not be recorded: %+v", svc.picked)
+	}
+	if svc.synced {
+		t.Error("read-only server ran a sync")
+	}
+}
+
+func TestReadWriteChanges(t *testing.T) {
+	svc := &fakeService{}
+	ts := httptest.NewServer((&Server{Service: svc}).Handler())
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		path, body string
+		status     int
+	}{
+		{"/games/400/status", `{"status": "playing"}`, http.StatusOK},
+		{"/games/400/status", `{"status": "won"}`, http.StatusBadRequest},
+		{"/games/400/note", `{"note": "Play with Sam"}`, http.StatusOK},
+		{"/games/400/note", `not json`, http.StatusBadRequest},
+	} {
+		req, _ := http.NewRequest("PUT", ts.URL+tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PUT %s: %v", tt.path, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("PUT %s %s: got status %d, want %d", tt.path, tt.body, resp.StatusCode, tt.status)
+		}
+	}
+	if svc.note != "Play with Sam" {
+		t.Errorf("got note %q", svc.note)
+	}
+}
+
+func TestReadWriteSync(t *testing.T) {
+	svc := &fakeService{}
+	ts := httptest.NewServer((&Server{Service: svc}).Handler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/sync", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST /sync: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var res model.SyncResult
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil || resp.StatusCode != http.StatusOK || res.New != 1 || !svc.synced {
+		t.Errorf("got status %d, %+v, %v", resp.StatusCode, res, err)
+	}
+}
+
+func TestChangesRequireJSON(t *testing.T) {
+	svc := &fakeService{games: []model.Game{{AppID: 620, Name: "Portal 2"}}}
+	ts := httptest.NewServer((&Server{Service: svc}).Handler())
+	defer ts.Close()
+
+	// Requests another web page could send without a CORS preflight.
+	for _, tt := range []struct {
+		method, path, contentType, body string
+	}{
+		{"POST", "/pick", "", ""},
+		{"POST", "/pick", "text/plain", `{"where": "genre:RPG"}`},
+		{"POST", "/sync", "application/x-www-form-urlencoded", ""},
+		{"PUT", "/games/400/note", "text/plain", `{"note": "x"}`},
+	} {
+		req, _ := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.method, tt.path, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusUnsupportedMediaType {
+			t.Errorf("%s %s as %q: got status %d, want 415", tt.method, tt.path, tt.contentType, resp.StatusCode)
+		}
+	}
+	if svc.picked != nil || svc.synced || svc.note != "" {
+		t.Errorf("a refused request reached the service: picked %+v, synced %v, note %q", svc.picked, svc.synced, svc.note)
+	}
+
+	req, _ := http.NewRequest("POST", ts.URL+"/pick", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /pick: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || svc.picked == nil || !svc.picked.Record {
+		t.Errorf("JSON pick: got status %d, %+v", resp.StatusCode, svc.picked)
+	}
+
+	resp, err = http.Get(ts.URL + "/recommendations")
+	if err != nil {
+		t.Fatalf("GET /recommendations: %v", err)
+	}
+	_ = resp.Body.Close()
+	if svc.recommended == nil || svc.recommended.Record {
+		t.Errorf("GET /recommendations should not record the run: %+v", svc.recommended)
+	}
+}
+
+func TestServeShutsDown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- (&Server{Service: &fakeService{}}).Serve(ctx, ln, time.Second) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/recommendations")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve returned %v, want nil after shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after the context was cancelled")
+	}
+}
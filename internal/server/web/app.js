@@ -0,0 +1,191 @@
+"use strict";
+
+const STATUSES = ["backlog", "playing", "beaten", "dropped"];
+
+const state = { readOnly: true, games: [], view: "backlog", query: "" };
+
+async function api(method, path, body) {
+  const opts = { method, headers: {} };
+  if (method !== "GET") {
+    // The server refuses changes sent as anything else.
+    opts.headers["Content-Type"] = "application/json";
+  }
+  if (body !== undefined) {
+    opts.body = JSON.stringify(body);
+  }
+  const res = await fetch(path, opts);
+  const data = await res.json().catch(() => ({}));
+  if (!res.ok) {
+    throw new Error(data.error || res.statusText);
+  }
+  return data;
+}
+
+// el builds an element; text children are added as text, never as HTML.
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs || {})) {
+    if (key.startsWith("on")) {
+      node.addEventListener(key.slice(2), value);
+    } else if (value !== false && value !== undefined) {
+      node.setAttribute(key, value === true ? "" : value);
+    }
+  }
+  for (const child of children) {
+    if (child !== null && child !== undefined) {
+      node.append(child);
+    }
+  }
+  return node;
+}
+
+let toastTimer;
+function showError(err) {
+  const toast = document.getElementById("toast");
+  toast.textContent = err.message || String(err);
+  toast.hidden = false;
+  clearTimeout(toastTimer);
+  toastTimer = setTimeout(() => { toast.hidden = true; }, 5000);
+}
+
+function image(game) {
+  if (game.header_image) {
+    return el("img", { src: game.header_image, alt: "", loading: "lazy" });
+  }
+  return el("div", { class: "placeholder" }, game.name);
+}
+
+function playtime(minutes) {
+  if (!minutes) {
+    return "Never played";
+  }
+  if (minutes < 60) {
+    return `${minutes} min played`;
+  }
+  return `${(minutes / 60).toFixed(1)} h played`;
+}
+
+async function loadInfo() {
+  const info = await api("GET", "/info");
+  state.readOnly = info.read_only;
+  document.getElementById("mode").hidden = !state.readOnly;
+}
+
+async function reroll() {
+  const button = document.getElementById("reroll");
+  const card = document.getElementById("pick-card");
+  button.disabled = true;
+  try {
+    const game = await api("POST", "/pick", {
+      turn_based_only: document.getElementById("turn-based").checked,
+    });
+    card.replaceChildren(
+      image(game),
+      el("div", {},
+        el("h3", {}, game.name),
+        el("p", { class: "muted" }, playtime(game.playtime_forever), game.is_turn_based ? " · Turn-based" : ""),
+        el("a", { href: game.store_url, target: "_blank", rel: "noopener" }, "Open in store")),
+    );
+    button.textContent = "Reroll";
+  } catch (err) {
+    card.replaceChildren(el("p", { class: "muted" }, err.message));
+  } finally {
+    button.disabled = false;
+  }
+}
+
+async function loadProfile() {
+  const chart = document.getElementById("chart");
+  try {
+    const profile = (await api("GET", "/profile")).slice(0, 10);
+    if (profile.length === 0) {
+      chart.replaceChildren(el("p", { class: "muted" }, "Not enough playtime for a profile yet."));
+      return;
+    }
+    const max = profile[0].value;
+    chart.replaceChildren(...profile.map((g) =>
+      el("div", { class: "bar", title: `${g.key}: ${g.value.toFixed(2)}` },
+        el("span", {}, g.key),
+        el("div", { style: `width: ${(g.value / max) * 100}%` }))));
+  } catch (err) {
+    chart.replaceChildren(el("p", { class: "muted" }, err.message));
+  }
+}
+
+async function loadGames() {
+  const unplayed = state.view === "backlog";
+  state.games = await api("GET", `/games?unplayed=${unplayed}`);
+  state.games.sort((a, b) => a.name.localeCompare(b.name));
+  renderGames();
+}
+
+async function setStatus(game, select) {
+  try {
+    const updated = await api("PUT", `/games/${game.appid}/status`, { status: select.value });
+    game.status = updated.status;
+  } catch (err) {
+    select.value = game.status || "";
+    showError(err);
+  }
+}
+
+async function setNote(game, textarea) {
+  if (textarea.value === (game.note || "")) {
+    return;
+  }
+  try {
+    const updated = await api("PUT", `/games/${game.appid}/note`, { note: textarea.value });
+    game.note = updated.note || "";
+  } catch (err) {
+    textarea.value = game.note || "";
+    showError(err);
+  }
+}
+
+function gameCard(game) {
+  const select = el("select", { "aria-label": "Status", disabled: state.readOnly },
+    el("option", { value: "", disabled: true }, "No status"),
+    ...STATUSES.map((s) => el("option", { value: s }, s)));
+  select.value = game.status || "";
+  select.addEventListener("change", () => setStatus(game, select));
+
+  const note = el("textarea", { placeholder: "Notes", "aria-label": "Notes", rows: 2, readonly: state.readOnly });
+  note.value = game.note || "";
+  note.addEventListener("change", () => setNote(game, note));
+
+  return el("article", { class: "card" },
+    image(game),
+    el("div", { class: "body" },
+      el("h3", {}, game.name),
+      el("div", { class: "meta" }, playtime(game.playtime_forever)),
+      select,
+      note));
+}
+
+function renderGames() {
+  const query = state.query.toLowerCase();
+  const games = state.games.filter((g) => g.name.toLowerCase().includes(query));
+  document.getElementById("count").textContent = `${games.length} games`;
+  document.getElementById("games").replaceChildren(...games.map(gameCard));
+}
+
+async function main() {
+  document.getElementById("reroll").addEventListener("click", reroll);
+  document.getElementById("search").addEventListener("input", (e) => {
+    state.query = e.target.value;
+    renderGames();
+  });
+  document.getElementById("view").addEventListener("change", (e) => {
+    state.view = e.target.value;
+    loadGames().catch(showError);
+  });
+
+  try {
+    await loadInfo();
+    await Promise.all([loadGames(), loadProfile()]);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+main();
@@ -14,6 +14,9 @@ import (
 
 var ErrRateLimitExceeded = fmt.Errorf("rate limit exceeded")
 
+// ErrNoAchievements is returned for games that have no achievement stats.
+var ErrNoAchievements = fmt.Errorf("game has no achievements")
+
 const (
 	baseURL    = "https://api.steampowered.com"
 	maxRetries = 2
@@ -208,3 +211,75 @@ func (c *Client) GetAppDetails(ctx context.Context, appID int) (*model.AppDetail
 
 	return &result, nil
 }
+
+// GetPlayerAchievements returns the achievement state of a game for a user.
+func (c *Client) GetPlayerAchievements(ctx context.Context, steamID64 string, appID int) ([]model.PlayerAchievement, error) {
+	u, _ := url.Parse(baseURL + "/ISteamUserStats/GetPlayerAchievements/v1/")
+	q := u.Query()
+	q.Set("key", c.apiKey)
+	q.Set("steamid", steamID64)
+	q.Set("appid", fmt.Sprintf("%d", appID))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Games without stats are reported as 400 with success=false.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return nil, fmt.Errorf("steam api returned status: %d", resp.StatusCode)
+	}
+
+	var result model.PlayerAchievementsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if !result.PlayerStats.Success {
+		if resp.StatusCode == http.StatusBadRequest {
+			return nil, ErrNoAchievements
+		}
+		return nil, fmt.Errorf("achievements unavailable: %s", result.PlayerStats.Error)
+	}
+
+	return result.PlayerStats.Achievements, nil
+}
+
+// GetSchemaForGame returns the achievement definitions of a game.
+func (c *Client) GetSchemaForGame(ctx context.Context, appID int) ([]model.SchemaAchievement, error) {
+	u, _ := url.Parse(baseURL + "/ISteamUserStats/GetSchemaForGame/v2/")
+	q := u.Query()
+	q.Set("key", c.apiKey)
+	q.Set("appid", fmt.Sprintf("%d", appID))
+	q.Set("l", "english")
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("steam api returned status: %d", resp.StatusCode)
+	}
+
+	var result model.SchemaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Game.AvailableGameStats.Achievements, nil
+}
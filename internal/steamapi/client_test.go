@@ -2,6 +2,7 @@ package steamapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -145,3 +146,87 @@ func TestClient_GetAppDetails(t *testing.T) {
 		t.Errorf("Expected name Counter-Strike, got %s", entry.Data.Name)
 	}
 }
+
+func TestClient_GetPlayerAchievements(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ISteamUserStats/GetPlayerAchievements/v1/" {
+			t.Errorf("Expected path /ISteamUserStats/GetPlayerAchievements/v1/, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("appid") == "20" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"playerstats": {"error": "Requested app has no stats", "success": false}}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"playerstats": {
+				"steamID": "76561198000000000",
+				"gameName": "Test Game",
+				"achievements": [
+					{"apiname": "ACH_WIN", "achieved": 1, "unlocktime": 1700000000},
+					{"apiname": "ACH_LOSE", "achieved": 0, "unlocktime": 0}
+				],
+				"success": true
+			}
+		}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient("test-key", time.Minute, time.Minute, time.Second)
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	c.httpClient.Transport = &TestTransport{TargetURL: ts.URL}
+
+	achievements, err := c.GetPlayerAchievements(context.Background(), "76561198000000000", 10)
+	if err != nil {
+		t.Fatalf("GetPlayerAchievements error: %v", err)
+	}
+	if len(achievements) != 2 || achievements[0].Achieved != 1 || achievements[0].UnlockTime != 1700000000 {
+		t.Errorf("unexpected achievements: %+v", achievements)
+	}
+
+	_, err = c.GetPlayerAchievements(context.Background(), "76561198000000000", 20)
+	if !errors.Is(err, ErrNoAchievements) {
+		t.Errorf("expected ErrNoAchievements, got %v", err)
+	}
+}
+
+func TestClient_GetSchemaForGame(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ISteamUserStats/GetSchemaForGame/v2/" {
+			t.Errorf("Expected path /ISteamUserStats/GetSchemaForGame/v2/, got %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"game": {
+				"gameName": "Test Game",
+				"availableGameStats": {
+					"achievements": [
+						{"name": "ACH_WIN", "displayName": "Winner", "hidden": 0},
+						{"name": "ACH_LOSE", "displayName": "Loser", "hidden": 1}
+					]
+				}
+			}
+		}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient("test-key", time.Minute, time.Minute, time.Second)
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	c.httpClient.Transport = &TestTransport{TargetURL: ts.URL}
+
+	schema, err := c.GetSchemaForGame(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("GetSchemaForGame error: %v", err)
+	}
+	if len(schema) != 2 || schema[0].DisplayName != "Winner" {
+		t.Errorf("unexpected schema: %+v", schema)
+	}
+}
@@ -0,0 +1,284 @@
+package steamlocal
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// collectionPrefix starts the cloud storage keys of user collections.
+const collectionPrefix = "user-collections."
+
+// Collection is a user collection ("shelf") of the Steam client.
+type Collection struct {
+	ID     string
+	Name   string
+	AppIDs []int
+	// Dynamic collections are defined by filters; their games are not
+	// stored.
+	Dynamic bool
+	// Deleted collections are written as deleted entries.
+	Deleted bool
+}
+
+// The built-in collections have no name of their own.
+var builtinCollections = map[string]string{
+	"favorite": "Favorites",
+	"hidden":   "Hidden",
+}
+
+// collectionValue is the JSON stored, as a string, in a collection entry.
+type collectionValue struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Added      []int           `json:"added"`
+	Removed    []int           `json:"removed"`
+	FilterSpec json.RawMessage `json:"filterSpec,omitempty"`
+}
+
+// cloudEntry is one entry of the cloud storage namespace file. The file is
+// a JSON array of [key, entry] pairs.
+type cloudEntry struct {
+	Key                      string `json:"key"`
+	Timestamp                int64  `json:"timestamp"`
+	Value                    string `json:"value,omitempty"`
+	Version                  string `json:"version"`
+	IsDeleted                bool   `json:"is_deleted,omitempty"`
+	ConflictResolutionMethod string `json:"conflictResolutionMethod,omitempty"`
+	StrMethodID              string `json:"strMethodId,omitempty"`
+}
+
+// CollectionsPath returns the path of an account's collections file.
+func CollectionsPath(root, steamID64 string) (string, error) {
+	account, err := AccountID(steamID64)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, "userdata", account, "config", "cloudstorage", "cloud-storage-namespace-1.json"), nil
+}
+
+// ReadCollections returns the collections of a cloud storage namespace
+// file, leaving out deleted ones.
+func ReadCollections(path string) ([]Collection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseCloudStorage(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var collections []Collection
+	for _, e := range entries {
+		if !strings.HasPrefix(e.key, collectionPrefix) || e.entry.IsDeleted || e.entry.Value == "" {
+			continue
+		}
+		var v collectionValue
+		if err := json.Unmarshal([]byte(e.entry.Value), &v); err != nil {
+			return nil, fmt.Errorf("%s: collection %s: %w", path, e.key, err)
+		}
+		c := Collection{ID: v.ID, Name: v.Name, Dynamic: len(v.FilterSpec) > 0}
+		if name, ok := builtinCollections[v.ID]; ok && c.Name == "" {
+			c.Name = name
+		}
+		removed := make(map[int]bool, len(v.Removed))
+		for _, id := range v.Removed {
+			removed[id] = true
+		}
+		for _, id := range v.Added {
+			if !removed[id] {
+				c.AppIDs = append(c.AppIDs, id)
+			}
+		}
+		collections = append(collections, c)
+	}
+	return collections, nil
+}
+
+// MergeCollections adds or replaces static collections in the contents of
+// a cloud storage namespace file and returns the new contents. Games a
+// replaced collection held before are listed as removed, so the client's
+// union merge does not bring them back. Deleted collections replace their
+// entry with a deletion. Other entries are kept as they are.
+func MergeCollections(data []byte, collections []Collection, now time.Time) ([]byte, error) {
+	entries, err := parseCloudStorage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions only ever grow; use one above the highest in the file.
+	var version int64
+	for _, e := range entries {
+		if v, err := strconv.ParseInt(e.entry.Version, 10, 64); err == nil && v > version {
+			version = v
+		}
+	}
+
+	index := make(map[string]int, len(entries))
+	for i, e := range entries {
+		index[e.key] = i
+	}
+	for _, c := range collections {
+		key := collectionPrefix + c.ID
+		if c.Deleted {
+			version++
+			entries = setEntry(entries, index, cloudEntry{
+				Key:       key,
+				Timestamp: now.Unix(),
+				Version:   strconv.FormatInt(version, 10),
+				IsDeleted: true,
+			})
+			continue
+		}
+		var removed []int
+		if i, ok := index[key]; ok {
+			if removed, err = removedApps(entries[i].entry, c.AppIDs); err != nil {
+				return nil, fmt.Errorf("collection %s: %w", key, err)
+			}
+		}
+		value, err := json.Marshal(collectionValue{ID: c.ID, Name: c.Name, Added: nonNil(c.AppIDs), Removed: nonNil(removed)})
+		if err != nil {
+			return nil, err
+		}
+		version++
+		entries = setEntry(entries, index, cloudEntry{
+			Key:                      key,
+			Timestamp:                now.Unix(),
+			Value:                    string(value),
+			Version:                  strconv.FormatInt(version, 10),
+			ConflictResolutionMethod: "custom",
+			StrMethodID:              "union-collections",
+		})
+	}
+
+	out := make([][2]interface{}, len(entries))
+	for i, e := range entries {
+		out[i][0] = e.key
+		if e.raw != nil {
+			out[i][1] = e.raw
+		} else {
+			out[i][1] = e.entry
+		}
+	}
+	return json.Marshal(out)
+}
+
+// setEntry replaces the entry with the same key, or appends it.
+func setEntry(entries []cloudPair, index map[string]int, entry cloudEntry) []cloudPair {
+	if i, ok := index[entry.Key]; ok {
+		entries[i].entry = entry
+		entries[i].raw = nil
+		return entries
+	}
+	index[entry.Key] = len(entries)
+	return append(entries, cloudPair{key: entry.Key, entry: entry})
+}
+
+// removedApps returns the games an existing collection entry added or
+// removed that are not in appIDs.
+func removedApps(old cloudEntry, appIDs []int) ([]int, error) {
+	if old.IsDeleted || old.Value == "" {
+		return nil, nil
+	}
+	var v collectionValue
+	if err := json.Unmarshal([]byte(old.Value), &v); err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool, len(appIDs))
+	for _, id := range appIDs {
+		seen[id] = true
+	}
+	var removed []int
+	for _, id := range append(v.Added, v.Removed...) {
+		if !seen[id] {
+			seen[id] = true
+			removed = append(removed, id)
+		}
+	}
+	return removed, nil
+}
+
+// WriteCollections merges collections into the file at path, which is
+// created when missing. The previous file is kept as path.bak.
+func WriteCollections(path string, collections []Collection, now time.Time) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		data = []byte("[]")
+	} else if err != nil {
+		return err
+	} else if err := os.WriteFile(path+".bak", data, 0o600); err != nil {
+		return fmt.Errorf("backing up %s: %w", path, err)
+	}
+
+	merged, err := MergeCollections(data, collections, now)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, merged, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// NewCollectionID returns a random ID in the client's "uc-" format.
+func NewCollectionID() (string, error) {
+	const chars = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	b := make([]byte, 12)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = chars[n.Int64()]
+	}
+	return "uc-" + string(b), nil
+}
+
+// cloudPair is a [key, entry] pair. raw keeps entries that are not
+// rewritten byte for byte.
+type cloudPair struct {
+	key   string
+	entry cloudEntry
+	raw   json.RawMessage
+}
+
+func parseCloudStorage(data []byte) ([]cloudPair, error) {
+	var pairs [][]json.RawMessage
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, fmt.Errorf("invalid cloud storage file: %w", err)
+	}
+	entries := make([]cloudPair, 0, len(pairs))
+	for _, p := range pairs {
+		if len(p) != 2 {
+			return nil, fmt.Errorf("invalid cloud storage entry: %d elements", len(p))
+		}
+		var e cloudPair
+		if err := json.Unmarshal(p[0], &e.key); err != nil {
+			return nil, fmt.Errorf("invalid cloud storage key: %w", err)
+		}
+		if err := json.Unmarshal(p[1], &e.entry); err != nil {
+			return nil, fmt.Errorf("invalid cloud storage entry %s: %w", e.key, err)
+		}
+		e.raw = p[1]
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func nonNil(ids []int) []int {
+	if ids == nil {
+		return []int{}
+	}
+	return ids
+}
@@ -0,0 +1,131 @@
+package steamlocal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const cloudStorage = `[
+	["user-collections.uc-couch00000", {"key": "user-collections.uc-couch00000", "timestamp": 1700000000, "value": "{\"id\":\"uc-couch00000\",\"name\":\"Couch\",\"added\":[620,400,70],\"removed\":[70]}", "version": "12", "conflictResolutionMethod": "custom", "strMethodId": "union-collections"}],
+	["user-collections.favorite", {"key": "user-collections.favorite", "timestamp": 1700000000, "value": "{\"id\":\"favorite\",\"added\":[10],\"removed\":[]}", "version": "3"}],
+	["user-collections.uc-dynamic000", {"key": "user-collections.uc-dynamic000", "timestamp": 1700000000, "value": "{\"id\":\"uc-dynamic000\",\"name\":\"Unplayed RPGs\",\"added\":[],\"removed\":[],\"filterSpec\":{\"nFormatVersion\":2}}", "version": "5"}],
+	["user-collections.uc-gone000000", {"key": "user-collections.uc-gone000000", "timestamp": 1700000000, "is_deleted": true, "version": "7"}],
+	["showcases.1", {"key": "showcases.1", "timestamp": 1700000000, "value": "{}", "version": "2"}]
+]`
+
+func TestReadCollections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cloud-storage-namespace-1.json")
+	writeFile(t, path, cloudStorage)
+
+	collections, err := ReadCollections(path)
+	if err != nil {
+		t.Fatalf("ReadCollections error: %v", err)
+	}
+	if len(collections) != 3 {
+		t.Fatalf("collections: got %+v", collections)
+	}
+	couch := collections[0]
+	if couch.ID != "uc-couch00000" || couch.Name != "Couch" || len(couch.AppIDs) != 2 || couch.Dynamic {
+		t.Errorf("Couch: got %+v", couch)
+	}
+	if fav := collections[1]; fav.Name != "Favorites" || len(fav.AppIDs) != 1 {
+		t.Errorf("favorite: got %+v", fav)
+	}
+	if !collections[2].Dynamic {
+		t.Errorf("expected a dynamic collection, got %+v", collections[2])
+	}
+}
+
+func TestWriteCollections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cloud-storage-namespace-1.json")
+	writeFile(t, path, cloudStorage)
+	now := time.Unix(1800000000, 0)
+
+	if err := WriteCollections(path, []Collection{
+		{ID: "uc-couch00000", Name: "Couch", AppIDs: []int{620}},
+		{ID: "uc-new0000000", Name: "Short", AppIDs: []int{400, 70}},
+	}, now); err != nil {
+		t.Fatalf("WriteCollections error: %v", err)
+	}
+
+	if backup, err := os.ReadFile(path + ".bak"); err != nil || string(backup) != cloudStorage {
+		t.Errorf("expected the old file as a backup, got %v", err)
+	}
+	collections, err := ReadCollections(path)
+	if err != nil {
+		t.Fatalf("ReadCollections error: %v", err)
+	}
+	names := []string{}
+	for _, c := range collections {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "Couch,Favorites,Unplayed RPGs,Short" {
+		t.Errorf("collections after write: %v", names)
+	}
+	if len(collections[0].AppIDs) != 1 || len(collections[3].AppIDs) != 2 {
+		t.Errorf("collections after write: %+v", collections)
+	}
+
+	data, _ := os.ReadFile(path)
+	var pairs [][]json.RawMessage
+	if err := json.Unmarshal(data, &pairs); err != nil || len(pairs) != 6 {
+		t.Fatalf("expected 6 entries, got %d, %v", len(pairs), err)
+	}
+	// Untouched entries are kept; new ones get higher versions.
+	if !strings.Contains(string(pairs[3][1]), `"is_deleted":true`) || !strings.Contains(string(pairs[4][1]), `"showcases.1"`) {
+		t.Errorf("other entries changed: %s %s", pairs[3][1], pairs[4][1])
+	}
+	// Games untagged since the last export are listed as removed.
+	var couch cloudEntry
+	var value collectionValue
+	_ = json.Unmarshal(pairs[0][1], &couch)
+	if err := json.Unmarshal([]byte(couch.Value), &value); err != nil || !reflect.DeepEqual(value.Removed, []int{400, 70}) {
+		t.Errorf("replaced entry: got %s, %v", couch.Value, err)
+	}
+	var entry cloudEntry
+	_ = json.Unmarshal(pairs[5][1], &entry)
+	if entry.Version != "14" || entry.Timestamp != now.Unix() || entry.StrMethodID != "union-collections" {
+		t.Errorf("new entry: got %+v", entry)
+	}
+}
+
+func TestWriteDeletedCollection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cloud-storage-namespace-1.json")
+	writeFile(t, path, cloudStorage)
+
+	if err := WriteCollections(path, []Collection{{ID: "uc-couch00000", Deleted: true}}, time.Unix(1800000000, 0)); err != nil {
+		t.Fatalf("WriteCollections error: %v", err)
+	}
+	collections, err := ReadCollections(path)
+	if err != nil {
+		t.Fatalf("ReadCollections error: %v", err)
+	}
+	for _, c := range collections {
+		if c.ID == "uc-couch00000" {
+			t.Errorf("deleted collection still read: %+v", c)
+		}
+	}
+
+	data, _ := os.ReadFile(path)
+	var pairs [][]json.RawMessage
+	if err := json.Unmarshal(data, &pairs); err != nil || len(pairs) != 5 {
+		t.Fatalf("expected 5 entries, got %d, %v", len(pairs), err)
+	}
+	var entry cloudEntry
+	_ = json.Unmarshal(pairs[0][1], &entry)
+	if !entry.IsDeleted || entry.Value != "" || entry.Version != "13" {
+		t.Errorf("deleted entry: got %+v", entry)
+	}
+}
+
+func TestNewCollectionID(t *testing.T) {
+	id, err := NewCollectionID()
+	if err != nil || len(id) != 15 || !strings.HasPrefix(id, "uc-") {
+		t.Errorf("NewCollectionID: got %q, %v", id, err)
+	}
+}
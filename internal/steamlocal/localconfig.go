@@ -0,0 +1,164 @@
+package steamlocal
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/vdf"
+)
+
+// steamID64Base is the SteamID64 of individual account 0. userdata folders
+// are named by the account ID, the SteamID64 minus this base.
+const steamID64Base = 76561197960265728
+
+// AccountID returns the userdata folder name of a SteamID64.
+func AccountID(steamID64 string) (string, error) {
+	id, err := strconv.ParseUint(steamID64, 10, 64)
+	if err != nil || id < steamID64Base {
+		return "", fmt.Errorf("invalid SteamID64 %q", steamID64)
+	}
+	return strconv.FormatUint(id-steamID64Base, 10), nil
+}
+
+// MostRecentUser returns the SteamID64 of the account last signed in to the
+// client, from config/loginusers.vdf.
+func MostRecentUser(root string) (string, error) {
+	doc, err := vdf.ParseFile(filepath.Join(root, "config", "loginusers.vdf"))
+	if err != nil {
+		return "", err
+	}
+	users := doc.Child("users")
+	if users == nil || len(users.Children) == 0 {
+		return "", errors.New("no users in loginusers.vdf")
+	}
+	for _, u := range users.Children {
+		if u.String("MostRecent") == "1" {
+			return u.Key, nil
+		}
+	}
+	return users.Children[0].Key, nil
+}
+
+// AppUsage is an app's playtime as recorded by the local client.
+type AppUsage struct {
+	AppID           int
+	PlaytimeMinutes int
+	LastPlayed      int64
+}
+
+// LocalConfigPath returns the path of an account's localconfig.vdf.
+func LocalConfigPath(root, steamID64 string) (string, error) {
+	account, err := AccountID(steamID64)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, "userdata", account, "config", "localconfig.vdf"), nil
+}
+
+// ReadLocalConfig returns the apps of a localconfig.vdf that have been
+// played or launched.
+func ReadLocalConfig(path string) ([]AppUsage, error) {
+	doc, err := vdf.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	apps := doc.Find("UserLocalConfigStore", "Software", "Valve", "Steam", "apps")
+	if apps == nil {
+		return nil, nil
+	}
+	var usage []AppUsage
+	for _, a := range apps.Children {
+		appID, err := strconv.Atoi(a.Key)
+		if err != nil || !a.IsObject() {
+			continue
+		}
+		u := AppUsage{AppID: appID}
+		u.PlaytimeMinutes, _ = strconv.Atoi(a.String("Playtime"))
+		u.LastPlayed, _ = strconv.ParseInt(a.String("LastPlayed"), 10, 64)
+		if u.PlaytimeMinutes == 0 && u.LastPlayed == 0 {
+			continue
+		}
+		usage = append(usage, u)
+	}
+	return usage, nil
+}
+
+// steamTools are name prefixes of the runtimes and compatibility tools the
+// client installs as apps.
+var steamTools = []string{
+	"proton ",
+	"steam linux runtime",
+	"steamworks common redistributables",
+	"steamvr",
+}
+
+func isSteamTool(name string) bool {
+	name = strings.ToLower(name) + " "
+	for _, p := range steamTools {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Library is an account's library as far as the local client knows it.
+type Library struct {
+	ConfigPath string
+	// Games have playtime and last-played values from localconfig.vdf and
+	// names from the app manifests. Apps that are neither installed nor
+	// named by a manifest have an empty name.
+	Games []model.Game
+	Scan  *Scan
+}
+
+// ImportLibrary reads an account's played apps and the apps of root it
+// installed. Installs last owned by other accounts, e.g. family-shared
+// games, are left out, as are Steam's own runtimes and tools.
+func ImportLibrary(root, steamID64 string) (*Library, error) {
+	path, err := LocalConfigPath(root, steamID64)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := ReadLocalConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	scan, err := ScanInstalled(root)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[int]string{}
+	games := map[int]*model.Game{}
+	for _, i := range scan.Games {
+		names[i.AppID] = i.Name
+		if scan.Owners[i.AppID] == steamID64 {
+			games[i.AppID] = &model.Game{AppID: i.AppID, Name: i.Name}
+		}
+	}
+	for _, u := range usage {
+		g, ok := games[u.AppID]
+		if !ok {
+			g = &model.Game{AppID: u.AppID, Name: names[u.AppID]}
+			games[u.AppID] = g
+		}
+		g.PlaytimeForever = u.PlaytimeMinutes
+		g.RTimeLastPlayed = int(u.LastPlayed)
+	}
+
+	lib := &Library{ConfigPath: path, Scan: scan}
+	for _, g := range games {
+		if isSteamTool(g.Name) {
+			continue
+		}
+		lib.Games = append(lib.Games, *g)
+	}
+	sort.Slice(lib.Games, func(i, j int) bool { return lib.Games[i].AppID < lib.Games[j].AppID })
+	return lib, nil
+}
@@ -0,0 +1,166 @@
+// Package steamlocal reads the files of a local Steam client installation.
+package steamlocal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/vdf"
+)
+
+// stateFullyInstalled is the StateFlags bit of an app that can be launched
+// without a download.
+const stateFullyInstalled = 4
+
+// DefaultRoot returns the usual Steam installation directory of this
+// platform: the first candidate that exists, or the first candidate.
+func DefaultRoot() string {
+	home, _ := os.UserHomeDir()
+	var candidates []string
+	switch runtime.GOOS {
+	case "windows":
+		candidates = []string{`C:\Program Files (x86)\Steam`, `C:\Program Files\Steam`}
+	case "darwin":
+		candidates = []string{filepath.Join(home, "Library", "Application Support", "Steam")}
+	default:
+		candidates = []string{
+			filepath.Join(home, ".steam", "steam"),
+			filepath.Join(home, ".local", "share", "Steam"),
+			filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+		}
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(filepath.Join(c, "steamapps")); err == nil {
+			return c
+		}
+	}
+	return candidates[0]
+}
+
+// Scan is the result of scanning a Steam installation.
+type Scan struct {
+	Libraries []string
+	Games     []model.InstalledGame
+	// Owners maps each installed app to the SteamID64 of the account that
+	// last owned it, from the manifest's LastOwner.
+	Owners map[int]string
+	// Problems are libraries and manifests that could not be read. They do
+	// not stop the scan.
+	Problems []error
+}
+
+// LibraryFolders returns the library folders listed in the root's
+// libraryfolders.vdf. The root itself is always the first library.
+func LibraryFolders(root string) ([]string, error) {
+	libraries := []string{root}
+	seen := map[string]bool{filepath.Clean(root): true}
+
+	doc, err := vdf.ParseFile(filepath.Join(root, "steamapps", "libraryfolders.vdf"))
+	if os.IsNotExist(err) {
+		return libraries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	folders := doc.Child("libraryfolders")
+	if folders == nil {
+		return libraries, nil
+	}
+	for _, f := range folders.Children {
+		// Numbered entries are objects with a path, or bare paths in the
+		// format used before 2021.
+		if _, err := strconv.Atoi(f.Key); err != nil {
+			continue
+		}
+		path := f.Value
+		if f.IsObject() {
+			path = f.String("path")
+		}
+		if path == "" || seen[filepath.Clean(path)] {
+			continue
+		}
+		seen[filepath.Clean(path)] = true
+		libraries = append(libraries, path)
+	}
+	return libraries, nil
+}
+
+// ScanInstalled lists the fully installed apps of every library of root.
+func ScanInstalled(root string) (*Scan, error) {
+	if _, err := os.Stat(filepath.Join(root, "steamapps")); err != nil {
+		return nil, fmt.Errorf("no Steam installation at %s: %w", root, err)
+	}
+	libraries, err := LibraryFolders(root)
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &Scan{Libraries: libraries, Owners: map[int]string{}}
+	seen := map[int]bool{}
+	for _, lib := range libraries {
+		if _, err := os.Stat(filepath.Join(lib, "steamapps")); err != nil {
+			scan.Problems = append(scan.Problems, fmt.Errorf("library %s: %w", lib, err))
+			continue
+		}
+		manifests, err := filepath.Glob(filepath.Join(lib, "steamapps", "appmanifest_*.acf"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range manifests {
+			game, owner, ok, err := readManifest(lib, path)
+			if err != nil {
+				scan.Problems = append(scan.Problems, err)
+				continue
+			}
+			if !ok || seen[game.AppID] {
+				continue
+			}
+			seen[game.AppID] = true
+			scan.Games = append(scan.Games, game)
+			if owner != "" {
+				scan.Owners[game.AppID] = owner
+			}
+		}
+	}
+	sort.Slice(scan.Games, func(i, j int) bool { return scan.Games[i].AppID < scan.Games[j].AppID })
+	return scan, nil
+}
+
+// readManifest reads an appmanifest_<appid>.acf and the SteamID64 of the
+// app's last owner. ok is false for apps that are not fully installed.
+func readManifest(library, path string) (model.InstalledGame, string, bool, error) {
+	doc, err := vdf.ParseFile(path)
+	if err != nil {
+		return model.InstalledGame{}, "", false, err
+	}
+	state := doc.Child("AppState")
+	if state == nil {
+		return model.InstalledGame{}, "", false, fmt.Errorf("%s: no AppState", path)
+	}
+	appID, err := strconv.Atoi(state.String("appid"))
+	if err != nil {
+		return model.InstalledGame{}, "", false, fmt.Errorf("%s: invalid appid %q", path, state.String("appid"))
+	}
+	flags, _ := strconv.Atoi(state.String("StateFlags"))
+	if flags&stateFullyInstalled == 0 {
+		return model.InstalledGame{}, "", false, nil
+	}
+
+	game := model.InstalledGame{
+		AppID:       appID,
+		Name:        state.String("name"),
+		LibraryPath: library,
+		InstallDir:  filepath.Join(library, "steamapps", "common", state.String("installdir")),
+	}
+	game.SizeOnDisk, _ = strconv.ParseInt(state.String("SizeOnDisk"), 10, 64)
+	if ts, err := strconv.ParseInt(state.String("LastUpdated"), 10, 64); err == nil && ts > 0 {
+		game.LastUpdated = time.Unix(ts, 0).UTC()
+	}
+	return game, state.String("LastOwner"), true, nil
+}
@@ -0,0 +1,176 @@
+package steamlocal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func manifest(appID int, name string, flags int) string {
+	return fmt.Sprintf(`"AppState"
+{
+	"appid"		"%d"
+	"name"		"%s"
+	"StateFlags"		"%d"
+	"installdir"		"%s"
+	"LastUpdated"		"1700000000"
+	"SizeOnDisk"		"2048"
+}
+`, appID, name, flags, name)
+}
+
+// ownedManifest is a manifest last owned by an account.
+func ownedManifest(appID int, name, owner string) string {
+	m := manifest(appID, name, 4)
+	return m[:len(m)-2] + fmt.Sprintf("\t\"LastOwner\"\t\t\"%s\"\n}\n", owner)
+}
+
+func TestScanInstalled(t *testing.T) {
+	root := t.TempDir()
+	extra := t.TempDir()
+	missing := filepath.Join(t.TempDir(), "unmounted")
+
+	writeFile(t, filepath.Join(root, "steamapps", "libraryfolders.vdf"), fmt.Sprintf(`"libraryfolders"
+{
+	"0"	{ "path" "%s" }
+	"1"	{ "path" "%s" }
+	"2"	{ "path" "%s" }
+}
+`, root, extra, missing))
+	writeFile(t, filepath.Join(root, "steamapps", "appmanifest_620.acf"), manifest(620, "Portal 2", 4))
+	writeFile(t, filepath.Join(root, "steamapps", "appmanifest_70.acf"), manifest(70, "Half-Life", 1026))
+	writeFile(t, filepath.Join(root, "steamapps", "appmanifest_1.acf"), `"AppState" {`)
+	writeFile(t, filepath.Join(extra, "steamapps", "appmanifest_400.acf"), manifest(400, "Portal", 6))
+
+	scan, err := ScanInstalled(root)
+	if err != nil {
+		t.Fatalf("ScanInstalled error: %v", err)
+	}
+	if len(scan.Libraries) != 3 {
+		t.Errorf("libraries: got %v", scan.Libraries)
+	}
+	// The broken manifest and the missing library are reported.
+	if len(scan.Problems) != 2 {
+		t.Errorf("problems: got %v", scan.Problems)
+	}
+	// Half-Life is still downloading.
+	if len(scan.Games) != 2 || scan.Games[0].AppID != 400 || scan.Games[1].AppID != 620 {
+		t.Fatalf("games: got %+v", scan.Games)
+	}
+	g := scan.Games[1]
+	if g.Name != "Portal 2" || g.LibraryPath != root || g.InstallDir != filepath.Join(root, "steamapps", "common", "Portal 2") ||
+		g.SizeOnDisk != 2048 || !g.LastUpdated.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Portal 2: got %+v", g)
+	}
+}
+
+func TestLibraryFoldersOldFormat(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "steamapps", "libraryfolders.vdf"), `"LibraryFolders"
+{
+	"TimeNextStatsReport"	"1700000000"
+	"ContentStatsID"	"-123"
+	"1"	"/mnt/games"
+}
+`)
+	libraries, err := LibraryFolders(root)
+	if err != nil {
+		t.Fatalf("LibraryFolders error: %v", err)
+	}
+	if strings.Join(libraries, ",") != root+",/mnt/games" {
+		t.Errorf("libraries: got %v", libraries)
+	}
+}
+
+func TestScanInstalledNoSteam(t *testing.T) {
+	if _, err := ScanInstalled(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without steamapps")
+	}
+}
+
+func TestImportLibrary(t *testing.T) {
+	root := t.TempDir()
+	steamID := "76561198000000001"
+
+	writeFile(t, filepath.Join(root, "config", "loginusers.vdf"), `"users"
+{
+	"76561198000000002"	{ "AccountName" "other" "MostRecent" "0" }
+	"76561198000000001"	{ "AccountName" "me" "MostRecent" "1" }
+}
+`)
+	writeFile(t, filepath.Join(root, "userdata", "39734273", "config", "localconfig.vdf"), `"UserLocalConfigStore"
+{
+	"Software"
+	{
+		"valve"
+		{
+			"Steam"
+			{
+				"apps"
+				{
+					"620"	{ "LastPlayed" "1700000000" "Playtime" "95" }
+					"70"	{ "LastPlayed" "1600000000" "Playtime" "30" }
+					"10"	{ "cloud" { "last_sync_state" "synchronized" } }
+				}
+			}
+		}
+	}
+}
+`)
+	writeFile(t, filepath.Join(root, "steamapps", "appmanifest_620.acf"), ownedManifest(620, "Portal 2", steamID))
+	writeFile(t, filepath.Join(root, "steamapps", "appmanifest_400.acf"), ownedManifest(400, "Portal", steamID))
+	writeFile(t, filepath.Join(root, "steamapps", "appmanifest_500.acf"), ownedManifest(500, "Left 4 Dead", "76561198000000002"))
+	writeFile(t, filepath.Join(root, "steamapps", "appmanifest_1493710.acf"), ownedManifest(1493710, "Proton Experimental", steamID))
+
+	user, err := MostRecentUser(root)
+	if err != nil || user != steamID {
+		t.Fatalf("MostRecentUser: got %q, %v", user, err)
+	}
+
+	lib, err := ImportLibrary(root, steamID)
+	if err != nil {
+		t.Fatalf("ImportLibrary error: %v", err)
+	}
+	// 10 was never played, 500 was installed by another account and Proton
+	// is a Steam tool.
+	if len(lib.Games) != 3 {
+		t.Fatalf("games: got %+v", lib.Games)
+	}
+	byID := map[int]string{}
+	for _, g := range lib.Games {
+		byID[g.AppID] = fmt.Sprintf("%s/%d/%d", g.Name, g.PlaytimeForever, g.RTimeLastPlayed)
+	}
+	want := map[int]string{70: "/30/1600000000", 400: "Portal/0/0", 620: "Portal 2/95/1700000000"}
+	for id, w := range want {
+		if byID[id] != w {
+			t.Errorf("%d: got %q, want %q", id, byID[id], w)
+		}
+	}
+	if len(lib.Scan.Games) != 4 || lib.Scan.Owners[500] != "76561198000000002" {
+		t.Errorf("installed: got %+v, owners %v", lib.Scan.Games, lib.Scan.Owners)
+	}
+}
+
+func TestAccountID(t *testing.T) {
+	if id, err := AccountID("76561197960265729"); err != nil || id != "1" {
+		t.Errorf("AccountID: got %q, %v", id, err)
+	}
+	for _, bad := range []string{"", "vanity", "42"} {
+		if _, err := AccountID(bad); err == nil {
+			t.Errorf("AccountID(%q): expected an error", bad)
+		}
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/dajoen/steam-pick/internal/model"
@@ -53,8 +52,9 @@ func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
 	return resp, err
 }
 
-// IsTurnBased checks if a game is turn-based by looking at genres and categories.
-func (c *Client) IsTurnBased(ctx context.Context, appID int, country string) (bool, error) {
+// GetAppDetails returns the store details of an app as listed in a
+// country's store.
+func (c *Client) GetAppDetails(ctx context.Context, appID int, country string) (*model.AppDetails, error) {
 	u, _ := url.Parse("https://store.steampowered.com/api/appdetails")
 	q := u.Query()
 	q.Set("appids", fmt.Sprintf("%d", appID))
@@ -64,42 +64,28 @@ func (c *Client) IsTurnBased(ctx context.Context, appID int, country string) (bo
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	resp, err := c.doRequest(req)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("store api returned status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("store api returned status: %d", resp.StatusCode)
 	}
 
 	var result model.AppDetailsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return false, err
+		return nil, err
 	}
 
 	appData, ok := result[fmt.Sprintf("%d", appID)]
 	if !ok || !appData.Success {
 		// If success is false, it might be region locked or invalid.
-		// We treat it as not turn-based (or error).
-		return false, fmt.Errorf("failed to get app details for %d", appID)
+		return nil, fmt.Errorf("failed to get app details for %d", appID)
 	}
-
-	// Check genres and categories
-	for _, g := range appData.Data.Genres {
-		if strings.Contains(strings.ToLower(g.Description), "turn") {
-			return true, nil
-		}
-	}
-	for _, cat := range appData.Data.Categories {
-		if strings.Contains(strings.ToLower(cat.Description), "turn") {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	return &appData.Data, nil
 }
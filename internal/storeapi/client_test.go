@@ -20,7 +20,7 @@ func (t *TestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return http.DefaultTransport.RoundTrip(req)
 }
 
-func TestClient_IsTurnBased(t *testing.T) {
+func TestClient_GetAppDetails(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`{
@@ -45,21 +45,15 @@ func TestClient_IsTurnBased(t *testing.T) {
 	c := NewClient(time.Second)
 	c.httpClient.Transport = &TestTransport{TargetURL: ts.URL}
 
-	// Test Turn Based
-	isTurn, err := c.IsTurnBased(context.Background(), 10, "US")
+	d, err := c.GetAppDetails(context.Background(), 10, "US")
 	if err != nil {
-		t.Errorf("IsTurnBased(10) error: %v", err)
+		t.Fatalf("GetAppDetails(10) error: %v", err)
 	}
-	if !isTurn {
-		t.Errorf("IsTurnBased(10) = false, want true")
+	if d.Name != "Turn Based Game" || len(d.Genres) != 1 || d.Genres[0].Description != "Turn-based Strategy" {
+		t.Errorf("GetAppDetails(10) = %+v", d)
 	}
 
-	// Test Not Turn Based
-	isTurn, err = c.IsTurnBased(context.Background(), 20, "US")
-	if err != nil {
-		t.Errorf("IsTurnBased(20) error: %v", err)
-	}
-	if isTurn {
-		t.Errorf("IsTurnBased(20) = true, want false")
+	if _, err := c.GetAppDetails(context.Background(), 30, "US"); err == nil {
+		t.Error("GetAppDetails(30) expected an error for a missing app")
 	}
 }
@@ -0,0 +1,264 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/logic"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/query"
+)
+
+// defaultLimit caps game lists so results fit in a model's context.
+const defaultLimit = 50
+
+// GameSummary is a game as returned by the list tools.
+type GameSummary struct {
+	AppID           int      `json:"appid"`
+	Name            string   `json:"name"`
+	PlaytimeMinutes int      `json:"playtime_minutes"`
+	Status          string   `json:"status,omitempty"`
+	Genres          []string `json:"genres,omitempty"`
+}
+
+// GameDescription is the result of get_description.
+type GameDescription struct {
+	AppID       int      `json:"appid"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Genres      []string `json:"genres"`
+	Categories  []string `json:"categories"`
+}
+
+// GamePlaytime is the result of get_playtime.
+type GamePlaytime struct {
+	AppID           int    `json:"appid"`
+	Name            string `json:"name"`
+	PlaytimeMinutes int    `json:"playtime_minutes"`
+	LastPlayed      string `json:"last_played,omitempty"` // RFC 3339
+	Status          string `json:"status,omitempty"`
+}
+
+type listArgs struct {
+	Status       string `json:"status"`
+	UnplayedOnly bool   `json:"unplayed_only"`
+	Genre        string `json:"genre"`
+	Category     string `json:"category"`
+	Where        string `json:"where"`
+	MaxPlaytime  *int   `json:"max_playtime_minutes"`
+	Limit        int    `json:"limit"`
+}
+
+type appArgs struct {
+	AppID  int    `json:"appid"`
+	Status string `json:"status"`
+}
+
+// NewLibrary returns the library tools for an account.
+func NewLibrary(database *db.DB, steamID string) *Registry {
+	l := &library{db: database, steamID: steamID}
+	r := NewRegistry()
+	r.Register(Tool{
+		Name:        "list_games",
+		Description: "List games in the library, optionally only unplayed ones or those with a backlog status.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"status": {"type": "string", "enum": ["backlog", "playing", "beaten", "dropped"], "description": "Only games with this status"},
+				"unplayed_only": {"type": "boolean", "description": "Only games still in the backlog"},
+				"limit": {"type": "integer", "description": "Maximum number of games (default 50)"}
+			}
+		}`),
+		ReadOnly: true,
+		Run:      l.listGames,
+	})
+	r.Register(Tool{
+		Name:        "query_games",
+		Description: "Find games by genre, category, playtime or a filter expression such as 'genre:strategy and playtime < 60'.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"genre": {"type": "string", "description": "Genre the game must have, e.g. Strategy"},
+				"category": {"type": "string", "description": "Category the game must have, e.g. Co-op"},
+				"max_playtime_minutes": {"type": "integer", "description": "Only games played at most this long"},
+				"unplayed_only": {"type": "boolean", "description": "Only games still in the backlog"},
+				"where": {"type": "string", "description": "Filter expression over name, status, genre, category, platform, appid, playtime and completion"},
+				"limit": {"type": "integer", "description": "Maximum number of games (default 50)"}
+			}
+		}`),
+		ReadOnly: true,
+		Run:      l.listGames,
+	})
+	r.Register(Tool{
+		Name:        "get_description",
+		Description: "Get the store description, genres and categories of a game.",
+		Parameters:  appIDSchema,
+		ReadOnly:    true,
+		Run:         l.getDescription,
+	})
+	r.Register(Tool{
+		Name:        "get_playtime",
+		Description: "Get how long and how recently a game was played.",
+		Parameters:  appIDSchema,
+		ReadOnly:    true,
+		Run:         l.getPlaytime,
+	})
+	r.Register(Tool{
+		Name:        "set_status",
+		Description: "Set the backlog status of a game.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"required": ["appid", "status"],
+			"properties": {
+				"appid": {"type": "integer"},
+				"status": {"type": "string", "enum": ["backlog", "playing", "beaten", "dropped"]}
+			}
+		}`),
+		Run: l.setStatus,
+	})
+	return r
+}
+
+var appIDSchema = json.RawMessage(`{
+	"type": "object",
+	"required": ["appid"],
+	"properties": {"appid": {"type": "integer", "description": "Steam AppID"}}
+}`)
+
+type library struct {
+	db      *db.DB
+	steamID string
+}
+
+func (l *library) listGames(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args listArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	where, err := query.Parse(args.Where)
+	if err != nil {
+		return nil, err
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultLimit
+	}
+
+	games, err := l.db.GetGamesWithDetails(l.steamID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []GameSummary{}
+	for _, g := range games {
+		if !g.Classification.IsGame() || !where(g) {
+			continue
+		}
+		if args.Status != "" && string(g.Status) != args.Status {
+			continue
+		}
+		if args.UnplayedOnly && !logic.InBacklog(g.Game) {
+			continue
+		}
+		if args.MaxPlaytime != nil && g.PlaytimeForever > *args.MaxPlaytime {
+			continue
+		}
+		genres := g.GenreNames()
+		if args.Genre != "" && !containsFold(genres, args.Genre) {
+			continue
+		}
+		if args.Category != "" && !containsFold(g.CategoryNames(), args.Category) {
+			continue
+		}
+		results = append(results, GameSummary{
+			AppID:           g.AppID,
+			Name:            g.Name,
+			PlaytimeMinutes: g.PlaytimeForever,
+			Status:          string(g.Status),
+			Genres:          genres,
+		})
+		if len(results) == args.Limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+func (l *library) game(appID int) (model.GameDetails, error) {
+	games, err := l.db.GetGamesWithDetails(l.steamID)
+	if err != nil {
+		return model.GameDetails{}, err
+	}
+	for _, g := range games {
+		if g.AppID == appID {
+			return g, nil
+		}
+	}
+	return model.GameDetails{}, fmt.Errorf("game %d is not in the library", appID)
+}
+
+func (l *library) getDescription(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args appArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	g, err := l.game(args.AppID)
+	if err != nil {
+		return nil, err
+	}
+	desc, _ := l.db.GetAppDescription(g.AppID)
+	return GameDescription{
+		AppID:       g.AppID,
+		Name:        g.Name,
+		Description: desc,
+		Genres:      g.GenreNames(),
+		Categories:  g.CategoryNames(),
+	}, nil
+}
+
+func (l *library) getPlaytime(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args appArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	g, err := l.game(args.AppID)
+	if err != nil {
+		return nil, err
+	}
+	p := GamePlaytime{AppID: g.AppID, Name: g.Name, PlaytimeMinutes: g.PlaytimeForever, Status: string(g.Status)}
+	if g.RTimeLastPlayed > 0 {
+		p.LastPlayed = time.Unix(int64(g.RTimeLastPlayed), 0).UTC().Format(time.RFC3339)
+	}
+	return p, nil
+}
+
+func (l *library) setStatus(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args appArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	next, err := model.ParseStatus(args.Status)
+	if err != nil {
+		return nil, err
+	}
+	g, err := l.game(args.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if err := logic.SetStatus(l.db, l.steamID, g.AppID, next, false, time.Now()); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"appid": g.AppID, "name": g.Name, "status": next}, nil
+}
+
+func containsFold(values []string, want string) bool {
+	for _, v := range values {
+		if strings.Contains(strings.ToLower(v), strings.ToLower(want)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/dajoen/steam-pick/internal/db"
+	"github.com/dajoen/steam-pick/internal/model"
+	"github.com/dajoen/steam-pick/internal/tools"
+)
+
+const steamID = "76561198000000000"
+
+func newLibrary(t *testing.T) (*db.DB, *tools.Registry) {
+	t.Helper()
+	database, err := db.NewWithDSN(":memory:")
+	if err != nil {
+		t.Fatalf("NewWithDSN error: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	if err := database.UpsertGames(steamID, []model.Game{
+		{AppID: 1, Name: "Short Tactics"},
+		{AppID: 2, Name: "Long Tactics", PlaytimeForever: 600},
+		{AppID: 3, Name: "Racer"},
+	}); err != nil {
+		t.Fatalf("UpsertGames error: %v", err)
+	}
+	for id, data := range map[int]model.AppDetails{
+		1: {Type: "game", ShortDescription: "Small squads", Genres: []model.Genre{{Description: "Strategy"}}},
+		2: {Type: "game", Genres: []model.Genre{{Description: "Strategy"}}},
+		3: {Type: "game", Genres: []model.Genre{{Description: "Racing"}}},
+	} {
+		if err := database.UpsertAppDetails(id, model.AppDetailsResponse{strconv.Itoa(id): {Success: true, Data: data}}); err != nil {
+			t.Fatalf("UpsertAppDetails error: %v", err)
+		}
+	}
+	return database, tools.NewLibrary(database, steamID)
+}
+
+func TestQueryGames(t *testing.T) {
+	_, registry := newLibrary(t)
+
+	res, err := registry.Call(context.Background(), "query_games", json.RawMessage(`{"genre": "strategy", "unplayed_only": true}`))
+	if err != nil {
+		t.Fatalf("Call error: %v", err)
+	}
+	var games []tools.GameSummary
+	if err := json.Unmarshal([]byte(res), &games); err != nil {
+		t.Fatalf("invalid result %s: %v", res, err)
+	}
+	if len(games) != 1 || games[0].AppID != 1 {
+		t.Errorf("got %+v, want only Short Tactics", games)
+	}
+
+	if _, err := registry.Call(context.Background(), "get_description", json.RawMessage(`{"appid": 99}`)); err == nil {
+		t.Error("expected error for a game outside the library")
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	database, registry := newLibrary(t)
+
+	if tool, _ := registry.Get("set_status"); tool.ReadOnly {
+		t.Error("set_status must not be read-only")
+	}
+	if _, err := registry.Call(context.Background(), "set_status", json.RawMessage(`{"appid": 1, "status": "playing"}`)); err != nil {
+		t.Fatalf("Call error: %v", err)
+	}
+	status, err := database.GetGameStatus(steamID, 1)
+	if err != nil || status != model.StatusPlaying {
+		t.Errorf("got status %q, %v, want playing", status, err)
+	}
+	if _, err := registry.Call(context.Background(), "set_status", json.RawMessage(`{"appid": 1, "status": "finished"}`)); err == nil {
+		t.Error("expected error for an unknown status")
+	}
+}
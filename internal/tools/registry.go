@@ -0,0 +1,74 @@
+// Package tools exposes library operations as named tools with JSON
+// arguments, for LLM tool calling and other machine clients.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Tool is an operation callable with JSON arguments. Parameters is the JSON
+// schema of the arguments.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+	// ReadOnly tools never change stored data.
+	ReadOnly bool
+	Run      func(ctx context.Context, args json.RawMessage) (interface{}, error)
+}
+
+// Registry holds tools in registration order.
+type Registry struct {
+	tools []Tool
+	index map[string]int
+}
+
+func NewRegistry() *Registry {
+	return &Registry{index: make(map[string]int)}
+}
+
+// Register adds a tool, replacing any tool with the same name.
+func (r *Registry) Register(t Tool) {
+	if i, ok := r.index[t.Name]; ok {
+		r.tools[i] = t
+		return
+	}
+	r.index[t.Name] = len(r.tools)
+	r.tools = append(r.tools, t)
+}
+
+// Tools returns the registered tools.
+func (r *Registry) Tools() []Tool {
+	return r.tools
+}
+
+// Get returns the tool called name.
+func (r *Registry) Get(name string) (Tool, bool) {
+	i, ok := r.index[name]
+	if !ok {
+		return Tool{}, false
+	}
+	return r.tools[i], true
+}
+
+// Call runs a tool and returns its result as JSON.
+func (r *Registry) Call(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	t, ok := r.Get(name)
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage(`{}`)
+	}
+	res, err := t.Run(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,318 @@
+// Package tui runs the interactive picker on a terminal.
+package tui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+// Filters are the pick filters that can be changed while picking.
+type Filters struct {
+	Where           string
+	TurnBasedOnly   bool
+	IncludeNonGames bool
+}
+
+// Backend picks and changes games for the picker.
+type Backend interface {
+	// Pick picks a game with the filters and seed, leaving out skipped.
+	Pick(ctx context.Context, f Filters, seed int64, skipped map[int]bool) (*model.Game, error)
+	// Accept marks a game as playing and records the pick.
+	Accept(ctx context.Context, g model.Game, f Filters, seed int64) error
+	// Snooze keeps a game out of picks for a duration such as "30d" and
+	// returns when the snooze ends.
+	Snooze(ctx context.Context, g model.Game, duration string) (time.Time, error)
+	// Open opens a URL in the browser.
+	Open(url string) error
+}
+
+// DefaultSnooze is used when the snooze prompt is left empty.
+const DefaultSnooze = "30d"
+
+// Picker shows one pick at a time and reads commands until a game is
+// accepted or the user quits.
+//
+// Pick n of a session uses seed Seed+n, so the same seed and keys give the
+// same sequence and the first pick matches 'pick --seed'.
+type Picker struct {
+	Backend Backend
+	Filters Filters
+	Seed    int64
+
+	In  io.Reader
+	Out io.Writer
+	// Raw means In delivers single key presses without echo, as from a
+	// terminal in raw mode. Otherwise a command is the first character of
+	// a line and may be followed by its argument, e.g. "z 7d".
+	Raw bool
+}
+
+// Keys of the picker.
+const (
+	keyReroll    = 'r'
+	keyAccept    = 'a'
+	keySkip      = 's'
+	keySnooze    = 'z'
+	keyOpen      = 'o'
+	keyWhere     = 'w'
+	keyTurnBased = 't'
+	keyNonGames  = 'n'
+	keyQuit      = 'q'
+	keyInterrupt = 0x03
+	keyEOT       = 0x04
+)
+
+const help = "[r]eroll  [a]ccept  [s]kip  [z] snooze  [o]pen store  [w]here  [t]urn-based  [n]on-games  [q]uit"
+
+type session struct {
+	*Picker
+	in      *bufio.Reader
+	n       int64
+	seed    int64
+	game    *model.Game
+	pickErr error
+	skipped map[int]bool
+	message string
+}
+
+// Run starts the session. It returns the accepted game, or nil when the
+// user quits or the input ends.
+func (p *Picker) Run(ctx context.Context) (*model.Game, error) {
+	s := &session{Picker: p, in: bufio.NewReader(p.In), skipped: map[int]bool{}}
+	s.pick(ctx)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s.render()
+		key, arg, err := s.readCommand()
+		if err != nil {
+			return stop(err)
+		}
+		s.message = ""
+
+		switch key {
+		case keyQuit, keyInterrupt, keyEOT:
+			return nil, nil
+
+		case keyReroll:
+			s.pick(ctx)
+
+		case keyWhere:
+			where, ok, err := s.argOrPrompt(arg, fmt.Sprintf("Filter expression (empty clears, now %q): ", s.Filters.Where))
+			if err != nil {
+				return stop(err)
+			}
+			if ok {
+				s.Filters.Where = where
+				s.pick(ctx)
+			}
+
+		case keyTurnBased:
+			s.Filters.TurnBasedOnly = !s.Filters.TurnBasedOnly
+			s.pick(ctx)
+
+		case keyNonGames:
+			s.Filters.IncludeNonGames = !s.Filters.IncludeNonGames
+			s.pick(ctx)
+
+		case keyAccept, keySkip, keySnooze, keyOpen:
+			if s.game == nil {
+				s.message = "Nothing picked; change the filters or reroll."
+				continue
+			}
+			g := *s.game
+			switch key {
+			case keyAccept:
+				if err := s.Backend.Accept(ctx, g, s.Filters, s.seed); err != nil {
+					s.message = fmt.Sprintf("Error: %v", err)
+					continue
+				}
+				fmt.Fprintf(s.Out, "Now playing %s (%d).\n", g.Name, g.AppID)
+				return &g, nil
+
+			case keySkip:
+				s.skipped[g.AppID] = true
+				s.pick(ctx)
+				s.message = fmt.Sprintf("Skipped %s.", g.Name)
+
+			case keySnooze:
+				d, ok, err := s.argOrPrompt(arg, fmt.Sprintf("Snooze for (default %s): ", DefaultSnooze))
+				if err != nil {
+					return stop(err)
+				}
+				if !ok {
+					continue
+				}
+				if d == "" {
+					d = DefaultSnooze
+				}
+				until, err := s.Backend.Snooze(ctx, g, d)
+				if err != nil {
+					s.message = fmt.Sprintf("Error: %v", err)
+					continue
+				}
+				s.skipped[g.AppID] = true
+				s.pick(ctx)
+				s.message = fmt.Sprintf("Snoozed %s until %s.", g.Name, until.Format("2006-01-02 15:04"))
+
+			case keyOpen:
+				if err := s.Backend.Open(g.StoreURL); err != nil {
+					s.message = fmt.Sprintf("Error: %v", err)
+				} else {
+					s.message = "Opened " + g.StoreURL
+				}
+			}
+
+		default:
+			s.message = fmt.Sprintf("Unknown key %q.", key)
+		}
+	}
+}
+
+// stop ends the session on a read error; the end of the input quits.
+func stop(err error) (*model.Game, error) {
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	return nil, err
+}
+
+// pick draws the next game of the sequence.
+func (s *session) pick(ctx context.Context) {
+	s.seed = s.Seed + s.n
+	s.n++
+	s.game, s.pickErr = s.Backend.Pick(ctx, s.Filters, s.seed, s.skipped)
+}
+
+func (s *session) render() {
+	if s.Raw {
+		fmt.Fprint(s.Out, "\x1b[H\x1b[2J")
+	} else {
+		fmt.Fprintln(s.Out)
+	}
+	fmt.Fprintf(s.Out, "Pick %d (seed %d)\n\n", s.n, s.seed)
+	if s.game != nil {
+		fmt.Fprintf(s.Out, "  %s\n", s.game.Name)
+		details := fmt.Sprintf("AppID %d", s.game.AppID)
+		if s.game.PlaytimeForever > 0 {
+			details += fmt.Sprintf(" · %d min played", s.game.PlaytimeForever)
+		}
+		if s.game.IsTurnBased {
+			details += " · turn-based"
+		}
+		fmt.Fprintf(s.Out, "  %s\n", details)
+		fmt.Fprintf(s.Out, "  %s\n", s.game.StoreURL)
+	} else {
+		fmt.Fprintf(s.Out, "  %v\n", s.pickErr)
+	}
+
+	filters := []string{}
+	if s.Filters.Where != "" {
+		filters = append(filters, fmt.Sprintf("where %q", s.Filters.Where))
+	}
+	if s.Filters.TurnBasedOnly {
+		filters = append(filters, "turn-based only")
+	}
+	if s.Filters.IncludeNonGames {
+		filters = append(filters, "non-games included")
+	}
+	if len(filters) == 0 {
+		filters = append(filters, "none")
+	}
+	fmt.Fprintf(s.Out, "\nFilters: %s\n", strings.Join(filters, ", "))
+	if len(s.skipped) > 0 {
+		fmt.Fprintf(s.Out, "Skipped: %d\n", len(s.skipped))
+	}
+	if s.message != "" {
+		fmt.Fprintf(s.Out, "\n%s\n", s.message)
+	}
+	fmt.Fprintf(s.Out, "\n%s\n> ", help)
+}
+
+// readCommand reads the next key and, in line mode, the rest of its line.
+func (s *session) readCommand() (rune, string, error) {
+	if !s.Raw {
+		for {
+			line, err := s.readLine()
+			if err != nil {
+				return 0, "", err
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			key, size := utf8.DecodeRuneInString(line)
+			return key, strings.TrimSpace(line[size:]), nil
+		}
+	}
+	for {
+		key, _, err := s.in.ReadRune()
+		if err != nil {
+			return 0, "", err
+		}
+		if key != '\r' && key != '\n' {
+			return key, "", nil
+		}
+	}
+}
+
+// argOrPrompt returns arg when given and otherwise asks for it. ok is false
+// when a raw prompt is cancelled with Escape or Ctrl-C.
+func (s *session) argOrPrompt(arg, prompt string) (string, bool, error) {
+	if arg != "" {
+		return arg, true, nil
+	}
+	if !s.Raw {
+		fmt.Fprint(s.Out, prompt)
+		line, err := s.readLine()
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimSpace(line), true, nil
+	}
+
+	fmt.Fprint(s.Out, "\n"+prompt)
+	var buf []rune
+	for {
+		r, _, err := s.in.ReadRune()
+		if err != nil {
+			return "", false, err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprintln(s.Out)
+			return strings.TrimSpace(string(buf)), true, nil
+		case 0x1b, keyInterrupt:
+			return "", false, nil
+		case 0x7f, '\b':
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+				fmt.Fprint(s.Out, "\b \b")
+			}
+		default:
+			if r >= ' ' {
+				buf = append(buf, r)
+				fmt.Fprint(s.Out, string(r))
+			}
+		}
+	}
+}
+
+// readLine reads a line without its newline. A last line without a newline
+// is returned before io.EOF.
+func (s *session) readLine() (string, error) {
+	line, err := s.in.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dajoen/steam-pick/internal/model"
+)
+
+type fakeBackend struct {
+	games    []model.Game
+	seeds    []int64
+	filters  []Filters
+	accepted []int64
+	snoozed  map[int]string
+	opened   []string
+}
+
+func (f *fakeBackend) Pick(ctx context.Context, filters Filters, seed int64, skipped map[int]bool) (*model.Game, error) {
+	f.seeds = append(f.seeds, seed)
+	f.filters = append(f.filters, filters)
+	if filters.Where == "bad" {
+		return nil, errors.New("invalid filter")
+	}
+	var left []model.Game
+	for _, g := range f.games {
+		if !skipped[g.AppID] {
+			left = append(left, g)
+		}
+	}
+	if len(left) == 0 {
+		return nil, errors.New("no games left")
+	}
+	g := left[int(seed)%len(left)]
+	return &g, nil
+}
+
+func (f *fakeBackend) Accept(ctx context.Context, g model.Game, filters Filters, seed int64) error {
+	f.accepted = append(f.accepted, int64(g.AppID), seed)
+	return nil
+}
+
+func (f *fakeBackend) Snooze(ctx context.Context, g model.Game, duration string) (time.Time, error) {
+	if f.snoozed == nil {
+		f.snoozed = map[int]string{}
+	}
+	f.snoozed[g.AppID] = duration
+	return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+func (f *fakeBackend) Open(url string) error {
+	f.opened = append(f.opened, url)
+	return nil
+}
+
+func newFakeBackend() *fakeBackend {
+	var games []model.Game
+	for i := 1; i <= 4; i++ {
+		games = append(games, model.Game{AppID: i, Name: "Game " + string(rune('A'+i-1)), StoreURL: "https://example.com/" + string(rune('0'+i))})
+	}
+	return &fakeBackend{games: games}
+}
+
+func TestPickerLineMode(t *testing.T) {
+	backend := newFakeBackend()
+	var out bytes.Buffer
+	p := &Picker{
+		Backend: backend,
+		Seed:    10,
+		In:      strings.NewReader("r\n\ns\nw bad\nw\ngenre:RPG\nt\nn\nz\n\no\nx\na\n"),
+		Out:     &out,
+	}
+
+	game, err := p.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	// Every draw takes the next seed: start, reroll, skip, two filter
+	// changes, two toggles and the snooze.
+	wantSeeds := []int64{10, 11, 12, 13, 14, 15, 16, 17}
+	if len(backend.seeds) != len(wantSeeds) {
+		t.Fatalf("seeds: got %v, want %v", backend.seeds, wantSeeds)
+	}
+	for i, s := range wantSeeds {
+		if backend.seeds[i] != s {
+			t.Fatalf("seeds: got %v, want %v", backend.seeds, wantSeeds)
+		}
+	}
+
+	last := backend.filters[len(backend.filters)-1]
+	if last != (Filters{Where: "genre:RPG", TurnBasedOnly: true, IncludeNonGames: true}) {
+		t.Errorf("filters after changes: got %+v", last)
+	}
+	if !strings.Contains(out.String(), "invalid filter") {
+		t.Errorf("expected the filter error to be shown, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), `Unknown key 'x'.`) {
+		t.Errorf("expected unknown key message, got:\n%s", out.String())
+	}
+
+	// An empty snooze prompt uses the default.
+	if len(backend.snoozed) != 1 {
+		t.Fatalf("snoozed: got %v", backend.snoozed)
+	}
+	for _, d := range backend.snoozed {
+		if d != DefaultSnooze {
+			t.Errorf("snooze duration: got %q, want %q", d, DefaultSnooze)
+		}
+	}
+	if len(backend.opened) != 1 {
+		t.Errorf("opened: got %v", backend.opened)
+	}
+	if game == nil || len(backend.accepted) != 2 || backend.accepted[0] != int64(game.AppID) || backend.accepted[1] != 17 {
+		t.Errorf("accepted: got %v for %+v", backend.accepted, game)
+	}
+}
+
+func TestPickerRawMode(t *testing.T) {
+	backend := newFakeBackend()
+	var out bytes.Buffer
+	p := &Picker{
+		Backend: backend,
+		Seed:    1,
+		// A where prompt with a backspace, a cancelled snooze, then quit.
+		In:  strings.NewReader("rwgenre:RPX\x7fG\rz\x1bq"),
+		Out: &out,
+		Raw: true,
+	}
+
+	game, err := p.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if game != nil {
+		t.Errorf("quit should not return a game, got %+v", game)
+	}
+	if len(backend.seeds) != 3 || backend.seeds[2] != 3 {
+		t.Errorf("seeds: got %v", backend.seeds)
+	}
+	if got := backend.filters[2].Where; got != "genre:RPG" {
+		t.Errorf("where: got %q", got)
+	}
+	if len(backend.snoozed) != 0 {
+		t.Errorf("cancelled snooze should not snooze, got %v", backend.snoozed)
+	}
+}
+
+func TestPickerSameSeedSameSequence(t *testing.T) {
+	run := func() string {
+		var out bytes.Buffer
+		p := &Picker{Backend: newFakeBackend(), Seed: 7, In: strings.NewReader("r\nr\ns\nr\n"), Out: &out}
+		if _, err := p.Run(context.Background()); err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+		return out.String()
+	}
+	if a, b := run(), run(); a != b {
+		t.Errorf("same seed gave different sessions:\n%s\n---\n%s", a, b)
+	}
+}
+
+func TestPickerEndOfInputInPrompt(t *testing.T) {
+	p := &Picker{Backend: newFakeBackend(), Seed: 1, In: strings.NewReader("w"), Out: &bytes.Buffer{}}
+	if game, err := p.Run(context.Background()); game != nil || err != nil {
+		t.Errorf("got %+v, %v; want nil, nil", game, err)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package tui
+
+import "errors"
+
+// MakeRaw is not supported on this platform; the picker reads lines.
+func MakeRaw(fd int) (restore func() error, err error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -0,0 +1,270 @@
+// Package vdf parses Valve's text KeyValues format, used by Steam for
+// libraryfolders.vdf, appmanifest_*.acf and localconfig.vdf.
+package vdf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Node is a key with either a string value or child nodes.
+type Node struct {
+	Key      string
+	Value    string
+	Children []*Node
+}
+
+// IsObject reports whether the node holds children rather than a value.
+func (n *Node) IsObject() bool { return n.Children != nil }
+
+// Child returns the first child with key, compared case-insensitively as
+// Steam does, or nil.
+func (n *Node) Child(key string) *Node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if strings.EqualFold(c.Key, key) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Find follows a path of keys and returns the node at its end, or nil.
+func (n *Node) Find(path ...string) *Node {
+	for _, key := range path {
+		n = n.Child(key)
+	}
+	return n
+}
+
+// String returns the value at path, or "" when it is missing or an object.
+func (n *Node) String(path ...string) string {
+	c := n.Find(path...)
+	if c == nil || c.IsObject() {
+		return ""
+	}
+	return c.Value
+}
+
+// Parse reads a KeyValues document. The result is a root node without a
+// key whose children are the top-level entries.
+func Parse(r io.Reader) (*Node, error) {
+	p := &parser{r: bufio.NewReader(r), line: 1}
+	root := &Node{Children: []*Node{}}
+	if err := p.parseChildren(root, false); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// ParseFile parses the file at path.
+func ParseFile(path string) (*Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	root, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return root, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+	tokenCondition
+)
+
+type parser struct {
+	r    *bufio.Reader
+	line int
+}
+
+// parseChildren reads key/value pairs into n until a closing brace, or the
+// end of input when nested is false.
+func (p *parser) parseChildren(n *Node, nested bool) error {
+	for {
+		kind, key, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case tokenEOF:
+			if nested {
+				return p.errorf("unexpected end of input, missing '}'")
+			}
+			return nil
+		case tokenClose:
+			if !nested {
+				return p.errorf("unexpected '}'")
+			}
+			return nil
+		case tokenOpen, tokenCondition:
+			return p.errorf("expected a key")
+		}
+
+		kind, value, err := p.next()
+		if err != nil {
+			return err
+		}
+		child := &Node{Key: key}
+		switch kind {
+		case tokenString:
+			child.Value = value
+		case tokenOpen:
+			child.Children = []*Node{}
+			if err := p.parseChildren(child, true); err != nil {
+				return err
+			}
+		default:
+			return p.errorf("expected a value or '{' after %q", key)
+		}
+		n.Children = append(n.Children, child)
+	}
+}
+
+// next returns the next token. Conditions such as [$WIN32] after a value
+// are skipped.
+func (p *parser) next() (tokenKind, string, error) {
+	for {
+		kind, s, err := p.token()
+		if err != nil || kind != tokenCondition {
+			return kind, s, err
+		}
+	}
+}
+
+func (p *parser) token() (tokenKind, string, error) {
+	for {
+		c, err := p.read()
+		if errors.Is(err, io.EOF) {
+			return tokenEOF, "", nil
+		}
+		if err != nil {
+			return 0, "", err
+		}
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case c == '/':
+			next, err := p.r.ReadByte()
+			if err != nil || next != '/' {
+				return 0, "", p.errorf("unexpected '/'")
+			}
+			if err := p.skipLine(); err != nil {
+				return 0, "", err
+			}
+		case c == '{':
+			return tokenOpen, "", nil
+		case c == '}':
+			return tokenClose, "", nil
+		case c == '[':
+			for {
+				c, err := p.read()
+				if err != nil {
+					return 0, "", p.errorf("unterminated condition")
+				}
+				if c == ']' {
+					return tokenCondition, "", nil
+				}
+			}
+		case c == '"':
+			s, err := p.quoted()
+			return tokenString, s, err
+		default:
+			s, err := p.unquoted(c)
+			return tokenString, s, err
+		}
+	}
+}
+
+// quoted reads a quoted string after its opening quote. Steam escapes
+// backslashes and quotes, as in "C:\\Program Files (x86)\\Steam".
+func (p *parser) quoted() (string, error) {
+	var b strings.Builder
+	for {
+		c, err := p.read()
+		if err != nil {
+			return "", p.errorf("unterminated string")
+		}
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			e, err := p.read()
+			if err != nil {
+				return "", p.errorf("unterminated string")
+			}
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// unquoted reads a bare token that started with first.
+func (p *parser) unquoted(first byte) (string, error) {
+	var b strings.Builder
+	b.WriteByte(first)
+	for {
+		c, err := p.r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n', '"', '{', '}', '[':
+			_ = p.r.UnreadByte()
+			return b.String(), nil
+		}
+		b.WriteByte(c)
+	}
+}
+
+func (p *parser) skipLine() error {
+	for {
+		c, err := p.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if c == '\n' {
+			return nil
+		}
+	}
+}
+
+func (p *parser) read() (byte, error) {
+	c, err := p.r.ReadByte()
+	if c == '\n' {
+		p.line++
+	}
+	return c, err
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
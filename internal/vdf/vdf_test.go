@@ -0,0 +1,65 @@
+package vdf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(strings.NewReader(`// Written by Steam
+"libraryfolders"
+{
+	"0"
+	{
+		"path"		"C:\\Program Files (x86)\\Steam"
+		"label"		""
+		"apps"
+		{
+			"620"		"12345"
+		}
+	}
+	"1"	"D:\\Games"
+	Unquoted	value [$WIN32]
+	"quote"	"say \"hi\""
+}
+`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	if got := doc.String("LibraryFolders", "0", "path"); got != `C:\Program Files (x86)\Steam` {
+		t.Errorf("path: got %q", got)
+	}
+	if got := doc.String("libraryfolders", "0", "apps", "620"); got != "12345" {
+		t.Errorf("apps/620: got %q", got)
+	}
+	if got := doc.String("libraryfolders", "1"); got != `D:\Games` {
+		t.Errorf("old-style path: got %q", got)
+	}
+	if got := doc.String("libraryfolders", "unquoted"); got != "value" {
+		t.Errorf("unquoted: got %q", got)
+	}
+	if got := doc.String("libraryfolders", "quote"); got != `say "hi"` {
+		t.Errorf("escaped quote: got %q", got)
+	}
+	if n := doc.Find("libraryfolders", "0", "label"); n == nil || n.IsObject() || n.Value != "" {
+		t.Errorf("empty value: got %+v", n)
+	}
+	if doc.Find("libraryfolders", "missing", "path") != nil || doc.String("libraryfolders", "0") != "" {
+		t.Error("missing keys and objects should give nil and \"\"")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		`"a" {`,
+		`"a" "b" }`,
+		`"a"`,
+		`"a" "unterminated`,
+		`{ "a" "b" }`,
+	} {
+		if _, err := Parse(strings.NewReader(in)); err == nil {
+			t.Errorf("Parse(%q): expected an error", in)
+		}
+	}
+}